@@ -15,93 +15,215 @@ import (
 	"golang.org/x/exp/slices"
 )
 
+// DefaultChurnWindowDays changes to lines younger than this are counted as churn. Older lines are counted as refactor
+const DefaultChurnWindowDays = 21
+
+const (
+	// MergesSkip merge commits are not analysed. The commits of merged branches are analysed
+	MergesSkip = "skip"
+	// MergesFirstParent only commits in the first parent history of the branch are analysed.
+	// Merge commits are compared to their first parent, as if the merged branch was squashed
+	MergesFirstParent = "first-parent"
+	// MergesInclude all commits are analysed. Merge commits are compared to their
+	// first parent only in the files changed while resolving the merge
+	MergesInclude = "include"
+)
+
+const (
+	// CoAuthorsSplit lines touched in commits with "Co-authored-by" trailers are split
+	// between the author and the co-authors of the commit
+	CoAuthorsSplit = "split"
+	// CoAuthorsFull all lines touched in commits with co-authors are credited to each of them
+	CoAuthorsFull = "full"
+	// CoAuthorsIgnore "Co-authored-by" trailers are ignored. Lines are credited to the commit author only
+	CoAuthorsIgnore = "ignore"
+)
+
 type ChangesOptions struct {
 	utils.BaseOptions
 	// AuthorsRegex string
-	SinceDate   string
-	UntilDate   string
-	SinceCommit string
-	UntilCommit string
+	SinceDate   string `json:"since_date"`
+	UntilDate   string `json:"until_date"`
+	SinceCommit string `json:"since_commit"`
+	UntilCommit string `json:"until_commit"`
+	// ChurnWindowDays changes to lines younger than this are counted as churn. DefaultChurnWindowDays if zero
+	ChurnWindowDays int `json:"churn_window_days"`
+	// AgeBuckets comma separated upper limits, in days, of the buckets used to count changed lines by age. Eg.: "1,7,21,90"
+	AgeBuckets string `json:"age_buckets"`
+	// Merges how merge commits are handled. MergesSkip, MergesFirstParent or MergesInclude. MergesSkip if empty
+	Merges string `json:"merges"`
+	// CoAuthors how lines of commits with co-authors are credited. CoAuthorsSplit, CoAuthorsFull or CoAuthorsIgnore. CoAuthorsSplit if empty
+	CoAuthors string `json:"co_authors"`
 }
 
 type ChangesTimeseriesOptions struct {
 	utils.BaseOptions
-	Since  string `json:"since"`
-	Until  string `json:"until"`
-	Period string `json:"period"`
+	Since           string `json:"since"`
+	Until           string `json:"until"`
+	Period          string `json:"period"`
+	ChurnWindowDays int    `json:"churn_window_days"`
+	AgeBuckets      string `json:"age_buckets"`
+	Merges          string `json:"merges"`
+	CoAuthors       string `json:"co_authors"`
 }
 
 type LinesTouched struct {
 	/* New lines found in commits */
-	New int
+	New int `json:"new"`
 
-	/* Lines changed in commits. If the same line is changed in two commits, for example, it will count as two changes. This is the sum of RefactorOwn, RefactorOther, ChurnOwn and ChurnOther */
-	Changes int
+	/* Lines changed in commits. If the same line is changed in two commits, for example, it will count as two changes. This is the sum of RefactorOwn, RefactorOther, ChurnOwn and ChurnOther. Deleted lines are not counted here */
+	Changes int `json:"changes"`
 
 	/* Lines changed after a while in which the author of the previous version was the same person */
-	RefactorOwn int
+	RefactorOwn int `json:"refactor_own"`
 	/* Lines changed after a while in which the author of the previous version was another person */
-	RefactorOther int
+	RefactorOther int `json:"refactor_other"`
 	/* Lines you owned that were changed by another person after a while. When adding RefactorOther to someone, the author of the previous version of the line will have this counter incremented */
-	RefactorReceived int
+	RefactorReceived int `json:"refactor_received"`
 
 	/* Lines changed in a short term in which the author of the previous version was the same person */
-	ChurnOwn int
+	ChurnOwn int `json:"churn_own"`
 	/* Lines changed in a short term in which the author of the previous version was another person */
-	ChurnOther int
+	ChurnOther int `json:"churn_other"`
 	/* Lines you owned that were changed by another person in a short term. When adding ChurnOther to someone, the author of the previous version of the line will have this counter incremented */
-	ChurnReceived int
+	ChurnReceived int `json:"churn_received"`
+
+	/* Lines deleted in commits, including the lines of files that were deleted. Deleted lines are counted only here, not in Changes */
+	Deleted int `json:"deleted"`
+	/* Lines you owned that were deleted by another person. When adding Deleted to someone, the author of the deleted line will have this counter incremented */
+	DeletedReceived int `json:"deleted_received"`
+
+	/* Lines that were kept as is when the file was renamed or copied to another path. They are not counted as New or Changes */
+	Moved int `json:"moved"`
+
+	/* New or changed lines credited to the author in commits with "Co-authored-by" trailers. In the totals, lines touched in commits with co-authors */
+	CoAuthored int `json:"co_authored"`
+
+	/* Number of changed lines per age in the moment they were changed. Same order as ChangesResult.AgeBucketNames. Only present if age buckets were defined */
+	AgeBuckets []int `json:"age_buckets,omitempty"`
 
 	/* Sum of age of lines in the moment they are changed. AgeDaysSum/Changes gives you the average survival duration of a line before it's changed by someone */
-	AgeDaysSum float64
+	AgeDaysSum float64 `json:"age_days_sum"`
 }
 
 type FileTouched struct {
-	Name  string
-	Lines int
+	Name  string `json:"name"`
+	Lines int    `json:"lines"`
 }
 
 type AuthorLines struct {
-	AuthorName      string
-	AuthorMail      string
-	LinesTouched    LinesTouched
-	FilesTouched    []FileTouched
+	AuthorName      string                 `json:"author_name"`
+	AuthorMail      string                 `json:"author_mail"`
+	LinesTouched    LinesTouched           `json:"lines_touched"`
+	FilesTouched    []FileTouched          `json:"files_touched"`
+	Commits         int                    `json:"commits"`
 	filesTouchedMap map[string]FileTouched // temporary map used during processing
 }
 
+// FileLines change stats of a single file
+type FileLines struct {
+	FilePath string `json:"file_path"`
+	/* Number of analysed commits that changed the file */
+	Commits int `json:"commits"`
+	/* Number of distinct authors with new or changed lines in the file */
+	Authors      int          `json:"authors"`
+	LinesTouched LinesTouched `json:"lines_touched"`
+}
+
+type CommitStats struct {
+	CommitId   string    `json:"commit_id"`
+	AuthorName string    `json:"author_name"`
+	AuthorMail string    `json:"author_mail"`
+	Date       time.Time `json:"date"`
+	/* Files of the commit that were analysed */
+	TotalFiles int `json:"total_files"`
+	/* Paths of the files of the commit that were analysed, sorted */
+	Files []string `json:"files"`
+	/* Lines touched by the commit in the analysed files */
+	LinesTouched LinesTouched `json:"lines_touched"`
+}
+
+// AuthorCollaboration lines of an author (the owner) that were changed or deleted by another author
+type AuthorCollaboration struct {
+	AuthorName string `json:"author_name"`
+	AuthorMail string `json:"author_mail"`
+	OwnerName  string `json:"owner_name"`
+	OwnerMail  string `json:"owner_mail"`
+	/* Lines of the owner changed (not deleted) by the author after the churn window */
+	Refactor int `json:"refactor"`
+	/* Lines of the owner changed (not deleted) by the author during the churn window */
+	Churn int `json:"churn"`
+	/* Lines of the owner deleted by the author */
+	Deleted int `json:"deleted"`
+}
+
+type TeamLines struct {
+	TeamName     string       `json:"team_name"`
+	AuthorNames  []string     `json:"author_names"`
+	LinesTouched LinesTouched `json:"lines_touched"`
+}
+
 type ChangesFileResult struct {
 	CommitId string
 	FilePath string
 	ChangesResult
+	coAuthorTurn int // next author of the commit credited when lines are split between co-authors
 }
 
 type ChangesResult struct {
 	/* Lines change stats */
-	TotalLinesTouched LinesTouched
+	TotalLinesTouched LinesTouched `json:"total_lines_touched"`
 	/* Total files changed in the different commits. If the same file is changed in two commits, for example, it will count as one. */
-	TotalFiles int
+	TotalFiles int `json:"total_files"`
 	/* Number of commits analysed */
-	TotalCommits   int
+	TotalCommits   int                    `json:"total_commits"`
 	authorLinesMap map[string]AuthorLines // temporary map used during processing
+	/* Lines changed by an author that were owned by another author, largest first. It is a sparse directed author-to-author matrix */
+	Collaborations    []AuthorCollaboration          `json:"collaborations"`
+	collaborationsMap map[string]AuthorCollaboration // temporary map used during processing
 	/* Change stats per author */
-	AuthorsLines  []AuthorLines
-	SinceCommit   utils.CommitInfo
-	UntilCommit   utils.CommitInfo
-	analysisTime  time.Duration
-	skippedFiles  int
-	authorSkipped bool
+	AuthorsLines []AuthorLines `json:"authors_lines"`
+	/* Change stats per team. Empty if teams were not defined */
+	TeamsLines []TeamLines `json:"teams_lines"`
+	/* Stats of each analysed commit, newest first */
+	Commits []CommitStats `json:"commits"`
+	/* Change stats of each changed file, sorted by file path */
+	FilesLines []FileLines `json:"files_lines"`
+	/* Names of the age buckets of changed lines. Eg.: "<1d", "<7d", ">=7d". Only present if age buckets were defined */
+	AgeBucketNames []string         `json:"age_bucket_names,omitempty"`
+	SinceCommit    utils.CommitInfo `json:"since_commit"`
+	UntilCommit    utils.CommitInfo `json:"until_commit"`
+	analysisTime   time.Duration
+	skippedFiles   int
+	authorSkipped  bool
 }
 
 type fileWorkerRequest struct {
-	repoDir         string
-	commitId        string
-	filePath        string
+	repoDir  string
+	git      utils.GitBackend
+	commitId string
+	filePath string
+	// prevFilePath path of the file before it was renamed or copied by the commit. Empty if it wasn't
+	prevFilePath    string
 	authorsRegex    string
 	authorsNotRegex string
+	// churnWindow changes to lines younger than this are churn. Defaults to DefaultChurnWindowDays
+	churnWindow time.Duration
+	// ageBuckets upper limits, in days, of the age buckets of changed lines
+	ageBuckets []int
+	identities *utils.IdentityResolver
+	// fileCache stores git information per file. nil if cache is disabled
+	fileCache *utils.CacheDB
+	// parentCommitId commit the file is compared to when analysing merge commits. Empty for other commits
+	parentCommitId string
+	// coAuthors how lines of commits with co-authors are credited
+	coAuthors string
 }
 type commitWorkerRequest struct {
 	repoDir  string
 	commitId string
+	// merge the commit is a merge commit that must be analysed
+	merge bool
 }
 
 func AnalyseTimeseriesChanges(opts ChangesTimeseriesOptions, progressChan chan<- utils.ProgressInfo) ([]ChangesResult, error) {
@@ -112,11 +234,25 @@ func AnalyseTimeseriesChanges(opts ChangesTimeseriesOptions, progressChan chan<-
 		return nil, fmt.Errorf("opts.Until is required")
 	}
 
+	gitOpts, err := utils.NewGitOptions(opts.BaseOptions)
+	if err != nil {
+		return nil, err
+	}
+	git, err := utils.NewGitBackend(opts.GitBackend, opts.RepoDir, gitOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer git.Close()
+
 	result := make([]ChangesResult, 0)
 	until := opts.Until
 	since := fmt.Sprintf("%s - %s", until, opts.Period)
 	analysisOpts := ChangesOptions{
-		BaseOptions: opts.BaseOptions,
+		BaseOptions:     opts.BaseOptions,
+		ChurnWindowDays: opts.ChurnWindowDays,
+		AgeBuckets:      opts.AgeBuckets,
+		Merges:          opts.Merges,
+		CoAuthors:       opts.CoAuthors,
 	}
 
 	processedCommits := make([]string, 0)
@@ -124,7 +260,7 @@ func AnalyseTimeseriesChanges(opts ChangesTimeseriesOptions, progressChan chan<-
 	for {
 		// FIND "SINCE" COMMIT
 		// see if the outer "since" is outside inner "since"
-		sinceCommits, err := utils.ExecGetCommitsInDateRange(opts.RepoDir, opts.Branch, opts.Since, since)
+		sinceCommits, err := git.CommitsInDateRange(opts.Branch, opts.Since, since)
 		if err != nil {
 			return nil, err
 		}
@@ -149,7 +285,7 @@ func AnalyseTimeseriesChanges(opts ChangesTimeseriesOptions, progressChan chan<-
 		// find next commit that wasn't processed yet
 		// this is necessary because git does a "loose" lookup for commits when using relative time periods
 		// and we don't want to repeat the same commit in multiple periods (to avoid double couting)
-		untilCommits, err := utils.ExecGetCommitsInDateRange(opts.RepoDir, opts.Branch, sinceCommit.Date.Format(time.RFC3339), until)
+		untilCommits, err := git.CommitsInDateRange(opts.Branch, sinceCommit.Date.Format(time.RFC3339), until)
 		if err != nil {
 			return nil, err
 		}
@@ -188,7 +324,7 @@ func AnalyseTimeseriesChanges(opts ChangesTimeseriesOptions, progressChan chan<-
 
 		// add all commits in range as processed
 		processedCommits = appendProcessed(processedCommits, sinceCommit.CommitId)
-		rangeCommits, err := utils.ExecGetCommitsInCommitRange(opts.RepoDir, opts.Branch, sinceCommit.CommitId, untilCommit.CommitId)
+		rangeCommits, err := git.CommitsInCommitRange(opts.Branch, sinceCommit.CommitId, untilCommit.CommitId)
 		for _, rangeid := range rangeCommits {
 			processedCommits = appendProcessed(processedCommits, rangeid.CommitId)
 		}
@@ -230,7 +366,9 @@ func AnalyseChanges(opts ChangesOptions, progressChan chan<- utils.ProgressInfo)
 	result := ChangesResult{
 		TotalLinesTouched: LinesTouched{},
 		authorLinesMap:    make(map[string]AuthorLines, 0),
-		AuthorsLines:      make([]AuthorLines, 0)}
+		collaborationsMap: make(map[string]AuthorCollaboration, 0),
+		AuthorsLines:      make([]AuthorLines, 0),
+		TeamsLines:        make([]TeamLines, 0)}
 
 	progressInfo := utils.ProgressInfo{}
 
@@ -250,7 +388,57 @@ func AnalyseChanges(opts ChangesOptions, progressChan chan<- utils.ProgressInfo)
 		return result, errors.New("files-not filter regex is invalid. err=" + err.Error())
 	}
 
+	if opts.ChurnWindowDays < 0 {
+		return result, fmt.Errorf("churn window must not be negative")
+	}
+	if opts.Merges != "" && opts.Merges != MergesSkip && opts.Merges != MergesFirstParent && opts.Merges != MergesInclude {
+		return result, fmt.Errorf("Invalid merges mode %s. Use '%s', '%s' or '%s'", opts.Merges, MergesSkip, MergesFirstParent, MergesInclude)
+	}
+	if opts.CoAuthors != "" && opts.CoAuthors != CoAuthorsSplit && opts.CoAuthors != CoAuthorsFull && opts.CoAuthors != CoAuthorsIgnore {
+		return result, fmt.Errorf("Invalid co-authors mode %s. Use '%s', '%s' or '%s'", opts.CoAuthors, CoAuthorsSplit, CoAuthorsFull, CoAuthorsIgnore)
+	}
+	ageBuckets, err := ParseAgeBuckets(opts.AgeBuckets)
+	if err != nil {
+		return result, err
+	}
+	result.AgeBucketNames = AgeBucketNames(ageBuckets)
+
+	identities, err := utils.NewIdentityResolver(opts.RepoDir, opts.IdentitiesFile)
+	if err != nil {
+		return result, err
+	}
+	identities.AddIdentities(opts.Identities)
+
+	gitOpts, err := utils.NewGitOptions(opts.BaseOptions)
+	if err != nil {
+		return result, err
+	}
+
+	git, err := utils.NewGitBackend(opts.GitBackend, opts.RepoDir, gitOpts)
+	if err != nil {
+		return result, err
+	}
+	defer git.Close()
+
+	// git information per file can be reused by analyses with different options
+	var fileCache *utils.CacheDB
+	if opts.CacheFile != "" {
+		fileCache, err = utils.NewCacheDB(opts.CacheFile, cacheTable, opts.CacheTTLSeconds)
+		if err != nil {
+			return result, fmt.Errorf("Cannot use file to cache results. err=%s", err)
+		}
+		defer fileCache.Close()
+	}
+
+	teams, err := utils.NewTeamResolver(opts.TeamsFile)
+	if err != nil {
+		return result, err
+	}
+
 	nrWorkers := runtime.NumCPU() - 1
+	if nrWorkers < 1 {
+		nrWorkers = 1
+	}
 	// nrWorkers := 1
 
 	// MAP REDUCE - analyse files in parallel goroutines
@@ -263,32 +451,58 @@ func AnalyseChanges(opts ChangesOptions, progressChan chan<- utils.ProgressInfo)
 
 	commitWorkersInputChan := make(chan commitWorkerRequest, 5000)
 
+	// presets need the commit range, so the filter is created before commits are sent to workers
+	var excludeFilter *utils.ExcludeFilter
+
+	// author and date of the commits, collected by commit workers
+	commitInfos := make(map[string]utils.CommitInfo, 0)
+	var commitInfosMutex sync.Mutex
+
 	// REDUCE - summarise counters (STEP 4/4)
 	var summaryWorkerWaitGroup sync.WaitGroup
 	summaryWorkerWaitGroup.Add(1)
 	go func() {
 		defer summaryWorkerWaitGroup.Done()
 
-		commitsWithFiles := make(map[string]bool, 0)
+		commitsStats := make(map[string]CommitStats, 0)
+		filesLines := make(map[string]FileLines, 0)
+		fileAuthors := make(map[string]map[string]bool, 0)
 
 		logrus.Debugf("Counting total lines changed per author")
 		for fileResult := range fileWorkersOutputChan {
 
 			if !fileResult.authorSkipped {
-				commitsWithFiles[fileResult.CommitId] = true
+				commitStats := commitsStats[fileResult.CommitId]
+				commitStats.TotalFiles++
+				commitStats.Files = append(commitStats.Files, fileResult.FilePath)
+				commitStats.LinesTouched = SumLinesTouched(commitStats.LinesTouched, fileResult.TotalLinesTouched)
+				commitsStats[fileResult.CommitId] = commitStats
 				_, ok := fileCounterMap[fileResult.FilePath]
 				if !ok {
 					fileCounterMap[fileResult.FilePath] = true
 					result.TotalFiles++
 				}
 				result.TotalLinesTouched = SumLinesTouched(result.TotalLinesTouched, fileResult.TotalLinesTouched)
+				fileLines := filesLines[fileResult.FilePath]
+				fileLines.Commits++
+				fileLines.LinesTouched = SumLinesTouched(fileLines.LinesTouched, fileResult.TotalLinesTouched)
+				filesLines[fileResult.FilePath] = fileLines
+				if fileAuthors[fileResult.FilePath] == nil {
+					fileAuthors[fileResult.FilePath] = make(map[string]bool, 0)
+				}
 				for author := range fileResult.authorLinesMap {
 					fileAuthorLines := fileResult.authorLinesMap[author]
+					if fileAuthorLines.LinesTouched.New+fileAuthorLines.LinesTouched.Changes > 0 {
+						fileAuthors[fileResult.FilePath][author] = true
+					}
 					authorLines := result.authorLinesMap[author]
 					authorLines.LinesTouched = SumLinesTouched(authorLines.LinesTouched, fileAuthorLines.LinesTouched)
 					authorLines.filesTouchedMap = sumFilesTouched(authorLines.filesTouchedMap, fileAuthorLines.filesTouchedMap)
 					result.authorLinesMap[author] = authorLines
 				}
+				for key, fileCollaboration := range fileResult.collaborationsMap {
+					result.collaborationsMap[key] = sumCollaborations(result.collaborationsMap[key], fileCollaboration)
+				}
 			}
 
 			progressInfo.CompletedTotalTime += fileResult.analysisTime
@@ -300,7 +514,59 @@ func AnalyseChanges(opts ChangesOptions, progressChan chan<- utils.ProgressInfo)
 			}
 		}
 
-		result.TotalCommits = len(commitsWithFiles)
+		result.TotalCommits = len(commitsStats)
+
+		logrus.Debugf("Preparing stats for each commit")
+		authorCommits := make(map[string]int, 0)
+		commitInfosMutex.Lock()
+		result.Commits = make([]CommitStats, 0)
+		for commitId, commitStats := range commitsStats {
+			commitInfo := commitInfos[commitId]
+			commitStats.CommitId = commitId
+			commitStats.AuthorName = commitInfo.AuthorName
+			commitStats.AuthorMail = commitInfo.AuthorMail
+			commitStats.Date = commitInfo.Date
+			sort.Strings(commitStats.Files)
+			result.Commits = append(result.Commits, commitStats)
+			authorCommits[fmt.Sprintf("%s###%s", commitInfo.AuthorName, commitInfo.AuthorMail)]++
+		}
+		commitInfosMutex.Unlock()
+		sort.Slice(result.Commits, func(i, j int) bool {
+			if result.Commits[i].Date.Equal(result.Commits[j].Date) {
+				return result.Commits[i].CommitId < result.Commits[j].CommitId
+			}
+			return result.Commits[i].Date.After(result.Commits[j].Date)
+		})
+
+		logrus.Debugf("Preparing stats for each file")
+		result.FilesLines = make([]FileLines, 0)
+		for filePath, fileLines := range filesLines {
+			fileLines.FilePath = filePath
+			fileLines.Authors = len(fileAuthors[filePath])
+			result.FilesLines = append(result.FilesLines, fileLines)
+		}
+		sort.Slice(result.FilesLines, func(i, j int) bool {
+			return result.FilesLines[i].FilePath < result.FilesLines[j].FilePath
+		})
+
+		logrus.Debugf("Preparing collaborations between authors")
+		result.Collaborations = make([]AuthorCollaboration, 0)
+		for _, collaboration := range result.collaborationsMap {
+			result.Collaborations = append(result.Collaborations, collaboration)
+		}
+		sort.Slice(result.Collaborations, func(i, j int) bool {
+			ci := result.Collaborations[i]
+			cj := result.Collaborations[j]
+			ti := ci.Refactor + ci.Churn + ci.Deleted
+			tj := cj.Refactor + cj.Churn + cj.Deleted
+			if ti != tj {
+				return ti > tj
+			}
+			if ci.AuthorName != cj.AuthorName {
+				return ci.AuthorName < cj.AuthorName
+			}
+			return ci.OwnerName < cj.OwnerName
+		})
 
 		logrus.Debugf("Preparing summary for each author")
 		authorsLines := make([]AuthorLines, 0)
@@ -318,6 +584,7 @@ func AnalyseChanges(opts ChangesOptions, progressChan chan<- utils.ProgressInfo)
 				AuthorMail:   authorParts[1],
 				LinesTouched: authorLines.LinesTouched,
 				FilesTouched: filesTouched,
+				Commits:      authorCommits[authorKeys],
 			})
 		}
 
@@ -327,6 +594,7 @@ func AnalyseChanges(opts ChangesOptions, progressChan chan<- utils.ProgressInfo)
 			return ai.New+ai.Changes > aj.New+aj.Changes
 		})
 		result.AuthorsLines = authorsLines
+		result.TeamsLines = sumTeamsLines(authorsLines, teams)
 
 	}()
 
@@ -351,14 +619,56 @@ func AnalyseChanges(opts ChangesOptions, progressChan chan<- utils.ProgressInfo)
 			defer commitWorkersWaitGroup.Done()
 			for req := range commitWorkersInputChan {
 				// logrus.Debugf("Analysing commit %s", req.commitId)
-				files, err := utils.ExecDiffTree(req.repoDir, req.commitId)
+				// merge commits are compared to their first parent
+				parentCommitId := ""
+				// err must be local as the commit workers run in parallel
+				var files []string
+				var err error
+				if req.merge {
+					parentCommitId = req.commitId + "^1"
+					if opts.Merges == MergesFirstParent {
+						files, err = git.DiffTreeCommits(parentCommitId, req.commitId)
+					} else {
+						files, err = git.DiffTreeMerge(req.commitId)
+					}
+				} else {
+					files, err = git.DiffTree(req.commitId)
+				}
 				if err != nil {
 					logrus.Errorf("Error getting files changed in commit. err=%s", err)
 					panic(5)
 				}
 
+				commitInfo, err := git.CommitInfo(req.commitId)
+				if err != nil {
+					logrus.Errorf("Error getting commit info. err=%s", err)
+					panic(5)
+				}
+				commitInfosMutex.Lock()
+				commitInfos[req.commitId] = identities.ResolveCommitInfo(commitInfo)
+				commitInfosMutex.Unlock()
+
+				// renamed files are compared to their previous path instead of being considered new files
+				renames, err := git.FileRenames(req.commitId)
+				if err != nil {
+					logrus.Errorf("Error getting files renamed in commit. err=%s", err)
+					panic(5)
+				}
+				prevFilePaths := make(map[string]string, 0)
+				renamedFiles := make(map[string]bool, 0)
+				for _, rename := range renames {
+					prevFilePaths[rename.DstPath] = rename.SrcPath
+					if !rename.Copied {
+						renamedFiles[rename.SrcPath] = true
+					}
+				}
+
 				for _, fileName := range files {
-					if strings.Trim(fileName, " ") == "" || !fre.MatchString(fileName) || (opts.FilesNotRegex != "" && freNot.MatchString(fileName)) {
+					if renamedFiles[fileName] {
+						// the old path of a renamed file is analysed together with its new path
+						continue
+					}
+					if strings.Trim(fileName, " ") == "" || !fre.MatchString(fileName) || (opts.FilesNotRegex != "" && freNot.MatchString(fileName)) || excludeFilter.FileExcluded(fileName) {
 						// logrus.Debugf("Ignoring file %s", fileName)
 						continue
 					}
@@ -366,10 +676,18 @@ func AnalyseChanges(opts ChangesOptions, progressChan chan<- utils.ProgressInfo)
 					progressInfo.TotalTasks += 1
 					fileWorkersInputChan <- fileWorkerRequest{
 						repoDir:         opts.RepoDir,
+						git:             git,
 						filePath:        fileName,
+						prevFilePath:    prevFilePaths[fileName],
 						commitId:        req.commitId,
 						authorsRegex:    opts.AuthorsRegex,
-						authorsNotRegex: opts.AuthorsNotRegex,
+						authorsNotRegex: excludeFilter.AuthorsNotRegex(opts.AuthorsNotRegex),
+						churnWindow:     time.Duration(opts.ChurnWindowDays) * 24 * time.Hour,
+						ageBuckets:      ageBuckets,
+						identities:      identities,
+						fileCache:       fileCache,
+						parentCommitId:  parentCommitId,
+						coAuthors:       opts.CoAuthors,
 					}
 				}
 			}
@@ -382,18 +700,36 @@ func AnalyseChanges(opts ChangesOptions, progressChan chan<- utils.ProgressInfo)
 		return result, fmt.Errorf("Cannot mix opts.SinceDate/UntilDate with opts.SinceCommit/UntilCommit")
 	}
 
-	commitIds, sinceCommit, untilCommit, err := commitIdsForRange(opts)
+	commitIds, sinceCommit, untilCommit, err := commitIdsForRange(opts, git, gitOpts)
+	if err != nil {
+		return result, err
+	}
+	result.SinceCommit = identities.ResolveCommitInfo(sinceCommit)
+	result.UntilCommit = identities.ResolveCommitInfo(untilCommit)
+
+	excludeFilter, err = utils.NewExcludeFilter(git, opts.ExcludePresets, untilCommit.CommitId)
 	if err != nil {
 		return result, err
 	}
-	result.SinceCommit = sinceCommit
-	result.UntilCommit = untilCommit
+
+	// merge commits show no changes in diff-tree, so they are analysed apart
+	mergeCommits := make(map[string]bool, 0)
+	if opts.Merges == MergesFirstParent || opts.Merges == MergesInclude {
+		mergeIds, err := git.MergeCommitIds(sinceCommit.CommitId, untilCommit.CommitId)
+		if err != nil {
+			return result, fmt.Errorf("Error getting merge commits. err=%s", err)
+		}
+		for _, mergeId := range mergeIds {
+			mergeCommits[mergeId] = true
+		}
+	}
 
 	logrus.Debug("Sending commits to workers")
 	for _, commitId := range commitIds {
 		commitWorkersInputChan <- commitWorkerRequest{
 			repoDir:  opts.RepoDir,
 			commitId: commitId,
+			merge:    mergeCommits[commitId],
 		}
 	}
 	close(commitWorkersInputChan)
@@ -431,7 +767,8 @@ func AnalyseChanges(opts ChangesOptions, progressChan chan<- utils.ProgressInfo)
 	return result, nil
 }
 
-func commitIdsForRange(opts ChangesOptions) ([]string, utils.CommitInfo, utils.CommitInfo, error) {
+// commitIdsForRange commits in the range of dates or commits of the options. Ignored revisions are not returned
+func commitIdsForRange(opts ChangesOptions, git utils.GitBackend, gitOpts utils.GitOptions) ([]string, utils.CommitInfo, utils.CommitInfo, error) {
 	// find commit ids from dates
 	if opts.SinceDate != "" || opts.UntilDate != "" {
 		logrus.Debugf("Commit date range from %s to %s", opts.SinceDate, opts.UntilDate)
@@ -440,7 +777,7 @@ func commitIdsForRange(opts ChangesOptions) ([]string, utils.CommitInfo, utils.C
 			// allow git query to find commit at "until"
 			since = ""
 		}
-		commits, err := utils.ExecGetCommitsInDateRange(opts.RepoDir, opts.Branch, since, opts.UntilDate)
+		commits, err := git.CommitsInDateRange(opts.Branch, since, opts.UntilDate)
 		if err != nil {
 			return nil, utils.CommitInfo{}, utils.CommitInfo{}, err
 		}
@@ -448,7 +785,7 @@ func commitIdsForRange(opts ChangesOptions) ([]string, utils.CommitInfo, utils.C
 		opts.UntilCommit = commits[0].CommitId
 	}
 
-	commits, err := utils.ExecGetCommitsInCommitRange(opts.RepoDir, opts.Branch, opts.SinceCommit, opts.UntilCommit)
+	commits, err := git.CommitsInCommitRange(opts.Branch, opts.SinceCommit, opts.UntilCommit)
 	if err != nil {
 		return nil, utils.CommitInfo{}, utils.CommitInfo{}, err
 	}
@@ -460,12 +797,12 @@ func commitIdsForRange(opts ChangesOptions) ([]string, utils.CommitInfo, utils.C
 	}
 
 	// commits are in reverse order
-	sinceCommit, err := utils.ExecGitCommitInfo(opts.RepoDir, commitIds[len(commitIds)-1])
+	sinceCommit, err := git.CommitInfo(commitIds[len(commitIds)-1])
 	if err != nil {
 		return nil, utils.CommitInfo{}, utils.CommitInfo{}, fmt.Errorf("Error getting since commit. err=%s", err)
 	}
 
-	untilCommit, err := utils.ExecGitCommitInfo(opts.RepoDir, commitIds[0])
+	untilCommit, err := git.CommitInfo(commitIds[0])
 	if err != nil {
 		return nil, utils.CommitInfo{}, utils.CommitInfo{}, fmt.Errorf("Error getting until commit. err=%s", err)
 	}
@@ -479,9 +816,61 @@ func commitIdsForRange(opts ChangesOptions) ([]string, utils.CommitInfo, utils.C
 
 	logrus.Debugf("Commit ids range from %s to %s", sinceCommit.CommitId, untilCommit.CommitId)
 
+	commitIds, err = filterMergeCommits(opts, git, commitIds, sinceCommit.CommitId, untilCommit.CommitId)
+	if err != nil {
+		return nil, utils.CommitInfo{}, utils.CommitInfo{}, err
+	}
+
+	// ignored revisions are removed after resolving the range so that the range boundaries are kept
+	if len(gitOpts.IgnoreRevs) > 0 {
+		filteredIds := make([]string, 0, len(commitIds))
+		for _, commitId := range commitIds {
+			if gitOpts.IsIgnoredRev(commitId) {
+				logrus.Debugf("Ignoring revision %s", commitId)
+				continue
+			}
+			filteredIds = append(filteredIds, commitId)
+		}
+		commitIds = filteredIds
+	}
+
 	return commitIds, sinceCommit, untilCommit, nil
 }
 
+// filterMergeCommits removes merge commits from commitIds when merges are skipped, or
+// commits from merged branches when only the first parent history is analysed. The since commit is always kept
+func filterMergeCommits(opts ChangesOptions, git utils.GitBackend, commitIds []string, sinceCommitId string, untilCommitId string) ([]string, error) {
+	if opts.Merges == MergesInclude {
+		return commitIds, nil
+	}
+
+	// first parent commits are kept, or merge commits are removed
+	keepListed := opts.Merges == MergesFirstParent
+	var listedIds []string
+	var err error
+	if keepListed {
+		listedIds, err = git.FirstParentCommitIds(sinceCommitId, untilCommitId)
+	} else {
+		listedIds, err = git.MergeCommitIds(sinceCommitId, untilCommitId)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error getting commits to filter merges. err=%s", err)
+	}
+	listed := make(map[string]bool, len(listedIds))
+	for _, commitId := range listedIds {
+		listed[commitId] = true
+	}
+
+	filteredIds := make([]string, 0, len(commitIds))
+	for _, commitId := range commitIds {
+		if commitId == sinceCommitId || listed[commitId] == keepListed {
+			filteredIds = append(filteredIds, commitId)
+		}
+	}
+	logrus.Debugf("%d of %d commits kept with merges=%s", len(filteredIds), len(commitIds), opts.Merges)
+	return filteredIds, nil
+}
+
 func sumFilesTouched(map1 map[string]FileTouched, map2 map[string]FileTouched) map[string]FileTouched {
 	if map1 == nil {
 		map1 = make(map[string]FileTouched, 0)
@@ -495,3 +884,43 @@ func sumFilesTouched(map1 map[string]FileTouched, map2 map[string]FileTouched) m
 	}
 	return map1
 }
+
+func sumCollaborations(collaboration1 AuthorCollaboration, collaboration2 AuthorCollaboration) AuthorCollaboration {
+	collaboration2.Refactor += collaboration1.Refactor
+	collaboration2.Churn += collaboration1.Churn
+	collaboration2.Deleted += collaboration1.Deleted
+	return collaboration2
+}
+
+// sumTeamsLines aggregates the lines touched by authors of the same team
+func sumTeamsLines(authorsLines []AuthorLines, teams *utils.TeamResolver) []TeamLines {
+	teamsLines := make([]TeamLines, 0)
+	if teams == nil {
+		return teamsLines
+	}
+
+	teamsLinesMap := make(map[string]TeamLines, 0)
+	for _, authorLines := range authorsLines {
+		teamName := teams.TeamOf(authorLines.AuthorName, authorLines.AuthorMail)
+		teamLines, ok := teamsLinesMap[teamName]
+		if !ok {
+			teamLines = TeamLines{TeamName: teamName, AuthorNames: make([]string, 0)}
+		}
+		teamLines.AuthorNames = append(teamLines.AuthorNames, authorLines.AuthorName)
+		teamLines.LinesTouched = SumLinesTouched(teamLines.LinesTouched, authorLines.LinesTouched)
+		teamsLinesMap[teamName] = teamLines
+	}
+
+	for _, teamLines := range teamsLinesMap {
+		teamsLines = append(teamsLines, teamLines)
+	}
+	sort.Slice(teamsLines, func(i, j int) bool {
+		ti := teamsLines[i].LinesTouched
+		tj := teamsLines[j].LinesTouched
+		if ti.New+ti.Changes == tj.New+tj.Changes {
+			return teamsLines[i].TeamName < teamsLines[j].TeamName
+		}
+		return ti.New+ti.Changes > tj.New+tj.Changes
+	})
+	return teamsLines
+}
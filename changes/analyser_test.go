@@ -2,6 +2,7 @@ package changes
 
 import (
 	"fmt"
+	"os"
 	"testing"
 	"time"
 
@@ -82,13 +83,15 @@ func TestAnalyseChangesFile1(t *testing.T) {
 	require.Equal(t, 4, result.TotalCommits)
 	require.Equal(t, 1, result.TotalFiles)
 	require.Equal(t, 3, result.TotalLinesTouched.New)
-	require.Equal(t, 3, result.TotalLinesTouched.Changes)
+	require.Equal(t, 2, result.TotalLinesTouched.Changes)
+	// the deleted line is counted apart from changes
+	require.Equal(t, 1, result.TotalLinesTouched.Deleted)
 
 	require.Equal(t, 2, len(result.AuthorsLines))
 
 	require.Equal(t, "author1", result.AuthorsLines[0].AuthorName)
 	require.Equal(t, "file1", result.AuthorsLines[0].FilesTouched[0].Name)
-	require.Equal(t, 4, result.AuthorsLines[0].FilesTouched[0].Lines)
+	require.Equal(t, 3, result.AuthorsLines[0].FilesTouched[0].Lines)
 
 	require.Equal(t, "author2", result.AuthorsLines[1].AuthorName)
 	require.Equal(t, "file1", result.AuthorsLines[1].FilesTouched[0].Name)
@@ -117,13 +120,14 @@ func TestAnalyseChangesFile1Author1(t *testing.T) {
 	require.Equal(t, 3, result.TotalCommits)
 	require.Equal(t, 1, result.TotalFiles)
 	require.Equal(t, 2, result.TotalLinesTouched.New)
-	require.Equal(t, 2, result.TotalLinesTouched.Changes)
+	require.Equal(t, 1, result.TotalLinesTouched.Changes)
+	require.Equal(t, 1, result.TotalLinesTouched.Deleted)
 
 	require.Equal(t, 1, len(result.AuthorsLines))
 
 	require.Equal(t, "author1", result.AuthorsLines[0].AuthorName)
 	require.Equal(t, "file1", result.AuthorsLines[0].FilesTouched[0].Name)
-	require.Equal(t, 4, result.AuthorsLines[0].FilesTouched[0].Lines)
+	require.Equal(t, 3, result.AuthorsLines[0].FilesTouched[0].Lines)
 }
 
 func TestAnalyseChangesNotAuthor1(t *testing.T) {
@@ -178,7 +182,8 @@ func TestAnalyseChangesAllFiles(t *testing.T) {
 	require.Equal(t, 5, result.TotalCommits)
 	require.Equal(t, 2, result.TotalFiles)
 	require.Equal(t, 8, result.TotalLinesTouched.New)
-	require.Equal(t, 3, result.TotalLinesTouched.Changes)
+	require.Equal(t, 2, result.TotalLinesTouched.Changes)
+	require.Equal(t, 1, result.TotalLinesTouched.Deleted)
 
 	require.Equal(t, 3, len(result.AuthorsLines))
 
@@ -189,7 +194,7 @@ func TestAnalyseChangesAllFiles(t *testing.T) {
 
 	require.Equal(t, "author1", result.AuthorsLines[1].AuthorName)
 	require.Equal(t, "file1", result.AuthorsLines[1].FilesTouched[0].Name)
-	require.Equal(t, 4, result.AuthorsLines[1].FilesTouched[0].Lines)
+	require.Equal(t, 3, result.AuthorsLines[1].FilesTouched[0].Lines)
 
 	require.Equal(t, "author2", result.AuthorsLines[2].AuthorName)
 	require.Equal(t, "file1", result.AuthorsLines[2].FilesTouched[0].Name)
@@ -249,13 +254,404 @@ func TestCommitIdsForRange(t *testing.T) {
 	if err != nil {
 		return
 	}
-	commitIds, sinceCommit, untilCommit, err := commitIdsForRange(ChangesOptions{
-		BaseOptions: utils.BaseOptions{
-			RepoDir: repoDir,
-			Branch:  "main",
-		},
-	})
+	for _, backend := range []string{utils.GitBackendExec, utils.GitBackendGoGit} {
+		git, err := utils.NewGitBackend(backend, repoDir, utils.GitOptions{})
+		require.Nil(t, err)
+		defer git.Close()
+
+		commitIds, sinceCommit, untilCommit, err := commitIdsForRange(ChangesOptions{
+			BaseOptions: utils.BaseOptions{
+				RepoDir: repoDir,
+				Branch:  "main",
+			},
+		}, git, utils.GitOptions{})
+		require.Nil(t, err)
+		require.Equal(t, 4, len(commitIds))
+		require.True(t, sinceCommit.Date.Before(untilCommit.Date))
+	}
+}
+
+func TestAnalyseChangesIdentities(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	identitiesFile := t.TempDir() + "/identities.yml"
+	err = os.WriteFile(identitiesFile, []byte(`
+- name: Author One
+  mail: author1@mail.com
+  mails: [author2@mail.com]
+`), 0644)
+	require.Nil(t, err)
+
+	result, err := AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main", FilesRegex: "file1", IdentitiesFile: identitiesFile},
+	}, nil)
+
+	require.Nil(t, err)
+	require.Equal(t, 4, result.TotalCommits)
+	require.Equal(t, 3, result.TotalLinesTouched.New)
+	require.Equal(t, 2, result.TotalLinesTouched.Changes)
+	require.Equal(t, 1, result.TotalLinesTouched.Deleted)
+	require.Equal(t, 1, len(result.AuthorsLines))
+	require.Equal(t, "Author One", result.AuthorsLines[0].AuthorName)
+	require.Equal(t, "<author1@mail.com>", result.AuthorsLines[0].AuthorMail)
+	// changes between author1 and author2 are now changes to own lines
+	require.Equal(t, 0, result.AuthorsLines[0].LinesTouched.ChurnOther)
+	require.Equal(t, 2, result.AuthorsLines[0].LinesTouched.ChurnOwn)
+}
+
+func TestAnalyseChangesTeams(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	teamsFile := t.TempDir() + "/teams.yml"
+	err = os.WriteFile(teamsFile, []byte("squad-a:\n  - author1\n  - author2\n"), 0644)
+	require.Nil(t, err)
+
+	result, err := AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main", FilesRegex: "file1", TeamsFile: teamsFile},
+	}, nil)
+
+	require.Nil(t, err)
+	require.Equal(t, 1, len(result.TeamsLines))
+	require.Equal(t, "squad-a", result.TeamsLines[0].TeamName)
+	require.Equal(t, result.TotalLinesTouched.New, result.TeamsLines[0].LinesTouched.New)
+	require.Equal(t, result.TotalLinesTouched.Changes, result.TeamsLines[0].LinesTouched.Changes)
+}
+
+func TestAnalyseChangesGoGit(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	opts := ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"},
+	}
+	execResult, err := AnalyseChanges(opts, nil)
+	require.Nil(t, err)
+
+	opts.GitBackend = utils.GitBackendGoGit
+	goGitResult, err := AnalyseChanges(opts, nil)
+	require.Nil(t, err)
+	require.Equal(t, execResult.TotalCommits, goGitResult.TotalCommits)
+	require.Equal(t, execResult.TotalFiles, goGitResult.TotalFiles)
+	require.Equal(t, execResult.TotalLinesTouched, goGitResult.TotalLinesTouched)
+	require.Equal(t, execResult.AuthorsLines, goGitResult.AuthorsLines)
+}
+
+func TestAnalyseChangesRenamedFile(t *testing.T) {
+	repoDir, err := utils.ResolveTestRenamedFilesRepo()
+	require.Nil(t, err)
+
+	for _, backend := range []string{utils.GitBackendExec, utils.GitBackendGoGit} {
+		results, err := AnalyseChanges(ChangesOptions{
+			BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main", GitBackend: backend},
+		}, nil)
+		require.Nil(t, err)
+
+		// the old path of the renamed file is not analysed
+		require.Equal(t, 2, results.TotalFiles)
+		require.Equal(t, 5, results.TotalLinesTouched.New)
+		require.Equal(t, 1, results.TotalLinesTouched.Changes)
+		require.Equal(t, 0, results.TotalLinesTouched.Deleted)
+		require.Equal(t, 4, results.TotalLinesTouched.Moved)
+
+		for _, al := range results.AuthorsLines {
+			if al.AuthorName == "author2" {
+				require.Equal(t, 0, al.LinesTouched.New)
+				require.Equal(t, 1, al.LinesTouched.ChurnOther)
+				require.Equal(t, 4, al.LinesTouched.Moved)
+			}
+		}
+	}
+}
+
+func TestAnalyseChangesAgeBuckets(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	results, err := AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"},
+		AgeBuckets:  "1,7",
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, []string{"<1d", "<7d", ">=7d"}, results.AgeBucketNames)
+	// all lines of the test repo were changed in the same day
+	require.Equal(t, []int{2, 0, 0}, results.TotalLinesTouched.AgeBuckets)
+
+	_, err = AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"},
+		AgeBuckets:  "7,1",
+	}, nil)
+	require.NotNil(t, err)
+}
+
+func TestAnalyseChangesIgnoreWhitespace(t *testing.T) {
+	repoDir, err := utils.ResolveTestWhitespaceRepo()
+	require.Nil(t, err)
+
+	results, err := AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"},
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 4, results.TotalLinesTouched.New)
+	require.Equal(t, 3, results.TotalLinesTouched.Changes)
+	require.Equal(t, 3, len(results.AuthorsLines))
+
+	// the reformatting commit of author2 isn't counted
+	results, err = AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main", IgnoreWhitespace: true},
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 3, results.TotalLinesTouched.New)
+	require.Equal(t, 1, results.TotalLinesTouched.Changes)
+	require.Equal(t, 2, len(results.AuthorsLines))
+	require.Equal(t, "author1", results.AuthorsLines[0].AuthorName)
+	require.Equal(t, 1, results.AuthorsLines[0].LinesTouched.ChurnReceived)
+	require.Equal(t, "author3", results.AuthorsLines[1].AuthorName)
+}
+
+func TestAnalyseChangesIgnoreRevs(t *testing.T) {
+	repoDir, err := utils.ResolveTestWhitespaceRepo()
+	require.Nil(t, err)
+
+	commits, err := utils.ExecGetCommitsInDateRange(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	ignoreRevsFile := t.TempDir() + "/.git-blame-ignore-revs"
+	err = os.WriteFile(ignoreRevsFile, []byte("# reformat\n"+commits[1].CommitId+"\n"), 0644)
+	require.Nil(t, err)
+
+	// the reformatting commit of author2 is skipped
+	results, err := AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main", IgnoreRevsFile: ignoreRevsFile},
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 2, results.TotalCommits)
+	require.Equal(t, 3, results.TotalLinesTouched.New)
+	require.Equal(t, 1, results.TotalLinesTouched.Changes)
+	require.Equal(t, 2, len(results.AuthorsLines))
+	require.Equal(t, "author1", results.AuthorsLines[0].AuthorName)
+	require.Equal(t, 1, results.AuthorsLines[0].LinesTouched.ChurnReceived)
+
+	_, err = AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main", IgnoreRevsFile: repoDir + "/missing"},
+	}, nil)
+	require.NotNil(t, err)
+}
+
+func TestAnalyseChangesMerges(t *testing.T) {
+	repoDir, err := utils.ResolveTestMergesRepo()
+	require.Nil(t, err)
+
+	// the line changed while merging is not counted
+	results, err := AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"},
+		Merges:      MergesSkip,
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 3, results.TotalCommits)
+	require.Equal(t, 7, results.TotalLinesTouched.New)
+	require.Equal(t, 2, results.TotalLinesTouched.Changes)
+	require.Equal(t, 2, len(results.AuthorsLines))
+
+	// the merge is analysed as a single change that includes the changes of the merged branch
+	results, err = AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"},
+		Merges:      MergesFirstParent,
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 3, results.TotalCommits)
+	require.Equal(t, 7, results.TotalLinesTouched.New)
+	require.Equal(t, 3, results.TotalLinesTouched.Changes)
+	require.Equal(t, 3, len(results.AuthorsLines))
+
+	// all commits are analysed and the merge is analysed in the file changed while merging
+	results, err = AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"},
+		Merges:      MergesInclude,
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 4, results.TotalCommits)
+	require.Equal(t, 7, results.TotalLinesTouched.New)
+	require.Equal(t, 4, results.TotalLinesTouched.Changes)
+	require.Equal(t, 3, len(results.AuthorsLines))
+
+	_, err = AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"},
+		Merges:      "all",
+	}, nil)
+	require.NotNil(t, err)
+}
+
+func TestAnalyseChangesCoAuthors(t *testing.T) {
+	repoDir, err := utils.ResolveTestCoAuthorsRepo()
+	require.Nil(t, err)
+
+	newLines := func(results ChangesResult) map[string]int {
+		lines := make(map[string]int)
+		for _, authorLines := range results.AuthorsLines {
+			lines[authorLines.AuthorName] = authorLines.LinesTouched.New
+		}
+		return lines
+	}
+
+	// lines are split between the author and the co-authors of each commit
+	results, err := AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"},
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 7, results.TotalLinesTouched.New)
+	require.Equal(t, 7, results.TotalLinesTouched.CoAuthored)
+	require.Equal(t, map[string]int{"author1": 3, "author2": 3, "author3": 1}, newLines(results))
+
+	// all lines are credited to each author of the commit, but touched only once
+	results, err = AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"},
+		CoAuthors:   CoAuthorsFull,
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 7, results.TotalLinesTouched.New)
+	require.Equal(t, 7, results.TotalLinesTouched.CoAuthored)
+	require.Equal(t, map[string]int{"author1": 7, "author2": 7, "author3": 3}, newLines(results))
+
+	results, err = AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"},
+		CoAuthors:   CoAuthorsIgnore,
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 7, results.TotalLinesTouched.New)
+	require.Equal(t, 0, results.TotalLinesTouched.CoAuthored)
+	require.Equal(t, map[string]int{"author1": 4, "author3": 3}, newLines(results))
+
+	_, err = AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"},
+		CoAuthors:   "all",
+	}, nil)
+	require.NotNil(t, err)
+}
+
+func TestAnalyseChangesCommitStats(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	results, err := AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"},
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, results.TotalCommits, len(results.Commits))
+
+	lines := LinesTouched{}
+	authorCommits := 0
+	for i, commit := range results.Commits {
+		require.NotEmpty(t, commit.AuthorName)
+		require.True(t, commit.TotalFiles > 0)
+		if i > 0 {
+			// newest first
+			require.False(t, commit.Date.After(results.Commits[i-1].Date))
+		}
+		lines = SumLinesTouched(lines, commit.LinesTouched)
+	}
+	for _, authorLines := range results.AuthorsLines {
+		authorCommits += authorLines.Commits
+	}
+	require.Equal(t, results.TotalLinesTouched.New, lines.New)
+	require.Equal(t, results.TotalLinesTouched.Changes, lines.Changes)
+	require.Equal(t, results.TotalCommits, authorCommits)
+}
+
+func TestAnalyseChangesFilesLines(t *testing.T) {
+	repoDir, err := utils.ResolveTestCoAuthorsRepo()
+	require.Nil(t, err)
+
+	results, err := AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"},
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(results.FilesLines))
+	require.Equal(t, "file1", results.FilesLines[0].FilePath)
+	require.Equal(t, 2, results.FilesLines[0].Commits)
+	require.Equal(t, 3, results.FilesLines[0].Authors)
+	require.Equal(t, 7, results.FilesLines[0].LinesTouched.New)
+
+	results, err = AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"},
+		CoAuthors:   CoAuthorsIgnore,
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 2, results.FilesLines[0].Authors)
+}
+
+func TestAnalyseChangesCollaborations(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	results, err := AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"},
+	}, nil)
+	require.Nil(t, err)
+	require.True(t, len(results.Collaborations) > 0)
+
+	refactor, churn, deleted := 0, 0, 0
+	for _, collaboration := range results.Collaborations {
+		require.NotEqual(t, collaboration.AuthorName, collaboration.OwnerName)
+		refactor += collaboration.Refactor
+		churn += collaboration.Churn
+		deleted += collaboration.Deleted
+	}
+	// deleted lines are counted only as deleted, apart from refactor and churn
+	require.Equal(t, results.TotalLinesTouched.RefactorReceived+results.TotalLinesTouched.ChurnReceived, refactor+churn)
+	require.Equal(t, results.TotalLinesTouched.DeletedReceived, deleted)
+
+	// author1 and author2 changed lines of each other
+	require.Equal(t, 2, len(results.Collaborations))
+	require.Equal(t, "author1", results.Collaborations[0].AuthorName)
+	require.Equal(t, "author2", results.Collaborations[0].OwnerName)
+	require.Equal(t, 1, results.Collaborations[0].Churn)
+	require.Equal(t, "author2", results.Collaborations[1].AuthorName)
+	require.Equal(t, "author1", results.Collaborations[1].OwnerName)
+}
+
+func TestAnalyseChangesCollaborationsDeleted(t *testing.T) {
+	repoDir, err := utils.ResolveTestDeletedFilesRepo()
+	require.Nil(t, err)
+
+	results, err := AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"},
+	}, nil)
+	require.Nil(t, err)
+
+	// author2 deleted one line of file1 and the three lines of file2, all written by author1
+	require.Equal(t, 1, len(results.Collaborations))
+	require.Equal(t, "author2", results.Collaborations[0].AuthorName)
+	require.Equal(t, "author1", results.Collaborations[0].OwnerName)
+	require.Equal(t, 0, results.Collaborations[0].Refactor)
+	require.Equal(t, 0, results.Collaborations[0].Churn)
+	require.Equal(t, 4, results.Collaborations[0].Deleted)
+
+	authors, matrix := CollaborationMatrix(results.Collaborations, 0)
+	require.Equal(t, []string{"author1", "author2"}, authors)
+	require.Equal(t, [][]int{{0, 0}, {4, 0}}, matrix)
+}
+
+func TestAnalyseChangesExcludePresets(t *testing.T) {
+	repoDir, err := utils.ResolveTestExcludePresetsRepo()
+	require.Nil(t, err)
+
+	results, err := AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"},
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 2, results.TotalCommits)
+	require.Equal(t, 7, results.TotalFiles)
+	require.Equal(t, 2, len(results.AuthorsLines))
+
+	// the bot commit and the lock, vendored and generated files are not analysed
+	results, err = AnalyseChanges(ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main", ExcludePresets: "bots,lockfiles,vendor,generated"},
+	}, nil)
 	require.Nil(t, err)
-	require.Equal(t, 4, len(commitIds))
-	require.True(t, sinceCommit.Date.Before(untilCommit.Date))
+	require.Equal(t, 1, results.TotalCommits)
+	require.Equal(t, 3, results.TotalFiles)
+	require.Equal(t, 1, len(results.AuthorsLines))
+	require.Equal(t, "author1", results.AuthorsLines[0].AuthorName)
 }
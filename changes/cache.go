@@ -11,7 +11,9 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-var cacheTable = "GITWHO_CHANGES_CACHE"
+// results are stored as json, so this table must be renamed
+// when the json attributes of ChangesResult are changed
+var cacheTable = "GITWHO_CHANGES_CACHE_V14"
 
 func GetFromCache(opts ChangesOptions) (*ChangesResult, error) {
 	// logrus.Debugf("Reusing results found in cache file")
@@ -79,16 +81,29 @@ func getCacheKey(opts ChangesOptions) string {
 		add = time.Now().Format(time.DateOnly)
 	}
 
-	return fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s",
+	return fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%t:%s:%s:%s:%s:%s:%s:%s:%d:%s:%s:%s:%s",
 		opts.RepoDir,
 		opts.Branch,
 		opts.AuthorsRegex,
 		opts.AuthorsNotRegex,
 		opts.FilesRegex,
 		opts.FilesNotRegex,
+		opts.IdentitiesFile,
+		opts.IdentitiesKey(),
+		opts.TeamsFile,
+		opts.TeamsKey(),
+		opts.GitBackend,
+		opts.IgnoreWhitespace,
+		opts.IgnoreRevsFile,
+		opts.IgnoreRevsKey(),
+		opts.ExcludePresets,
 		opts.SinceDate,
 		opts.UntilDate,
 		opts.SinceCommit,
 		opts.UntilCommit,
+		opts.ChurnWindowDays,
+		opts.AgeBuckets,
+		opts.Merges,
+		opts.CoAuthors,
 		add)
 }
@@ -52,3 +52,38 @@ func TestSaveNewCachedResultsOwnership(t *testing.T) {
 	require.NotNil(t, result2)
 	require.Equal(t, sampleResult, *result2)
 }
+
+func TestFileCacheChanges(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+	cacheFile := t.TempDir() + "/gitwho-cache"
+
+	opts := ChangesOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main", AuthorsRegex: "author1", CacheFile: cacheFile, CacheTTLSeconds: 60},
+	}
+	_, err = AnalyseChanges(opts, nil)
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+	files, err := utils.ExecDiffTree(repoDir, commit.CommitId)
+	require.Nil(t, err)
+	require.NotEmpty(t, files)
+	cachedb, err := utils.NewCacheDB(cacheFile, cacheTable, 60)
+	require.Nil(t, err)
+	value, err := cachedb.GetFileValue(commit.CommitId, files[0], fileGitDataCacheVersion+":"+utils.GitBackendExec)
+	cachedb.Close()
+	require.Nil(t, err)
+	require.NotNil(t, value)
+
+	// different filters reuse the git data of each file
+	opts.AuthorsRegex = "author2"
+	cachedResult, err := AnalyseChanges(opts, nil)
+	require.Nil(t, err)
+
+	opts.CacheFile = ""
+	result, err := AnalyseChanges(opts, nil)
+	require.Nil(t, err)
+	require.Equal(t, result.TotalLinesTouched, cachedResult.TotalLinesTouched)
+	require.Equal(t, result.AuthorsLines, cachedResult.AuthorsLines)
+}
@@ -0,0 +1,212 @@
+package changes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/flaviostutz/gitwho/utils"
+)
+
+type CouplingOptions struct {
+	ChangesOptions
+	// MinRevisions files changed in less commits than this are ignored
+	MinRevisions int `json:"min_revisions"`
+	// MinSharedRevisions pairs of files changed together in less commits than this are ignored
+	MinSharedRevisions int `json:"min_shared_revisions"`
+	// MinDegree min degree of coupling, in percent, for a pair of files to be reported
+	MinDegree float64 `json:"min_degree"`
+	// MaxChangesetSize commits with more files than this (large refactorings, formatting etc) are ignored. Unlimited if zero
+	MaxChangesetSize int `json:"max_changeset_size"`
+	// ModuleDepth if greater than zero, files are grouped by their first ModuleDepth dirs
+	// and the coupling is calculated between these modules instead of files
+	ModuleDepth int `json:"module_depth"`
+}
+
+// FileCoupling how much two files (or modules) change together
+type FileCoupling struct {
+	File1 string `json:"file1"`
+	File2 string `json:"file2"`
+	// Revisions1 number of commits that changed File1
+	Revisions1 int `json:"revisions1"`
+	// Revisions2 number of commits that changed File2
+	Revisions2 int `json:"revisions2"`
+	// SharedRevisions number of commits that changed both files
+	SharedRevisions int `json:"shared_revisions"`
+	// Support percentage of the analysed commits that changed both files
+	Support float64 `json:"support"`
+	// Confidence1 percentage of the commits that changed File1 that also changed File2
+	Confidence1 float64 `json:"confidence1"`
+	// Confidence2 percentage of the commits that changed File2 that also changed File1
+	Confidence2 float64 `json:"confidence2"`
+	// Degree percentage of shared commits over the average number of commits of the two files
+	Degree float64 `json:"degree"`
+}
+
+// CouplingGroup files that are coupled to each other, directly or through other files of the group
+type CouplingGroup struct {
+	Files []string `json:"files"`
+}
+
+type CouplingResult struct {
+	SinceCommit utils.CommitInfo `json:"since_commit"`
+	UntilCommit utils.CommitInfo `json:"until_commit"`
+	// TotalCommits number of commits used to calculate the coupling (after ignoring large changesets)
+	TotalCommits int `json:"total_commits"`
+	// Pairs coupled files, sorted by degree of coupling (highest first)
+	Pairs []FileCoupling `json:"pairs"`
+	// Groups coupled files, largest groups first
+	Groups []CouplingGroup `json:"groups"`
+}
+
+// AnalyseCoupling finds files that usually change in the same commits (temporal coupling)
+func AnalyseCoupling(opts CouplingOptions, progressChan chan<- utils.ProgressInfo) (CouplingResult, error) {
+	if opts.MinDegree < 0 || opts.MinDegree > 100 {
+		return CouplingResult{}, fmt.Errorf("Min degree should be between 0 and 100")
+	}
+	changesResult, err := AnalyseChanges(opts.ChangesOptions, progressChan)
+	if err != nil {
+		return CouplingResult{}, err
+	}
+	result := CalcCoupling(changesResult.Commits, opts)
+	result.SinceCommit = changesResult.SinceCommit
+	result.UntilCommit = changesResult.UntilCommit
+	return result, nil
+}
+
+// CalcCoupling calculates the coupling between the files changed in the commits
+func CalcCoupling(commits []CommitStats, opts CouplingOptions) CouplingResult {
+	result := CouplingResult{
+		Pairs:  make([]FileCoupling, 0),
+		Groups: make([]CouplingGroup, 0),
+	}
+
+	revisions := make(map[string]int, 0)
+	sharedRevisions := make(map[[2]string]int, 0)
+	for _, commit := range commits {
+		files := changesetFiles(commit.Files, opts.ModuleDepth)
+		if len(files) == 0 || (opts.MaxChangesetSize > 0 && len(files) > opts.MaxChangesetSize) {
+			continue
+		}
+		result.TotalCommits++
+		for i, file1 := range files {
+			revisions[file1]++
+			for _, file2 := range files[i+1:] {
+				sharedRevisions[[2]string{file1, file2}]++
+			}
+		}
+	}
+
+	for pair, shared := range sharedRevisions {
+		revisions1 := revisions[pair[0]]
+		revisions2 := revisions[pair[1]]
+		if shared < opts.MinSharedRevisions || revisions1 < opts.MinRevisions || revisions2 < opts.MinRevisions {
+			continue
+		}
+		coupling := FileCoupling{
+			File1:           pair[0],
+			File2:           pair[1],
+			Revisions1:      revisions1,
+			Revisions2:      revisions2,
+			SharedRevisions: shared,
+			Support:         perc(shared, result.TotalCommits),
+			Confidence1:     perc(shared, revisions1),
+			Confidence2:     perc(shared, revisions2),
+			Degree:          perc(2*shared, revisions1+revisions2),
+		}
+		if coupling.Degree < opts.MinDegree {
+			continue
+		}
+		result.Pairs = append(result.Pairs, coupling)
+	}
+
+	sort.Slice(result.Pairs, func(i, j int) bool {
+		pi := result.Pairs[i]
+		pj := result.Pairs[j]
+		if pi.Degree != pj.Degree {
+			return pi.Degree > pj.Degree
+		}
+		if pi.SharedRevisions != pj.SharedRevisions {
+			return pi.SharedRevisions > pj.SharedRevisions
+		}
+		if pi.File1 != pj.File1 {
+			return pi.File1 < pj.File1
+		}
+		return pi.File2 < pj.File2
+	})
+
+	result.Groups = couplingGroups(result.Pairs)
+	return result
+}
+
+// changesetFiles unique files (or modules, if moduleDepth > 0) of a commit, sorted
+func changesetFiles(files []string, moduleDepth int) []string {
+	unique := make(map[string]bool, 0)
+	for _, file := range files {
+		if moduleDepth > 0 {
+			parts := strings.Split(file, "/")
+			if len(parts) > moduleDepth {
+				parts = parts[:moduleDepth]
+			}
+			file = strings.Join(parts, "/")
+		}
+		unique[file] = true
+	}
+	results := make([]string, 0, len(unique))
+	for file := range unique {
+		results = append(results, file)
+	}
+	sort.Strings(results)
+	return results
+}
+
+// couplingGroups connected components of the graph of coupled files
+func couplingGroups(pairs []FileCoupling) []CouplingGroup {
+	parent := make(map[string]string, 0)
+	var find func(file string) string
+	find = func(file string) string {
+		if parent[file] == file {
+			return file
+		}
+		root := find(parent[file])
+		parent[file] = root
+		return root
+	}
+	for _, pair := range pairs {
+		for _, file := range []string{pair.File1, pair.File2} {
+			if _, ok := parent[file]; !ok {
+				parent[file] = file
+			}
+		}
+		root1 := find(pair.File1)
+		root2 := find(pair.File2)
+		if root1 != root2 {
+			parent[root2] = root1
+		}
+	}
+
+	groupsMap := make(map[string][]string, 0)
+	for file := range parent {
+		root := find(file)
+		groupsMap[root] = append(groupsMap[root], file)
+	}
+	groups := make([]CouplingGroup, 0)
+	for _, files := range groupsMap {
+		sort.Strings(files)
+		groups = append(groups, CouplingGroup{Files: files})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if len(groups[i].Files) != len(groups[j].Files) {
+			return len(groups[i].Files) > len(groups[j].Files)
+		}
+		return groups[i].Files[0] < groups[j].Files[0]
+	})
+	return groups
+}
+
+func perc(value int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(value) * 100 / float64(total)
+}
@@ -0,0 +1,70 @@
+package changes
+
+import (
+	"testing"
+
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalcCoupling(t *testing.T) {
+	commits := []CommitStats{
+		{Files: []string{"a/1", "a/2", "b/1"}},
+		{Files: []string{"a/1", "a/2"}},
+		{Files: []string{"a/1", "a/2"}},
+		{Files: []string{"a/1", "c/1"}},
+		{Files: []string{"c/1", "d/1"}},
+		{Files: []string{"a/1", "b/1", "c/1", "d/1", "e/1"}},
+	}
+
+	result := CalcCoupling(commits, CouplingOptions{MinRevisions: 1, MinSharedRevisions: 2, MaxChangesetSize: 4})
+	require.Equal(t, 5, result.TotalCommits)
+	require.Equal(t, 1, len(result.Pairs))
+	pair := result.Pairs[0]
+	require.Equal(t, "a/1", pair.File1)
+	require.Equal(t, "a/2", pair.File2)
+	require.Equal(t, 4, pair.Revisions1)
+	require.Equal(t, 3, pair.Revisions2)
+	require.Equal(t, 3, pair.SharedRevisions)
+	require.Equal(t, 60.0, pair.Support)
+	require.Equal(t, 75.0, pair.Confidence1)
+	require.Equal(t, 100.0, pair.Confidence2)
+	require.InDelta(t, 85.7, pair.Degree, 0.1)
+
+	result = CalcCoupling(commits, CouplingOptions{MinRevisions: 1, MinSharedRevisions: 1, MinDegree: 50, MaxChangesetSize: 4})
+	require.Equal(t, 3, len(result.Pairs))
+	require.Equal(t, 2, len(result.Groups))
+	require.Equal(t, []string{"a/1", "a/2", "b/1"}, result.Groups[0].Files)
+	require.Equal(t, []string{"c/1", "d/1"}, result.Groups[1].Files)
+
+	// coupling between modules
+	result = CalcCoupling(commits, CouplingOptions{MinRevisions: 1, MinSharedRevisions: 2, ModuleDepth: 1})
+	require.Equal(t, 6, result.TotalCommits)
+	require.Equal(t, 3, len(result.Pairs))
+	require.Equal(t, "c", result.Pairs[0].File1)
+	require.Equal(t, "d", result.Pairs[0].File2)
+	require.Equal(t, 80.0, result.Pairs[0].Degree)
+	require.Equal(t, "a", result.Pairs[1].File1)
+	require.Equal(t, "b", result.Pairs[1].File2)
+}
+
+func TestAnalyseCoupling(t *testing.T) {
+	repoDir, err := utils.ResolveTestExcludePresetsRepo()
+	require.Nil(t, err)
+
+	result, err := AnalyseCoupling(CouplingOptions{
+		ChangesOptions:     ChangesOptions{BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"}},
+		MinRevisions:       1,
+		MinSharedRevisions: 2,
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 2, result.TotalCommits)
+	require.Equal(t, 1, len(result.Pairs))
+	require.Equal(t, "file1", result.Pairs[0].File1)
+	require.Equal(t, "package-lock.json", result.Pairs[0].File2)
+	require.Equal(t, 100.0, result.Pairs[0].Degree)
+	require.Equal(t, 1, len(result.Groups))
+
+	_, err = AnalyseCoupling(CouplingOptions{MinDegree: 101}, nil)
+	require.NotNil(t, err)
+}
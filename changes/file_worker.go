@@ -1,6 +1,7 @@
 package changes
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
@@ -29,60 +30,48 @@ func fileAnalysisWorker(fileWorkerInputChan <-chan fileWorkerRequest, analyseFil
 				TotalLinesTouched: LinesTouched{},
 				authorLinesMap:    make(map[string]AuthorLines, 0),
 				AuthorsLines:      []AuthorLines{},
+				collaborationsMap: make(map[string]AuthorCollaboration, 0),
 			},
 		}
 
-		fsize, err := utils.ExecTreeFileSize(req.repoDir, req.commitId, req.filePath)
+		gitData, err := fileGitData(req)
 		if err != nil {
-			// can't get file size when the file was deleted by commit, so it's not present anymore
-			// TODO get previous version of the file and count these lines as "changed" because they were deleted?
-			skippedFiles++
-			continue
-		}
-		if fsize > 80000 {
-			logrus.Debugf("Ignoring file because it's too big. file=%s, size=%d", req.filePath, fsize)
-			skippedFiles++
-			continue
-		}
-
-		isBin, err := utils.ExecDiffIsBinary(req.repoDir, req.commitId, req.filePath)
-		if err != nil {
-			analyseFileErrChan <- errors.New(fmt.Sprintf("Couldn't determine if file is binary. file=%s; commitId=%s; err=%s", req.filePath, req.commitId, err))
+			analyseFileErrChan <- err
 			break
 		}
-		if isBin {
-			logrus.Debugf("Ignoring binary file. file=%s, commitId=%s", req.filePath, req.commitId)
+		if gitData.Skipped {
 			skippedFiles++
 			continue
 		}
 
-		commitInfo, err := utils.ExecGitCommitInfo(req.repoDir, req.commitId)
+		commitInfo, err := req.git.CommitInfo(req.commitId)
 		if err != nil {
 			analyseFileErrChan <- errors.New(fmt.Sprintf("Couldn't get commit info. commitId=%s; err=%s", req.commitId, err))
 			break
 		}
+		commitInfo = req.identities.ResolveCommitInfo(commitInfo)
 
-		// blame current version of the file
-		fileDstBlame, err := utils.ExecGitBlame(req.repoDir, req.filePath, req.commitId)
-		if err != nil {
-			logrus.Infof("Couldn't git blame cur version of file. Ignoring it. file=%s; commitId=%s", req.filePath, req.commitId)
-			skippedFiles++
-			continue
-		}
+		fileDstBlame := req.identities.ResolveBlameLines(gitData.DstBlame)
 
-		// find the previous commit in which this file was changed
-		prevCommitId, err := utils.ExecPreviousCommitIdForFile(req.repoDir, req.commitId, req.filePath)
-		if err != nil {
-			analyseFileErrChan <- errors.New(fmt.Sprintf("Error on getting prev commit id. err=%s", err))
-			break
+		fileTouchedByCountedAuthor := false
+		// the file was deleted by this commit, so all its lines were deleted.
+		// They are counted only as deleted lines, not as changed lines
+		if gitData.Deleted {
+			for _, srcline := range req.identities.ResolveBlameLines(gitData.SrcBlame) {
+				added := addDeletedLine(&changesFileResult, commitInfo, srcline, req)
+				fileTouchedByCountedAuthor = added || fileTouchedByCountedAuthor
+			}
+			changesFileResult.analysisTime = time.Since(startTime)
+			changesFileResult.authorSkipped = !fileTouchedByCountedAuthor
+			analyseFileOutputChan <- changesFileResult
+			continue
 		}
 
-		fileTouchedByCountedAuthor := false
 		// there is no previous commit because this is a brand new file
-		if prevCommitId == "" {
+		if gitData.PrevCommitId == "" {
 			// consider all lines as "New"
 			for _, dstBlame := range fileDstBlame {
-				added := addAuthorLines(&changesFileResult,
+				added := addCommitAuthorLines(&changesFileResult, commitInfo,
 					dstBlame.AuthorName,
 					dstBlame.AuthorMail,
 					LinesTouched{New: 1},
@@ -95,21 +84,25 @@ func fileAnalysisWorker(fileWorkerInputChan <-chan fileWorkerRequest, analyseFil
 			continue
 		}
 
-		// blame previous version of the file (so we can compare from->to contents)
-		fileSrcBlame, err := utils.ExecGitBlame(req.repoDir, req.filePath, prevCommitId)
-		if err != nil {
-			// analyseFileErrChan <- errors.New(fmt.Sprintf("Error on git blame prev. file=%s. err=%s", req.filePath, err))
-			// break
-			logrus.Infof("Couldn't git blame prev version of file. Ignoring it. file=%s; commitId=%s", req.filePath, prevCommitId)
-			skippedFiles++
-			continue
-		}
+		fileSrcBlame := req.identities.ResolveBlameLines(gitData.SrcBlame)
+		diffs := gitData.Diffs
 
-		// diff both versions of the file
-		// diffs := diffMatcher.DiffMain(filePrevContents, fileCurContents, false)
-		diffs, err := utils.ExecDiffFileRevisions(req.repoDir, req.filePath, prevCommitId, req.commitId)
-		if err != nil {
-			logrus.Debugf("Couldn't diff file revisions. Ignoring file. file=%s; srcCommit=%s; dstCommit=%s; err=%s", req.filePath, prevCommitId, req.commitId, err)
+		// lines that were not touched while the file was renamed or copied
+		if gitData.PrevFilePath != "" {
+			moved := len(fileDstBlame)
+			for _, diff := range diffs {
+				if diff.Operation != utils.OperationDelete {
+					moved -= len(diff.DstLines)
+				}
+			}
+			if moved > 0 {
+				added := addCommitAuthorLines(&changesFileResult, commitInfo,
+					commitInfo.AuthorName,
+					commitInfo.AuthorMail,
+					LinesTouched{Moved: moved},
+					req)
+				fileTouchedByCountedAuthor = added || fileTouchedByCountedAuthor
+			}
 		}
 
 		// for each line, classify change type
@@ -118,7 +111,7 @@ func fileAnalysisWorker(fileWorkerInputChan <-chan fileWorkerRequest, analyseFil
 			// NEW lines
 			if diff.Operation == utils.OperationAdd {
 				// added lines are simply "new"
-				added := addAuthorLines(&changesFileResult,
+				added := addCommitAuthorLines(&changesFileResult, commitInfo,
 					fileDstBlame[diff.DstLines[0].Number-1].AuthorName,
 					fileDstBlame[diff.DstLines[0].Number-1].AuthorMail,
 					LinesTouched{New: len(diff.DstLines)},
@@ -129,8 +122,8 @@ func fileAnalysisWorker(fileWorkerInputChan <-chan fileWorkerRequest, analyseFil
 
 			// CHANGED lines
 			// classify change as
-			//   REFACTOR - when the line changed was more than 21 days old
-			//   CHURN - when the line changed was less than 21 days old
+			//   REFACTOR - when the line changed was older than the churn window (21 days by default)
+			//   CHURN - when the line changed was younger than the churn window
 			for i := 0; i < len(diff.SrcLines); i++ {
 				srcline := fileSrcBlame[i+diff.SrcLines[0].Number-1]
 				// dstline := fileDstBlame[i+diff.DstLines[0].Number-1]
@@ -140,8 +133,6 @@ func fileAnalysisWorker(fileWorkerInputChan <-chan fileWorkerRequest, analyseFil
 				// 	continue
 				// }
 
-				lineAge := commitInfo.Date.Sub(srcline.AuthorDate)
-
 				dstAuthorName := commitInfo.AuthorName
 				dstAuthorMail := commitInfo.AuthorMail
 				if diff.Operation == utils.OperationChange {
@@ -149,88 +140,22 @@ func fileAnalysisWorker(fileWorkerInputChan <-chan fileWorkerRequest, analyseFil
 					dstAuthorMail = fileDstBlame[diff.DstLines[0].Number-1].AuthorMail
 				}
 
-				// REFACTOR - changes to "old" lines
-				if lineAge > 504*time.Hour {
-
-					// REFACTORED it's own line
-					if srcline.AuthorName == commitInfo.AuthorName {
-						added := addAuthorLines(&changesFileResult,
-							dstAuthorName,
-							dstAuthorMail,
-							LinesTouched{
-								Changes:     1,
-								RefactorOwn: 1,
-								AgeDaysSum:  lineAge.Hours() / float64(24),
-							},
-							req)
-						fileTouchedByCountedAuthor = added || fileTouchedByCountedAuthor
-						continue
-					}
-
-					// REFACTORED someone else's line
-					added := addAuthorLines(&changesFileResult,
-						dstAuthorName,
-						dstAuthorMail,
-						LinesTouched{
-							Changes:       1,
-							RefactorOther: 1,
-							AgeDaysSum:    lineAge.Hours() / float64(24),
-						},
-						req)
-					fileTouchedByCountedAuthor = added || fileTouchedByCountedAuthor
-
-					// if someone changed your line, you receive a "refactor received" count
-					addAuthorLines(&changesFileResult,
-						srcline.AuthorName,
-						srcline.AuthorMail,
-						LinesTouched{RefactorReceived: 1},
-						req)
-
-					continue
-				}
-
-				// CHURN - changes to "young" lines
-
-				// churn by the same author
-				if srcline.AuthorName == commitInfo.AuthorName {
-					added := addAuthorLines(&changesFileResult,
-						dstAuthorName,
-						dstAuthorMail,
-						LinesTouched{
-							Changes:    1,
-							ChurnOwn:   1,
-							AgeDaysSum: lineAge.Hours() / float64(24),
-						},
-						req)
+				// deleted lines are counted apart from changed lines
+				if diff.Operation == utils.OperationDelete {
+					added := addDeletedLine(&changesFileResult, commitInfo, srcline, req)
 					fileTouchedByCountedAuthor = added || fileTouchedByCountedAuthor
 					continue
 				}
 
-				// churn by a different author
-				added := addAuthorLines(&changesFileResult,
-					dstAuthorName,
-					dstAuthorMail,
-					LinesTouched{
-						Changes:    1,
-						ChurnOther: 1,
-						AgeDaysSum: lineAge.Hours() / float64(24),
-					},
-					req)
+				added := addChangedLine(&changesFileResult, commitInfo, srcline, dstAuthorName, dstAuthorMail, req)
 				fileTouchedByCountedAuthor = added || fileTouchedByCountedAuthor
-
-				// if someone changed your line, you receive a "churn received" count
-				addAuthorLines(&changesFileResult,
-					srcline.AuthorName,
-					srcline.AuthorMail,
-					LinesTouched{ChurnReceived: 1},
-					req)
 			}
 
 			// special case when changes led to additional lines in destination
 			if len(diff.DstLines) > len(diff.SrcLines) {
 				for i := len(diff.SrcLines); i < len(diff.DstLines); i++ {
 					dstline := fileDstBlame[i+diff.DstLines[0].Number-1]
-					added := addAuthorLines(&changesFileResult,
+					added := addCommitAuthorLines(&changesFileResult, commitInfo,
 						dstline.AuthorName,
 						dstline.AuthorMail,
 						LinesTouched{New: 1},
@@ -253,7 +178,390 @@ func fileAnalysisWorker(fileWorkerInputChan <-chan fileWorkerRequest, analyseFil
 	}
 }
 
+// fileGitDataCacheVersion must be changed when fileChangesGitData or the way it's calculated changes
+var fileGitDataCacheVersion = "changes-gitdata-4"
+
+// fileChangesGitData raw git information needed to analyse the changes
+// of a file in a commit, stored in the files cache
+type fileChangesGitData struct {
+	// Skipped the file is not analysed because it's too big, binary or couldn't be blamed
+	Skipped bool
+	// Deleted the file was deleted by the commit. SrcBlame has the lines that were deleted
+	Deleted bool
+	// PrevCommitId previous commit in which the file was changed. Empty for new files
+	PrevCommitId string
+	// PrevFilePath path of the file in PrevCommitId when it was renamed or copied by the commit
+	PrevFilePath string
+	// DstBlame blame of the file in the analysed commit
+	DstBlame []utils.BlameLine
+	// SrcBlame blame of the file in the previous commit
+	SrcBlame []utils.BlameLine
+	// Diffs between the previous and the analysed version of the file
+	Diffs []utils.DiffEntry
+}
+
+// fileGitData gets the git information of the file or gets it from the files cache.
+// The identities of the authors are not resolved, so the cached results can be
+// reused with other identities files
+func fileGitData(req fileWorkerRequest) (fileChangesGitData, error) {
+	cachedValue, err := req.fileCache.GetFileValue(req.commitId, req.filePath, fileGitDataCacheVersion+":"+req.git.CacheVersion())
+	if err != nil {
+		logrus.Debugf("Couldn't get file git data from cache. file=%s; err=%s", req.filePath, err)
+	}
+	if cachedValue != nil {
+		cached := fileChangesGitData{}
+		err = json.Unmarshal([]byte(*cachedValue), &cached)
+		if err == nil {
+			return cached, nil
+		}
+		logrus.Debugf("Ignoring invalid file git data in cache. file=%s; err=%s", req.filePath, err)
+	}
+
+	result, err := execFileGitData(req)
+	if err != nil {
+		return fileChangesGitData{}, err
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		return fileChangesGitData{}, err
+	}
+	err = req.fileCache.PutFileValue(req.commitId, req.filePath, fileGitDataCacheVersion+":"+req.git.CacheVersion(), string(b))
+	if err != nil {
+		logrus.Warnf("Couldn't save file git data to cache. file=%s; err=%s", req.filePath, err)
+	}
+	return result, nil
+}
+
+func execFileGitData(req fileWorkerRequest) (fileChangesGitData, error) {
+	_, err := req.git.TreeFileSize(req.commitId, req.filePath)
+	if err != nil {
+		// can't get file size when the file was deleted by commit, so it's not present anymore
+		return execDeletedFileGitData(req)
+	}
+	skip, err := skipFile(req, req.commitId)
+	if err != nil || skip {
+		return fileChangesGitData{Skipped: skip}, err
+	}
+
+	// blame current version of the file
+	dstBlame, err := req.git.Blame(req.filePath, req.commitId)
+	if err != nil {
+		logrus.Infof("Couldn't git blame cur version of file. Ignoring it. file=%s; commitId=%s", req.filePath, req.commitId)
+		return fileChangesGitData{Skipped: true}, nil
+	}
+
+	// renamed files are compared to the file in its previous path
+	srcFilePath := req.filePath
+	if req.prevFilePath != "" {
+		srcFilePath = req.prevFilePath
+	}
+
+	// find the previous commit in which this file was changed
+	prevCommitId, err := previousCommitIdForFile(req, srcFilePath)
+	if err != nil {
+		return fileChangesGitData{}, fmt.Errorf("Error on getting prev commit id. err=%s", err)
+	}
+	result := fileChangesGitData{
+		PrevCommitId: prevCommitId,
+		PrevFilePath: req.prevFilePath,
+		DstBlame:     dstBlame,
+	}
+	if prevCommitId == "" {
+		return result, nil
+	}
+
+	// blame previous version of the file (so we can compare from->to contents)
+	result.SrcBlame, err = req.git.Blame(srcFilePath, prevCommitId)
+	if err != nil {
+		logrus.Infof("Couldn't git blame prev version of file. Ignoring it. file=%s; commitId=%s", srcFilePath, prevCommitId)
+		return fileChangesGitData{Skipped: true}, nil
+	}
+
+	// diff both versions of the file
+	if req.prevFilePath != "" {
+		result.Diffs, err = req.git.DiffMovedFileRevisions(srcFilePath, prevCommitId, req.filePath, req.commitId)
+	} else {
+		result.Diffs, err = req.git.DiffFileRevisions(req.filePath, prevCommitId, req.commitId)
+	}
+	if err != nil {
+		logrus.Debugf("Couldn't diff file revisions. Ignoring file. file=%s; srcCommit=%s; dstCommit=%s; err=%s", req.filePath, prevCommitId, req.commitId, err)
+	}
+	return result, nil
+}
+
+// previousCommitIdForFile previous commit in which the file was changed. Merge commits are
+// compared to their parent instead, so the previous commit is empty if the file isn't in the parent
+func previousCommitIdForFile(req fileWorkerRequest, filePath string) (string, error) {
+	if req.parentCommitId == "" {
+		return req.git.PreviousCommitIdForFile(req.commitId, filePath)
+	}
+	_, err := req.git.TreeFileSize(req.parentCommitId, filePath)
+	if err != nil {
+		return "", nil
+	}
+	return req.parentCommitId, nil
+}
+
+// execDeletedFileGitData blames the last version of a file deleted by the commit
+func execDeletedFileGitData(req fileWorkerRequest) (fileChangesGitData, error) {
+	prevCommitId, err := previousCommitIdForFile(req, req.filePath)
+	if err != nil {
+		return fileChangesGitData{}, fmt.Errorf("Error on getting prev commit id. err=%s", err)
+	}
+	if prevCommitId == "" {
+		return fileChangesGitData{Skipped: true}, nil
+	}
+	skip, err := skipFile(req, prevCommitId)
+	if err != nil || skip {
+		return fileChangesGitData{Skipped: skip}, err
+	}
+	srcBlame, err := req.git.Blame(req.filePath, prevCommitId)
+	if err != nil {
+		logrus.Infof("Couldn't git blame prev version of deleted file. Ignoring it. file=%s; commitId=%s", req.filePath, prevCommitId)
+		return fileChangesGitData{Skipped: true}, nil
+	}
+	return fileChangesGitData{
+		Deleted:      true,
+		PrevCommitId: prevCommitId,
+		SrcBlame:     srcBlame,
+	}, nil
+}
+
+// skipFile checks if the file is too big or binary in a commit
+func skipFile(req fileWorkerRequest, commitId string) (bool, error) {
+	fsize, err := req.git.TreeFileSize(commitId, req.filePath)
+	if err != nil {
+		return true, nil
+	}
+	if fsize > 80000 {
+		logrus.Debugf("Ignoring file because it's too big. file=%s, size=%d", req.filePath, fsize)
+		return true, nil
+	}
+
+	isBin, err := req.git.IsBinary(commitId, req.filePath)
+	if err != nil {
+		return false, fmt.Errorf("Couldn't determine if file is binary. file=%s; commitId=%s; err=%s", req.filePath, commitId, err)
+	}
+	if isBin {
+		logrus.Debugf("Ignoring binary file. file=%s, commitId=%s", req.filePath, commitId)
+		return true, nil
+	}
+	return false, nil
+}
+
+// addChangedLine classifies a changed line as churn or refactor by its age
+// and counts it for the author of the change (dst) and for the previous owner of the line (src).
+// Deleted lines are counted by addDeletedLine instead
+func addChangedLine(changesFileResult *ChangesFileResult, commitInfo utils.CommitInfo, srcline utils.BlameLine, dstAuthorName string, dstAuthorMail string, req fileWorkerRequest) bool {
+	lineAge := commitInfo.Date.Sub(srcline.AuthorDate)
+	ageBuckets := lineAgeBuckets(req, lineAge)
+
+	churnWindow := req.churnWindow
+	if churnWindow == 0 {
+		churnWindow = DefaultChurnWindowDays * 24 * time.Hour
+	}
+
+	// REFACTOR - changes to "old" lines
+	if lineAge > churnWindow {
+
+		// REFACTORED it's own line
+		if srcline.AuthorName == commitInfo.AuthorName {
+			return addCommitAuthorLines(changesFileResult, commitInfo,
+				dstAuthorName,
+				dstAuthorMail,
+				LinesTouched{
+					Changes:     1,
+					RefactorOwn: 1,
+					AgeDaysSum:  lineAge.Hours() / float64(24),
+					AgeBuckets:  ageBuckets,
+				},
+				req)
+		}
+
+		// REFACTORED someone else's line
+		added := addCommitAuthorLines(changesFileResult, commitInfo,
+			dstAuthorName,
+			dstAuthorMail,
+			LinesTouched{
+				Changes:       1,
+				RefactorOther: 1,
+				AgeDaysSum:    lineAge.Hours() / float64(24),
+				AgeBuckets:    ageBuckets,
+			},
+			req)
+
+		// if someone changed your line, you receive a "refactor received" count
+		addAuthorLines(changesFileResult,
+			srcline.AuthorName,
+			srcline.AuthorMail,
+			LinesTouched{RefactorReceived: 1},
+			req)
+		addCollaboration(changesFileResult, dstAuthorName, dstAuthorMail, srcline, AuthorCollaboration{Refactor: 1}, req)
+		return added
+	}
+
+	// CHURN - changes to "young" lines
+
+	// churn by the same author
+	if srcline.AuthorName == commitInfo.AuthorName {
+		return addCommitAuthorLines(changesFileResult, commitInfo,
+			dstAuthorName,
+			dstAuthorMail,
+			LinesTouched{
+				Changes:    1,
+				ChurnOwn:   1,
+				AgeDaysSum: lineAge.Hours() / float64(24),
+				AgeBuckets: ageBuckets,
+			},
+			req)
+	}
+
+	// churn by a different author
+	added := addCommitAuthorLines(changesFileResult, commitInfo,
+		dstAuthorName,
+		dstAuthorMail,
+		LinesTouched{
+			Changes:    1,
+			ChurnOther: 1,
+			AgeDaysSum: lineAge.Hours() / float64(24),
+			AgeBuckets: ageBuckets,
+		},
+		req)
+
+	// if someone changed your line, you receive a "churn received" count
+	addAuthorLines(changesFileResult,
+		srcline.AuthorName,
+		srcline.AuthorMail,
+		LinesTouched{ChurnReceived: 1},
+		req)
+	addCollaboration(changesFileResult, dstAuthorName, dstAuthorMail, srcline, AuthorCollaboration{Churn: 1}, req)
+	return added
+}
+
+// lineAgeBuckets counter of changed lines per age bucket with the bucket of the line incremented.
+// nil if age buckets weren't defined
+func lineAgeBuckets(req fileWorkerRequest, lineAge time.Duration) []int {
+	if len(req.ageBuckets) == 0 {
+		return nil
+	}
+	buckets := make([]int, len(req.ageBuckets)+1)
+	for i, limit := range req.ageBuckets {
+		if lineAge < time.Duration(limit)*24*time.Hour {
+			buckets[i] = 1
+			return buckets
+		}
+	}
+	buckets[len(req.ageBuckets)] = 1
+	return buckets
+}
+
+// addDeletedLine counts a line deleted by the author of the commit. If the line
+// was owned by another person, it receives a "deleted received" count
+func addDeletedLine(changesFileResult *ChangesFileResult, commitInfo utils.CommitInfo, srcline utils.BlameLine, req fileWorkerRequest) bool {
+	added := addCommitAuthorLines(changesFileResult, commitInfo,
+		commitInfo.AuthorName,
+		commitInfo.AuthorMail,
+		LinesTouched{Deleted: 1},
+		req)
+	if srcline.AuthorName != commitInfo.AuthorName {
+		addAuthorLines(changesFileResult,
+			srcline.AuthorName,
+			srcline.AuthorMail,
+			LinesTouched{DeletedReceived: 1},
+			req)
+		addCollaboration(changesFileResult, commitInfo.AuthorName, commitInfo.AuthorMail, srcline, AuthorCollaboration{Deleted: 1}, req)
+	}
+	return added
+}
+
+// addCollaboration counts a line owned by srcline author that was changed or deleted by another author
+func addCollaboration(changesFileResult *ChangesFileResult, authorName string, authorMail string, srcline utils.BlameLine, lines AuthorCollaboration, req fileWorkerRequest) {
+	if authorName == srcline.AuthorName || !authorCounted(req, authorName, authorMail) || !authorCounted(req, srcline.AuthorName, srcline.AuthorMail) {
+		return
+	}
+	key := fmt.Sprintf("%s###%s>>>%s###%s", authorName, authorMail, srcline.AuthorName, srcline.AuthorMail)
+	lines.AuthorName = authorName
+	lines.AuthorMail = authorMail
+	lines.OwnerName = srcline.AuthorName
+	lines.OwnerMail = srcline.AuthorMail
+	changesFileResult.collaborationsMap[key] = sumCollaborations(changesFileResult.collaborationsMap[key], lines)
+}
+
+// addCommitAuthorLines counts lines touched by the author of a commit. When the commit has
+// co-authors, the lines are split between them or fully credited to each one, depending on req.coAuthors
+func addCommitAuthorLines(changesFileResult *ChangesFileResult, commitInfo utils.CommitInfo, authorName string, authorMail string, linesChanges LinesTouched, req fileWorkerRequest) bool {
+	if req.coAuthors == CoAuthorsIgnore || len(commitInfo.CoAuthors) == 0 || authorName != commitInfo.AuthorName {
+		return addAuthorLines(changesFileResult, authorName, authorMail, linesChanges, req)
+	}
+	authors := commitAuthors(commitInfo)
+	linesChanges.CoAuthored = linesChanges.New + linesChanges.Changes
+
+	if req.coAuthors == CoAuthorsFull {
+		added := false
+		for _, author := range authors {
+			added = creditAuthorLines(changesFileResult, author.Name, author.Mail, linesChanges, req) || added
+		}
+		// the lines were touched only once
+		if added {
+			changesFileResult.TotalLinesTouched = SumLinesTouched(changesFileResult.TotalLinesTouched, linesChanges)
+		}
+		return added
+	}
+
+	// each line is credited to the next author of the commit
+	added := false
+	for _, lines := range splitLinesTouched(linesChanges) {
+		author := authors[changesFileResult.coAuthorTurn%len(authors)]
+		changesFileResult.coAuthorTurn++
+		added = addAuthorLines(changesFileResult, author.Name, author.Mail, lines, req) || added
+	}
+	return added
+}
+
+// commitAuthors author of the commit followed by its co-authors
+func commitAuthors(commitInfo utils.CommitInfo) []utils.CoAuthor {
+	authors := []utils.CoAuthor{{Name: commitInfo.AuthorName, Mail: commitInfo.AuthorMail}}
+	for _, coAuthor := range commitInfo.CoAuthors {
+		if coAuthor.Name == commitInfo.AuthorName {
+			continue
+		}
+		authors = append(authors, coAuthor)
+	}
+	return authors
+}
+
+// splitLinesTouched breaks a batch of new or moved lines into single lines.
+// Other changes are always counted line by line
+func splitLinesTouched(linesChanges LinesTouched) []LinesTouched {
+	line := linesChanges
+	count := 1
+	if linesChanges.New > 1 {
+		line = LinesTouched{New: 1, CoAuthored: 1}
+		count = linesChanges.New
+	} else if linesChanges.Moved > 1 {
+		line = LinesTouched{Moved: 1}
+		count = linesChanges.Moved
+	}
+	results := make([]LinesTouched, count)
+	for i := range results {
+		results[i] = line
+	}
+	return results
+}
+
 func addAuthorLines(changesFileResult *ChangesFileResult, authorName string, authorMail string, linesChanges LinesTouched, req fileWorkerRequest) bool {
+	if !creditAuthorLines(changesFileResult, authorName, authorMail, linesChanges, req) {
+		return false
+	}
+
+	// add to overall totals
+	changesFileResult.TotalLinesTouched = SumLinesTouched(changesFileResult.TotalLinesTouched, linesChanges)
+	return true
+}
+
+// creditAuthorLines counts lines for an author without adding them to the overall totals
+func creditAuthorLines(changesFileResult *ChangesFileResult, authorName string, authorMail string, linesChanges LinesTouched, req fileWorkerRequest) bool {
 	if !authorCounted(req, authorName, authorMail) {
 		return false
 	}
@@ -275,9 +583,6 @@ func addAuthorLines(changesFileResult *ChangesFileResult, authorName string, aut
 	authorLine.filesTouchedMap[req.filePath] = fileChanges
 
 	changesFileResult.authorLinesMap[authorKey] = authorLine
-
-	// add to overall totals
-	changesFileResult.TotalLinesTouched = SumLinesTouched(changesFileResult.TotalLinesTouched, linesChanges)
 	return true
 }
 
@@ -2,6 +2,7 @@ package changes
 
 import (
 	"testing"
+	"time"
 
 	"github.com/flaviostutz/gitwho/utils"
 	"github.com/sirupsen/logrus"
@@ -17,6 +18,11 @@ func TestAnalyseWorkerFile1(t *testing.T) {
 		return
 	}
 
+	git, err := utils.NewGitBackend(utils.GitBackendExec, repoDir, utils.GitOptions{})
+	require.Nil(t, err)
+
+	defer git.Close()
+
 	analyseFileInputChan := make(chan fileWorkerRequest, 4)
 	analyseFileOutputChan := make(chan ChangesFileResult, 4)
 
@@ -27,10 +33,10 @@ func TestAnalyseWorkerFile1(t *testing.T) {
 	// fmt.Printf(">>> %s\n", strings.Join(utils.CommitInfoToCommitIds(commits), "\n"))
 
 	// submit commit1:file1 for analysis
-	analyseFileInputChan <- fileWorkerRequest{repoDir: repoDir, commitId: commits[4].CommitId, filePath: "file1"}
-	analyseFileInputChan <- fileWorkerRequest{repoDir: repoDir, commitId: commits[3].CommitId, filePath: "file1"}
-	analyseFileInputChan <- fileWorkerRequest{repoDir: repoDir, commitId: commits[2].CommitId, filePath: "file1"}
-	analyseFileInputChan <- fileWorkerRequest{repoDir: repoDir, commitId: commits[1].CommitId, filePath: "file1"}
+	analyseFileInputChan <- fileWorkerRequest{repoDir: repoDir, git: git, commitId: commits[4].CommitId, filePath: "file1"}
+	analyseFileInputChan <- fileWorkerRequest{repoDir: repoDir, git: git, commitId: commits[3].CommitId, filePath: "file1"}
+	analyseFileInputChan <- fileWorkerRequest{repoDir: repoDir, git: git, commitId: commits[2].CommitId, filePath: "file1"}
+	analyseFileInputChan <- fileWorkerRequest{repoDir: repoDir, git: git, commitId: commits[1].CommitId, filePath: "file1"}
 	close(analyseFileInputChan)
 
 	// execute analysis
@@ -99,17 +105,109 @@ func TestAnalyseWorkerFile1(t *testing.T) {
 	// a1
 	changes4 := <-analyseFileOutputChan
 	require.Equal(t, "file1", changes4.FilePath)
+	// the deleted line is counted only as deleted
 	require.Equal(t, 0, changes4.TotalLinesTouched.New)
-	require.Equal(t, 1, changes4.TotalLinesTouched.Changes)
-	require.Equal(t, 1, changes4.TotalLinesTouched.ChurnOwn)
+	require.Equal(t, 0, changes4.TotalLinesTouched.Changes)
+	require.Equal(t, 0, changes4.TotalLinesTouched.ChurnOwn)
 	require.Equal(t, 0, changes4.TotalLinesTouched.ChurnOther)
+	require.Equal(t, 1, changes4.TotalLinesTouched.Deleted)
+	require.Equal(t, 0, changes4.TotalLinesTouched.DeletedReceived)
 	authorLines4, ok := changes4.authorLinesMap["author1###<author1@mail.com>"]
 	require.True(t, ok)
-	require.Equal(t, 1, authorLines4.LinesTouched.ChurnOwn)
-	require.Equal(t, 1, authorLines4.LinesTouched.Changes)
-	require.Equal(t, 0, authorLines4.LinesTouched.ChurnOther)
+	require.Equal(t, 0, authorLines4.LinesTouched.ChurnOwn)
+	require.Equal(t, 0, authorLines4.LinesTouched.Changes)
+	require.Equal(t, 1, authorLines4.LinesTouched.Deleted)
 	require.Equal(t, 1, len(authorLines4.filesTouchedMap))
 	author1FilesMap, ok = authorLines4.filesTouchedMap["file1"]
 	require.True(t, ok)
-	require.Equal(t, 1, author1FilesMap.Lines)
+	require.Equal(t, 0, author1FilesMap.Lines)
+}
+
+func TestAnalyseWorkerDeletedLines(t *testing.T) {
+	repoDir, err := utils.ResolveTestDeletedFilesRepo()
+	require.Nil(t, err)
+
+	git, err := utils.NewGitBackend(utils.GitBackendExec, repoDir, utils.GitOptions{})
+	require.Nil(t, err)
+
+	defer git.Close()
+
+	analyseFileInputChan := make(chan fileWorkerRequest, 2)
+	analyseFileOutputChan := make(chan ChangesFileResult, 2)
+
+	commits, err := utils.ExecGetCommitsInDateRange(repoDir, "main", "1 month ago", "now")
+	require.Nil(t, err)
+	require.Equal(t, 2, len(commits))
+
+	// commit2 deletes a line of file1 and the whole file2
+	analyseFileInputChan <- fileWorkerRequest{repoDir: repoDir, git: git, commitId: commits[0].CommitId, filePath: "file1"}
+	analyseFileInputChan <- fileWorkerRequest{repoDir: repoDir, git: git, commitId: commits[0].CommitId, filePath: "file2"}
+	close(analyseFileInputChan)
+
+	errChan := make(chan error, 2)
+	fileAnalysisWorker(analyseFileInputChan, analyseFileOutputChan, errChan, nil)
+	require.Equal(t, 0, len(errChan))
+
+	// deleted line
+	changes1 := <-analyseFileOutputChan
+	require.Equal(t, "file1", changes1.FilePath)
+	require.Equal(t, 0, changes1.TotalLinesTouched.Changes)
+	require.Equal(t, 1, changes1.TotalLinesTouched.Deleted)
+	require.Equal(t, 1, changes1.TotalLinesTouched.DeletedReceived)
+	require.Equal(t, 0, changes1.TotalLinesTouched.ChurnReceived)
+	authorLines2, ok := changes1.authorLinesMap["author2###<author2@mail.com>"]
+	require.True(t, ok)
+	require.Equal(t, 1, authorLines2.LinesTouched.Deleted)
+	require.Equal(t, 0, authorLines2.LinesTouched.Changes)
+	require.Equal(t, 0, authorLines2.LinesTouched.ChurnOther)
+	authorLines1, ok := changes1.authorLinesMap["author1###<author1@mail.com>"]
+	require.True(t, ok)
+	require.Equal(t, 1, authorLines1.LinesTouched.DeletedReceived)
+	require.Equal(t, 0, authorLines1.LinesTouched.ChurnReceived)
+	require.Equal(t, 0, authorLines1.LinesTouched.Deleted)
+
+	// deleted file
+	changes2 := <-analyseFileOutputChan
+	require.Equal(t, "file2", changes2.FilePath)
+	require.Equal(t, 0, changes2.TotalLinesTouched.New)
+	require.Equal(t, 0, changes2.TotalLinesTouched.Changes)
+	require.Equal(t, 3, changes2.TotalLinesTouched.Deleted)
+	require.Equal(t, 3, changes2.TotalLinesTouched.DeletedReceived)
+	authorLines2, ok = changes2.authorLinesMap["author2###<author2@mail.com>"]
+	require.True(t, ok)
+	require.Equal(t, 3, authorLines2.LinesTouched.Deleted)
+	require.Equal(t, 0, authorLines2.LinesTouched.Changes)
+	require.Equal(t, 0, authorLines2.LinesTouched.ChurnOther)
+	require.Equal(t, 0, authorLines2.filesTouchedMap["file2"].Lines)
+	authorLines1, ok = changes2.authorLinesMap["author1###<author1@mail.com>"]
+	require.True(t, ok)
+	require.Equal(t, 3, authorLines1.LinesTouched.DeletedReceived)
+	require.Equal(t, 0, authorLines1.LinesTouched.ChurnReceived)
+}
+
+func TestAnalyseWorkerChurnWindow(t *testing.T) {
+	now := time.Now()
+	commitInfo := utils.CommitInfo{AuthorName: "author1", AuthorMail: "<author1@mail.com>", Date: now}
+	srcline := utils.BlameLine{AuthorName: "author1", AuthorMail: "<author1@mail.com>", AuthorDate: now.Add(-2 * time.Hour)}
+	newResult := func() ChangesFileResult {
+		return ChangesFileResult{ChangesResult: ChangesResult{
+			authorLinesMap:    make(map[string]AuthorLines, 0),
+			collaborationsMap: make(map[string]AuthorCollaboration, 0),
+		}}
+	}
+
+	// a line changed 2 hours after it was written is churn with the default window
+	changes := newResult()
+	addChangedLine(&changes, commitInfo, srcline, "author1", "<author1@mail.com>", fileWorkerRequest{filePath: "file1"})
+	require.Equal(t, 1, changes.TotalLinesTouched.Changes)
+	require.Equal(t, 1, changes.TotalLinesTouched.ChurnOwn)
+	require.Equal(t, 0, changes.TotalLinesTouched.RefactorOwn)
+
+	// and a refactor with a smaller window
+	changes = newResult()
+	addChangedLine(&changes, commitInfo, srcline, "author1", "<author1@mail.com>", fileWorkerRequest{filePath: "file1", churnWindow: time.Hour, ageBuckets: []int{1}})
+	require.Equal(t, 1, changes.TotalLinesTouched.Changes)
+	require.Equal(t, 1, changes.TotalLinesTouched.RefactorOwn)
+	require.Equal(t, 0, changes.TotalLinesTouched.ChurnOwn)
+	require.Equal(t, []int{1, 0}, changes.TotalLinesTouched.AgeBuckets)
 }
@@ -3,6 +3,8 @@ package changes
 import (
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/muesli/clusters"
@@ -198,6 +200,37 @@ func ClusterizeAuthors(changesResults []ChangesResult, numberOfClusters int) ([]
 	return authorLinesCluster, nil
 }
 
+// ParseAgeBuckets parses comma separated upper limits of age buckets in days. Eg.: "1,7,21,90"
+func ParseAgeBuckets(ageBuckets string) ([]int, error) {
+	limits := make([]int, 0)
+	if strings.TrimSpace(ageBuckets) == "" {
+		return limits, nil
+	}
+	for _, limitStr := range strings.Split(ageBuckets, ",") {
+		limit, err := strconv.Atoi(strings.TrimSpace(limitStr))
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("Invalid age bucket %s. Use positive number of days separated by comma", limitStr)
+		}
+		if len(limits) > 0 && limit <= limits[len(limits)-1] {
+			return nil, fmt.Errorf("Age buckets must be in ascending order. ageBuckets=%s", ageBuckets)
+		}
+		limits = append(limits, limit)
+	}
+	return limits, nil
+}
+
+// AgeBucketNames names of the age buckets. There is an additional bucket for lines older than the last limit
+func AgeBucketNames(limits []int) []string {
+	if len(limits) == 0 {
+		return nil
+	}
+	names := make([]string, 0)
+	for _, limit := range limits {
+		names = append(names, fmt.Sprintf("<%dd", limit))
+	}
+	return append(names, fmt.Sprintf(">=%dd", limits[len(limits)-1]))
+}
+
 func SumLinesTouched(changes1 LinesTouched, changes2 LinesTouched) LinesTouched {
 	changes1.Changes += changes2.Changes
 	changes1.ChurnOther += changes2.ChurnOther
@@ -207,6 +240,97 @@ func SumLinesTouched(changes1 LinesTouched, changes2 LinesTouched) LinesTouched
 	changes1.RefactorOther += changes2.RefactorOther
 	changes1.RefactorOwn += changes2.RefactorOwn
 	changes1.RefactorReceived += changes2.RefactorReceived
+	changes1.Deleted += changes2.Deleted
+	changes1.DeletedReceived += changes2.DeletedReceived
+	changes1.Moved += changes2.Moved
+	changes1.CoAuthored += changes2.CoAuthored
+	if len(changes2.AgeBuckets) > 0 {
+		// a new slice is created so results being summed don't share it
+		size := len(changes1.AgeBuckets)
+		if len(changes2.AgeBuckets) > size {
+			size = len(changes2.AgeBuckets)
+		}
+		ageBuckets := make([]int, size)
+		copy(ageBuckets, changes1.AgeBuckets)
+		for i, lines := range changes2.AgeBuckets {
+			ageBuckets[i] += lines
+		}
+		changes1.AgeBuckets = ageBuckets
+	}
 	changes1.AgeDaysSum += changes2.AgeDaysSum
 	return changes1
 }
+
+// CommitsPerWeekday number of commits per day of the week, starting on Sunday, in the timezone of each commit
+func CommitsPerWeekday(commits []CommitStats) []int {
+	results := make([]int, 7)
+	for _, commit := range commits {
+		results[commit.Date.Weekday()]++
+	}
+	return results
+}
+
+// CommitsPerHour number of commits per hour of the day in the timezone of each commit
+func CommitsPerHour(commits []CommitStats) []int {
+	results := make([]int, 24)
+	for _, commit := range commits {
+		results[commit.Date.Hour()]++
+	}
+	return results
+}
+
+// LargestCommits commits with most lines touched (new+changes), largest first
+func LargestCommits(commits []CommitStats, count int) []CommitStats {
+	results := make([]CommitStats, len(commits))
+	copy(results, commits)
+	sort.SliceStable(results, func(i, j int) bool {
+		li := results[i].LinesTouched
+		lj := results[j].LinesTouched
+		return li.New+li.Changes > lj.New+lj.Changes
+	})
+	if len(results) > count {
+		results = results[:count]
+	}
+	return results
+}
+
+// CollaborationMatrix directed author-to-author matrix of the lines changed by other authors.
+// matrix[i][j] has the lines of authors[j] changed or deleted by authors[i]. Authors are sorted
+// by the lines they changed plus the lines they had changed, limited to maxAuthors if greater than zero
+func CollaborationMatrix(collaborations []AuthorCollaboration, maxAuthors int) ([]string, [][]int) {
+	totals := make(map[string]int, 0)
+	for _, collaboration := range collaborations {
+		lines := collaboration.Refactor + collaboration.Churn + collaboration.Deleted
+		totals[collaboration.AuthorName] += lines
+		totals[collaboration.OwnerName] += lines
+	}
+	authors := make([]string, 0, len(totals))
+	for author := range totals {
+		authors = append(authors, author)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if totals[authors[i]] != totals[authors[j]] {
+			return totals[authors[i]] > totals[authors[j]]
+		}
+		return authors[i] < authors[j]
+	})
+	if maxAuthors > 0 && len(authors) > maxAuthors {
+		authors = authors[:maxAuthors]
+	}
+
+	index := make(map[string]int, len(authors))
+	matrix := make([][]int, len(authors))
+	for i, author := range authors {
+		index[author] = i
+		matrix[i] = make([]int, len(authors))
+	}
+	for _, collaboration := range collaborations {
+		i, ok1 := index[collaboration.AuthorName]
+		j, ok2 := index[collaboration.OwnerName]
+		if !ok1 || !ok2 {
+			continue
+		}
+		matrix[i][j] += collaboration.Refactor + collaboration.Churn + collaboration.Deleted
+	}
+	return authors, matrix
+}
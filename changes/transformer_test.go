@@ -2,6 +2,7 @@ package changes
 
 import (
 	"testing"
+	"time"
 
 	"github.com/flaviostutz/gitwho/utils"
 	"github.com/stretchr/testify/require"
@@ -32,3 +33,70 @@ func TestClusterizeAuthors(t *testing.T) {
 	require.Equal(t, "author3", authorClusters[0].AuthorLines[0].AuthorName)
 	require.NotEqual(t, authorClusters[1].AuthorLines[0].AuthorName, authorClusters[2].AuthorLines[0].AuthorName)
 }
+
+func TestParseAgeBuckets(t *testing.T) {
+	limits, err := ParseAgeBuckets("1, 7,21,90")
+	require.Nil(t, err)
+	require.Equal(t, []int{1, 7, 21, 90}, limits)
+	require.Equal(t, []string{"<1d", "<7d", "<21d", "<90d", ">=90d"}, AgeBucketNames(limits))
+
+	limits, err = ParseAgeBuckets("")
+	require.Nil(t, err)
+	require.Empty(t, limits)
+	require.Nil(t, AgeBucketNames(limits))
+
+	_, err = ParseAgeBuckets("7,1")
+	require.NotNil(t, err)
+	_, err = ParseAgeBuckets("1,a")
+	require.NotNil(t, err)
+}
+
+func TestSumLinesTouchedAgeBuckets(t *testing.T) {
+	lines1 := LinesTouched{Changes: 1, AgeBuckets: []int{1, 0}}
+	lines2 := LinesTouched{Changes: 2, AgeBuckets: []int{1, 1}}
+	sum := SumLinesTouched(lines1, lines2)
+	require.Equal(t, 3, sum.Changes)
+	require.Equal(t, []int{2, 1}, sum.AgeBuckets)
+	// summed values must not change the original ones
+	require.Equal(t, []int{1, 0}, lines1.AgeBuckets)
+
+	sum = SumLinesTouched(LinesTouched{}, lines2)
+	require.Equal(t, []int{1, 1}, sum.AgeBuckets)
+}
+
+func TestCommitStats(t *testing.T) {
+	// sunday 10h and monday 10h and 15h
+	sunday := time.Date(2023, 8, 13, 10, 0, 0, 0, time.UTC)
+	commits := []CommitStats{
+		{CommitId: "c1", Date: sunday, LinesTouched: LinesTouched{New: 2}},
+		{CommitId: "c2", Date: sunday.Add(24 * time.Hour), LinesTouched: LinesTouched{New: 5, Changes: 5}},
+		{CommitId: "c3", Date: sunday.Add(29 * time.Hour), LinesTouched: LinesTouched{Changes: 3}},
+	}
+	require.Equal(t, []int{1, 2, 0, 0, 0, 0, 0}, CommitsPerWeekday(commits))
+	hours := CommitsPerHour(commits)
+	require.Equal(t, 2, hours[10])
+	require.Equal(t, 1, hours[15])
+
+	largest := LargestCommits(commits, 2)
+	require.Equal(t, 2, len(largest))
+	require.Equal(t, "c2", largest[0].CommitId)
+	require.Equal(t, "c3", largest[1].CommitId)
+	// the original order is kept
+	require.Equal(t, "c1", commits[0].CommitId)
+}
+
+func TestCollaborationMatrix(t *testing.T) {
+	collaborations := []AuthorCollaboration{
+		{AuthorName: "a", OwnerName: "b", Refactor: 3, Churn: 2},
+		{AuthorName: "b", OwnerName: "a", Deleted: 1},
+		{AuthorName: "c", OwnerName: "a", Churn: 1},
+	}
+
+	authors, matrix := CollaborationMatrix(collaborations, 0)
+	require.Equal(t, []string{"a", "b", "c"}, authors)
+	require.Equal(t, [][]int{{0, 5, 0}, {1, 0, 0}, {1, 0, 0}}, matrix)
+
+	authors, matrix = CollaborationMatrix(collaborations, 2)
+	require.Equal(t, []string{"a", "b"}, authors)
+	require.Equal(t, [][]int{{0, 5}, {1, 0}}, matrix)
+}
@@ -11,31 +11,39 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func RunChangesTimeseries(osArgs []string) {
-	opts := changes.ChangesTimeseriesOptions{}
-	cliOpts := cli.CliOpts{}
-
+// changesTimeseriesFlags defines the flags of the changes-timeseries command
+func changesTimeseriesFlags(opts *changes.ChangesTimeseriesOptions, cliOpts *cli.CliOpts) *flag.FlagSet {
 	flags := flag.NewFlagSet("changes-timeseries", flag.ExitOnError)
-	flags.StringVar(&opts.RepoDir, "repo", ".", "Repository path to analyse")
-	flags.StringVar(&opts.Branch, "branch", "main", "Branch name to analyse")
-	flags.StringVar(&opts.FilesRegex, "files", ".*", "Regex for selecting which file paths to include in analysis")
-	flags.StringVar(&opts.FilesNotRegex, "files-not", "", "Regex for filtering out files from analysis")
-	flags.StringVar(&opts.AuthorsRegex, "authors", ".*", "Regex for selecting which authors to include in analysis")
-	flags.StringVar(&opts.AuthorsNotRegex, "authors-not", "", "Regex for filtering out authors from analysis")
-	flags.StringVar(&opts.CacheFile, "cache-file", "", "If defined, stores results in a cache file that can be used in subsequent calls that uses the same parameters.")
-	flags.IntVar(&opts.CacheTTLSeconds, "cache-ttl", 5184000, "Time in seconds for old items in cache file to be deleted. Defaults to 2 months")
+	cli.AddBaseFlags(flags, &opts.BaseOptions)
+	flags.StringVar(&opts.TeamsFile, "teams", "", "YAML file mapping team names to lists of author regexes (name or email). Results are also aggregated per team")
 	flags.StringVar(&opts.Since, "since", "90 days ago", "Filter changes made from this date")
 	flags.StringVar(&opts.Until, "until", "now", "Filter changes made util this date")
+	flags.IntVar(&opts.ChurnWindowDays, "churn-window", changes.DefaultChurnWindowDays, "Changes to lines younger than this number of days are counted as churn. Older lines are counted as refactor")
+	flags.StringVar(&opts.AgeBuckets, "age-buckets", "", "Comma separated upper limits, in days, for counting changed lines by age (histogram). Eg.: '1,7,21,90'")
+	flags.StringVar(&opts.Merges, "merges", changes.MergesSkip, "How merge commits are handled. 'skip' (merge commits are ignored), 'first-parent' (only the first parent history is analysed and merges are compared to their first parent) or 'include' (all commits, with merges compared to their first parent in files changed while merging)")
+	flags.StringVar(&opts.CoAuthors, "co-authors", changes.CoAuthorsSplit, "How lines of commits with 'Co-authored-by' trailers are credited. 'split' (lines are split between the author and the co-authors), 'full' (all lines are credited to each of them) or 'ignore' (only the commit author is credited)")
 	flags.StringVar(&opts.Period, "period", "30 days ago", "Show changes data each [period] in the range [since]-[until]. Eg.: '7 days', '1 month'")
-	flags.StringVar(&cliOpts.Format, "format", "full", "Output format. 'full' (more details), 'short' (lines per author) or 'graph' (open browser)")
+	flags.StringVar(&cliOpts.Format, "format", "full", "Output format. 'full' (more details), 'short' (lines per author), 'graph' (open browser) or 'json' (JSON document)")
 	flags.StringVar(&cliOpts.GoProfileFile, "profile-file", "", "Profile file to dump golang runtime data to")
 	flags.BoolVar(&cliOpts.Verbose, "verbose", false, "Show verbose logs during processing")
+	return flags
+}
+
+func RunChangesTimeseries(osArgs []string) {
+	opts := changes.ChangesTimeseriesOptions{}
+	cliOpts := cli.CliOpts{}
 
-	flags.Parse(osArgs[2:])
-	progressChan := cli.SetupBasic(cliOpts)
+	flags := changesTimeseriesFlags(&opts, &cliOpts)
+	err := cli.ParseFlags(flags, osArgs[2:], &opts.BaseOptions)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	progressChan := cli.SetupBasic(cliOpts, opts.BaseOptions)
 	defer close(progressChan)
 
-	_, err := utils.ExecGetCommitsInDateRange(opts.RepoDir, opts.Branch, "", "")
+	_, err = cli.LatestCommit(opts.BaseOptions, "")
 	if err != nil {
 		fmt.Printf("Branch %s not found\n", opts.Branch)
 		os.Exit(1)
@@ -76,5 +84,13 @@ func RunChangesTimeseries(osArgs []string) {
 		}
 		fmt.Printf("\nServing graph at %s\n", url)
 		select {}
+
+	case "json":
+		output, err := FormatTimeseriesChangesResultsJSON(changesResults, opts)
+		if err != nil {
+			fmt.Printf("Couldn't format results as JSON. err=%s\n", err)
+			os.Exit(4)
+		}
+		fmt.Println(output)
 	}
 }
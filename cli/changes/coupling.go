@@ -0,0 +1,85 @@
+package changes
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/flaviostutz/gitwho/changes"
+	"github.com/flaviostutz/gitwho/cli"
+	"github.com/sirupsen/logrus"
+)
+
+// couplingFlags defines the flags of the coupling command
+func couplingFlags(opts *changes.CouplingOptions, cliOpts *cli.CliOpts, top *int) *flag.FlagSet {
+	flags := flag.NewFlagSet("coupling", flag.ExitOnError)
+	cli.AddBaseFlags(flags, &opts.BaseOptions)
+	flags.StringVar(&opts.SinceDate, "since", "6 months ago", "Consider commits made from this date")
+	flags.StringVar(&opts.UntilDate, "until", "now", "Consider commits made until this date")
+	flags.StringVar(&opts.Merges, "merges", changes.MergesSkip, "How merge commits are handled. 'skip' (merge commits are ignored), 'first-parent' (only the first parent history is analysed and merges are compared to their first parent) or 'include' (all commits, with merges compared to their first parent in files changed while merging)")
+	flags.IntVar(&opts.MinRevisions, "min-revs", 5, "Files changed in less commits than this are ignored")
+	flags.IntVar(&opts.MinSharedRevisions, "min-shared-revs", 3, "Pairs of files changed together in less commits than this are ignored")
+	flags.Float64Var(&opts.MinDegree, "min-degree", 30, "Min degree of coupling, in percent, for a pair of files to be shown. Degree is the number of commits that changed both files over the average number of commits of each file")
+	flags.IntVar(&opts.MaxChangesetSize, "max-changeset", 30, "Commits that changed more files than this (large refactorings, formatting etc) are ignored. Use 0 for unlimited")
+	flags.IntVar(&opts.ModuleDepth, "module-depth", 0, "If greater than zero, files are grouped by their first [module-depth] dirs and the coupling between these modules is shown instead of files")
+	flags.IntVar(top, "top", 30, "Number of pairs shown in 'full' and 'short' outputs. Use 0 to show all")
+	flags.StringVar(&cliOpts.Format, "format", "full", "Output format. 'full' (pairs and groups of coupled files), 'short' (pairs only), 'csv' (CSV format) or 'json' (JSON document)")
+	flags.StringVar(&cliOpts.GoProfileFile, "profile-file", "", "Profile file to dump golang runtime data to")
+	flags.BoolVar(&cliOpts.Verbose, "verbose", false, "Show verbose logs during processing")
+	return flags
+}
+
+func RunCoupling(osArgs []string) {
+	opts := changes.CouplingOptions{}
+	cliOpts := cli.CliOpts{}
+	top := 0
+	flags := couplingFlags(&opts, &cliOpts, &top)
+	err := cli.ParseFlags(flags, osArgs[2:], &opts.BaseOptions)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	progressChan := cli.SetupBasic(cliOpts, opts.BaseOptions)
+	defer close(progressChan)
+
+	_, err = cli.LatestCommit(opts.BaseOptions, "")
+	if err != nil {
+		fmt.Printf("Branch %s not found\n", opts.Branch)
+		os.Exit(1)
+	}
+
+	logrus.Debugf("Starting analysis of temporal coupling")
+	result, err := changes.AnalyseCoupling(opts, progressChan)
+	if err != nil {
+		fmt.Println("Failed to perform coupling analysis. err=", err)
+		os.Exit(2)
+	}
+
+	switch cliOpts.Format {
+	case "full":
+		fmt.Println(FormatCouplingResults(result, top, true))
+
+	case "short":
+		fmt.Println(FormatCouplingResults(result, top, false))
+
+	case "graph":
+		fmt.Printf("format 'graph' is not supported\n")
+		os.Exit(3)
+
+	case "csv":
+		output, err := FormatCouplingResultsCSV(result)
+		if err != nil {
+			fmt.Printf("Couldn't format results as CSV. err=%s", err)
+		}
+		fmt.Println(output)
+
+	case "json":
+		output, err := FormatCouplingResultsJSON(result, opts)
+		if err != nil {
+			fmt.Printf("Couldn't format results as JSON. err=%s\n", err)
+			os.Exit(4)
+		}
+		fmt.Println(output)
+	}
+}
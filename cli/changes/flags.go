@@ -0,0 +1,21 @@
+package changes
+
+import (
+	"flag"
+
+	"github.com/flaviostutz/gitwho/changes"
+	"github.com/flaviostutz/gitwho/cli"
+)
+
+// CommandFlags creates the flags of each changes command, indexed by command name
+var CommandFlags = map[string]func() *flag.FlagSet{
+	"changes": func() *flag.FlagSet {
+		return changesFlags(&changes.ChangesOptions{}, &cli.CliOpts{})
+	},
+	"changes-timeseries": func() *flag.FlagSet {
+		return changesTimeseriesFlags(&changes.ChangesTimeseriesOptions{}, &cli.CliOpts{})
+	},
+	"coupling": func() *flag.FlagSet {
+		return couplingFlags(&changes.CouplingOptions{}, &cli.CliOpts{}, new(int))
+	},
+}
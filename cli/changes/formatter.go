@@ -1,11 +1,15 @@
 package changes
 
 import (
+	"bytes"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/flaviostutz/gitwho/changes"
+	"github.com/flaviostutz/gitwho/cli"
 	"github.com/flaviostutz/gitwho/utils"
+	"github.com/rodaine/table"
 )
 
 func FormatFullTextResults(cresult changes.ChangesResult) (string, error) {
@@ -19,6 +23,9 @@ func FormatFullTextResults(cresult changes.ChangesResult) (string, error) {
 		text += fmt.Sprintf("Average line age when changed: %d days\n", (int(cresult.TotalLinesTouched.AgeDaysSum / float64(cresult.TotalLinesTouched.Changes))))
 	}
 	text += formatLinesTouched(cresult.TotalLinesTouched, changes.LinesTouched{})
+	text += formatAgeBuckets(cresult.TotalLinesTouched, cresult.AgeBucketNames)
+	text += formatCommits(cresult.Commits)
+	text += formatCollaborations(cresult.Collaborations)
 
 	// author clusters
 	cstr, err := formatAuthorClusters(cresult)
@@ -29,14 +36,24 @@ func FormatFullTextResults(cresult changes.ChangesResult) (string, error) {
 	text += "\n" + cstr
 
 	for _, authorLines := range cresult.AuthorsLines {
-		if authorLines.LinesTouched.New+authorLines.LinesTouched.Changes == 0 {
+		if authorLines.LinesTouched.New+authorLines.LinesTouched.Changes+authorLines.LinesTouched.Deleted == 0 {
 			continue
 		}
 		mailStr := fmt.Sprintf(" %s", authorLines.AuthorMail)
 		text += fmt.Sprintf("\nAuthor: %s%s\n", authorLines.AuthorName, mailStr)
+		if authorLines.Commits > 0 {
+			text += fmt.Sprintf("- Commits: %d%s\n", authorLines.Commits, utils.CalcPercStr(authorLines.Commits, cresult.TotalCommits))
+		}
 		text += formatLinesTouched(authorLines.LinesTouched, cresult.TotalLinesTouched)
+		text += formatAgeBuckets(authorLines.LinesTouched, cresult.AgeBucketNames)
 		text += formatTopTouchedFiles(authorLines.FilesTouched)
 	}
+
+	for _, teamLines := range cresult.TeamsLines {
+		text += fmt.Sprintf("\nTeam: %s\n", teamLines.TeamName)
+		text += fmt.Sprintf("- Authors: %s\n", utils.JoinWithLimit(teamLines.AuthorNames, ", ", 10))
+		text += formatLinesTouched(teamLines.LinesTouched, cresult.TotalLinesTouched)
+	}
 	return text, nil
 }
 
@@ -113,9 +130,34 @@ func FormatTopTextResults(cresult changes.ChangesResult) (string, error) {
 		text += fmt.Sprintf("  %s: %d%s\n", al.AuthorName, al.LinesTouched.ChurnOwn+al.LinesTouched.ChurnReceived, utils.CalcPercStr(al.LinesTouched.ChurnOwn+al.LinesTouched.ChurnReceived, cresult.TotalLinesTouched.ChurnOwn+cresult.TotalLinesTouched.ChurnReceived))
 	}
 
+	// top deleters
+	sort.Slice(cresult.AuthorsLines, func(i, j int) bool {
+		ai := cresult.AuthorsLines[i].LinesTouched
+		aj := cresult.AuthorsLines[j].LinesTouched
+		return ai.Deleted > aj.Deleted
+	})
+	text += "\nTop Deleters\n"
+	for i := 0; i < len(cresult.AuthorsLines) && i < 3; i++ {
+		al := cresult.AuthorsLines[i]
+		text += fmt.Sprintf("  %s: %d%s\n", al.AuthorName, al.LinesTouched.Deleted, utils.CalcPercStr(al.LinesTouched.Deleted, cresult.TotalLinesTouched.Deleted))
+	}
+
+	// teams
+	if len(cresult.TeamsLines) > 0 {
+		text += "\nTeams (new+changes)\n"
+		for _, tl := range cresult.TeamsLines {
+			text += fmt.Sprintf("  %s: %d%s\n", tl.TeamName, tl.LinesTouched.New+tl.LinesTouched.Changes, utils.CalcPercStr(tl.LinesTouched.New+tl.LinesTouched.Changes, cresult.TotalLinesTouched.New+cresult.TotalLinesTouched.Changes))
+		}
+	}
+
 	return text, nil
 }
 
+// FormatChangesResultsJSON formats changes results as a versioned JSON document
+func FormatChangesResultsJSON(cresult changes.ChangesResult, opts changes.ChangesOptions) (string, error) {
+	return cli.FormatJSON("changes", opts, cresult)
+}
+
 func formatAuthorClusters(cresult changes.ChangesResult) (string, error) {
 	aclusters, err := changes.ClusterizeAuthors([]changes.ChangesResult{cresult}, 3)
 	if err != nil {
@@ -137,6 +179,96 @@ func formatAuthorClusters(cresult changes.ChangesResult) (string, error) {
 	return text, nil
 }
 
+func formatAgeBuckets(changes changes.LinesTouched, ageBucketNames []string) string {
+	if len(ageBucketNames) == 0 {
+		return ""
+	}
+	text := "  - Age of changed lines:\n"
+	for i, bucketName := range ageBucketNames {
+		lines := 0
+		if i < len(changes.AgeBuckets) {
+			lines = changes.AgeBuckets[i]
+		}
+		text += fmt.Sprintf("    - %s: %d%s\n", bucketName, lines, utils.CalcPercStr(lines, changes.Changes))
+	}
+	return text
+}
+
+func formatCommits(commits []changes.CommitStats) string {
+	if len(commits) == 0 {
+		return ""
+	}
+	lines := 0
+	files := 0
+	for _, commit := range commits {
+		lines += commit.LinesTouched.New + commit.LinesTouched.Changes
+		files += commit.TotalFiles
+	}
+	text := fmt.Sprintf("\nCommits: %d\n", len(commits))
+	text += fmt.Sprintf("- Average lines touched per commit: %d\n", lines/len(commits))
+	text += fmt.Sprintf("- Average files touched per commit: %d\n", files/len(commits))
+	text += "- Largest commits:\n"
+	for _, commit := range changes.LargestCommits(commits, 5) {
+		text += fmt.Sprintf("  - %s %s %s: %d lines in %d files\n", shortCommitId(commit.CommitId), commit.Date.Format(time.DateOnly), commit.AuthorName, commit.LinesTouched.New+commit.LinesTouched.Changes, commit.TotalFiles)
+	}
+
+	weekdays := changes.CommitsPerWeekday(commits)
+	text += "- Commits per weekday:"
+	for i, count := range weekdays {
+		text += fmt.Sprintf(" %s:%d", time.Weekday(i).String()[:3], count)
+	}
+	text += "\n"
+
+	text += "- Commits per hour:"
+	for hour, count := range changes.CommitsPerHour(commits) {
+		if count == 0 {
+			continue
+		}
+		text += fmt.Sprintf(" %02dh:%d", hour, count)
+	}
+	text += "\n"
+	return text
+}
+
+// formatCollaborations shows a matrix with the lines of each author (columns)
+// that were changed or deleted by other authors (rows)
+func formatCollaborations(collaborations []changes.AuthorCollaboration) string {
+	if len(collaborations) == 0 {
+		return ""
+	}
+	authors, matrix := changes.CollaborationMatrix(collaborations, 10)
+
+	header := []interface{}{"#", "Changed by \\ Owner"}
+	for i := range authors {
+		header = append(header, fmt.Sprintf("%d", i+1))
+	}
+	tblWriter := bytes.NewBufferString("")
+	tbl := table.New(header...)
+	tbl.WithWriter(tblWriter)
+	for i, author := range authors {
+		row := []interface{}{fmt.Sprintf("%d", i+1), author}
+		for j, lines := range matrix[i] {
+			if i == j {
+				row = append(row, "-")
+				continue
+			}
+			row = append(row, fmt.Sprintf("%d", lines))
+		}
+		tbl.AddRow(row...)
+	}
+	tbl.Print()
+
+	text := "\nCollaboration of the top authors (lines of the owner changed or deleted by another author):\n"
+	return text + tblWriter.String()
+}
+
+func shortCommitId(commitId string) string {
+	if len(commitId) > 7 {
+		return commitId[:7]
+	}
+	return commitId
+}
+
 func formatTopTouchedFiles(filesTouched []changes.FileTouched) string {
 	text := fmt.Sprintf("  - Top files:\n")
 	sort.Slice(filesTouched, func(i, j int) bool {
@@ -165,5 +297,13 @@ func formatLinesTouched(changes changes.LinesTouched, totals changes.LinesTouche
 	text += fmt.Sprintf("      - Churn of own lines: %d%s\n", changes.ChurnOwn, utils.CalcPercStr(changes.ChurnOwn, changes.ChurnOwn+changes.ChurnOther))
 	text += fmt.Sprintf("      - Churn of other's lines (help given): %d%s\n", changes.ChurnOther, utils.CalcPercStr(changes.ChurnOther, changes.ChurnOwn+changes.ChurnOther))
 	text += fmt.Sprintf("      * Churn done by others to own lines (help received): %d\n", changes.ChurnReceived)
+	text += fmt.Sprintf("  * Lines deleted: %d\n", changes.Deleted)
+	text += fmt.Sprintf("    * Own lines deleted by others: %d\n", changes.DeletedReceived)
+	if changes.Moved > 0 {
+		text += fmt.Sprintf("  * Lines moved by renaming or copying files: %d\n", changes.Moved)
+	}
+	if changes.CoAuthored > 0 {
+		text += fmt.Sprintf("  * Lines touched in commits with co-authors: %d%s\n", changes.CoAuthored, utils.CalcPercStr(changes.CoAuthored, totalTouched))
+	}
 	return text
 }
@@ -0,0 +1,81 @@
+package changes
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+
+	"github.com/flaviostutz/gitwho/changes"
+	"github.com/flaviostutz/gitwho/cli"
+	"github.com/flaviostutz/gitwho/utils"
+)
+
+// FormatCouplingResults lists the top pairs of coupled files and, if full, the groups of coupled files
+func FormatCouplingResults(result changes.CouplingResult, top int, full bool) string {
+	text := fmt.Sprintf("\nCommits analysed: %d\n", result.TotalCommits)
+	text += fmt.Sprintf("Coupled pairs: %d\n", len(result.Pairs))
+
+	pairs := result.Pairs
+	if top > 0 && len(pairs) > top {
+		pairs = pairs[:top]
+	}
+	for _, pair := range pairs {
+		text += fmt.Sprintf("  %s <-> %s: degree %.0f%% (%d shared commits)\n", pair.File1, pair.File2, pair.Degree, pair.SharedRevisions)
+		if full {
+			text += fmt.Sprintf("    - Support: %.1f%%; Confidence: %.0f%% (%d commits) -> %.0f%% (%d commits)\n", pair.Support, pair.Confidence1, pair.Revisions1, pair.Confidence2, pair.Revisions2)
+		}
+	}
+	if len(pairs) < len(result.Pairs) {
+		text += fmt.Sprintf("  ...%d more\n", len(result.Pairs)-len(pairs))
+	}
+
+	if !full {
+		return text
+	}
+	text += fmt.Sprintf("\nCoupled groups: %d\n", len(result.Groups))
+	for _, group := range result.Groups {
+		text += fmt.Sprintf("  - %s\n", utils.JoinWithLimit(group.Files, ", ", 10))
+	}
+	return text
+}
+
+// FormatCouplingResultsCSV formats the pairs of coupled files as CSV
+func FormatCouplingResultsCSV(result changes.CouplingResult) (string, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Comma = ';'
+
+	header := []string{"File1", "File2", "Revisions1", "Revisions2", "SharedRevisions", "Support", "Confidence1", "Confidence2", "Degree"}
+	if err := writer.Write(header); err != nil {
+		return "", fmt.Errorf("failed to write CSV header: %v", err)
+	}
+	for _, pair := range result.Pairs {
+		row := []string{
+			pair.File1,
+			pair.File2,
+			strconv.Itoa(pair.Revisions1),
+			strconv.Itoa(pair.Revisions2),
+			strconv.Itoa(pair.SharedRevisions),
+			fmt.Sprintf("%.2f", pair.Support),
+			fmt.Sprintf("%.2f", pair.Confidence1),
+			fmt.Sprintf("%.2f", pair.Confidence2),
+			fmt.Sprintf("%.2f", pair.Degree),
+		}
+		if err := writer.Write(row); err != nil {
+			return "", fmt.Errorf("failed to write CSV row: %v", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", fmt.Errorf("error flushing CSV writer: %v", err)
+	}
+
+	return buf.String(), nil
+}
+
+// FormatCouplingResultsJSON formats coupling results as a versioned JSON document
+func FormatCouplingResultsJSON(result changes.CouplingResult, opts changes.CouplingOptions) (string, error) {
+	return cli.FormatJSON("coupling", opts, result)
+}
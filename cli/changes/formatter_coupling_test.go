@@ -0,0 +1,34 @@
+package changes
+
+import (
+	"testing"
+
+	"github.com/flaviostutz/gitwho/changes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatCoupling(t *testing.T) {
+	result := changes.CouplingResult{
+		TotalCommits: 10,
+		Pairs: []changes.FileCoupling{
+			{File1: "a", File2: "b", Revisions1: 4, Revisions2: 3, SharedRevisions: 3, Support: 30, Confidence1: 75, Confidence2: 100, Degree: 85.7},
+			{File1: "c", File2: "d", Revisions1: 2, Revisions2: 2, SharedRevisions: 2, Support: 20, Confidence1: 100, Confidence2: 100, Degree: 100},
+		},
+		Groups: []changes.CouplingGroup{{Files: []string{"a", "b"}}, {Files: []string{"c", "d"}}},
+	}
+
+	out := FormatCouplingResults(result, 1, false)
+	require.Equal(t, "\nCommits analysed: 10\nCoupled pairs: 2\n  a <-> b: degree 86% (3 shared commits)\n  ...1 more\n", out)
+
+	out = FormatCouplingResults(result, 0, true)
+	require.Contains(t, out, "  a <-> b: degree 86% (3 shared commits)\n    - Support: 30.0%; Confidence: 75% (4 commits) -> 100% (3 commits)\n")
+	require.Contains(t, out, "\nCoupled groups: 2\n  - a, b\n  - c, d\n")
+
+	out, err := FormatCouplingResultsCSV(result)
+	require.Nil(t, err)
+	require.Contains(t, out, "File1;File2;Revisions1;Revisions2;SharedRevisions;Support;Confidence1;Confidence2;Degree\na;b;4;3;3;30.00;75.00;100.00;85.70\n")
+
+	out, err = FormatCouplingResultsJSON(result, changes.CouplingOptions{})
+	require.Nil(t, err)
+	require.Contains(t, out, "\"command\": \"coupling\"")
+}
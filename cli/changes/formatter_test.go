@@ -39,6 +39,61 @@ func TestFormatChangesFull(t *testing.T) {
 
 	out, err := FormatFullTextResults(results)
 	require.Nil(t, err)
-	require.Contains(t, out, "Total authors active: 3\nTotal files touched: 2\nAverage line age when changed: 0 days\n- Total lines touched: 11\n  - New lines: 8 (72%)\n  - Changed lines: 3 (27%)\n    - Refactor: 0 (0%)")
+	require.Contains(t, out, "Total authors active: 3\nTotal files touched: 2\nAverage line age when changed: 0 days\n- Total lines touched: 10\n  - New lines: 8 (80%)\n  - Changed lines: 2 (20%)\n    - Refactor: 0 (0%)")
+	require.Contains(t, out, "  * Lines deleted: 1\n    * Own lines deleted by others: 0\n")
+	require.Contains(t, out, "\nCommits: 5\n- Average lines touched per commit: 2\n- Average files touched per commit: 1\n- Largest commits:\n")
+	require.Contains(t, out, "author3: 5 lines in 1 files\n")
+	require.Contains(t, out, "- Commits per weekday: Sun:")
 
 }
+
+func TestFormatChangesJSON(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+	opts := changes.ChangesOptions{
+		BaseOptions: utils.BaseOptions{
+			RepoDir: repoDir,
+			Branch:  "main",
+		},
+		SinceDate: "1 day ago",
+	}
+	results, err := changes.AnalyseChanges(opts, nil)
+	require.Nil(t, err)
+
+	out, err := FormatChangesResultsJSON(results, opts)
+	require.Nil(t, err)
+	require.Contains(t, out, "\"schema_version\": 1")
+	require.Contains(t, out, "\"command\": \"changes\"")
+	require.Contains(t, out, "\"since_date\": \"1 day ago\"")
+	require.Contains(t, out, "\"total_commits\": 5")
+	require.Contains(t, out, "\"author_name\": \"author3\"")
+}
+
+func TestFormatChangesCoAuthors(t *testing.T) {
+	repoDir, err := utils.ResolveTestCoAuthorsRepo()
+	require.Nil(t, err)
+	results, err := changes.AnalyseChanges(changes.ChangesOptions{
+		BaseOptions: utils.BaseOptions{
+			RepoDir: repoDir,
+			Branch:  "main",
+		},
+	}, nil)
+	require.Nil(t, err)
+
+	out, err := FormatFullTextResults(results)
+	require.Nil(t, err)
+	require.Contains(t, out, "  * Lines touched in commits with co-authors: 7 (100%)\n")
+	require.Contains(t, out, "author3 <author3@mail.com>\n- Commits: 1 (50%)\n- Total lines touched: 1 (14%)")
+}
+
+func TestFormatChangesCollaborations(t *testing.T) {
+	out := formatCollaborations([]changes.AuthorCollaboration{
+		{AuthorName: "author1", OwnerName: "author2", Refactor: 3, Churn: 1},
+		{AuthorName: "author2", OwnerName: "author1", Deleted: 2},
+	})
+	require.Contains(t, out, "\nCollaboration of the top authors (lines of the owner changed or deleted by another author):\n")
+	require.Regexp(t, `1\s+author1\s+-\s+4\s*\n`, out)
+	require.Regexp(t, `2\s+author2\s+2\s+-\s*\n`, out)
+
+	require.Equal(t, "", formatCollaborations([]changes.AuthorCollaboration{}))
+}
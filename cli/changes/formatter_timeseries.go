@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/flaviostutz/gitwho/changes"
+	"github.com/flaviostutz/gitwho/cli"
 	"github.com/flaviostutz/gitwho/utils"
 	"github.com/rodaine/table"
 )
@@ -14,7 +15,7 @@ func FormatTimeseriesChangesResults(changesResults []changes.ChangesResult, full
 	str := "\n"
 
 	tblWriter := bytes.NewBufferString("")
-	tbl := table.New("Period", "Commits", "Files touched", "Lines touched", "New lines", "Changed lines")
+	tbl := table.New("Period", "Commits", "Files touched", "Lines touched", "New lines", "Changed lines", "Deleted lines")
 	tbl.WithWriter(tblWriter)
 
 	prevResult := changes.ChangesResult{}
@@ -23,6 +24,7 @@ func FormatTimeseriesChangesResults(changesResults []changes.ChangesResult, full
 	totalFiles := 0
 	totalChanges := 0
 	totalNew := 0
+	totalDeleted := 0
 
 	for _, result := range changesResults {
 		tbl.AddRow(fmt.Sprintf("%s - %s", result.SinceCommit.Date.Format(time.DateOnly), result.UntilCommit.Date.Format(time.DateOnly)),
@@ -31,11 +33,13 @@ func FormatTimeseriesChangesResults(changesResults []changes.ChangesResult, full
 			fmt.Sprintf("%d%s", totalTouched(result.TotalLinesTouched), utils.CalcDiffStr(totalTouched(result.TotalLinesTouched), totalTouched(prevResult.TotalLinesTouched))),
 			fmt.Sprintf("%d%s", result.TotalLinesTouched.New, utils.CalcDiffStr(result.TotalLinesTouched.New, prevResult.TotalLinesTouched.New)),
 			fmt.Sprintf("%d%s", result.TotalLinesTouched.Changes, utils.CalcDiffStr(result.TotalLinesTouched.Changes, prevResult.TotalLinesTouched.Changes)),
+			fmt.Sprintf("%d%s", result.TotalLinesTouched.Deleted, utils.CalcDiffStr(result.TotalLinesTouched.Deleted, prevResult.TotalLinesTouched.Deleted)),
 		)
 		totalCommits += result.TotalCommits
 		totalFiles += result.TotalFiles
 		totalNew += result.TotalLinesTouched.New
 		totalChanges += result.TotalLinesTouched.Changes
+		totalDeleted += result.TotalLinesTouched.Deleted
 		prevResult = result
 	}
 	tbl.AddRow("Total",
@@ -44,6 +48,7 @@ func FormatTimeseriesChangesResults(changesResults []changes.ChangesResult, full
 		fmt.Sprintf("%d", totalNew+totalChanges),
 		fmt.Sprintf("%d", totalNew),
 		fmt.Sprintf("%d", totalChanges),
+		fmt.Sprintf("%d", totalDeleted),
 	)
 	tbl.Print()
 	str += tblWriter.String()
@@ -72,6 +77,11 @@ func FormatTimeseriesChangesResults(changesResults []changes.ChangesResult, full
 	return str, nil
 }
 
+// FormatTimeseriesChangesResultsJSON formats changes timeseries results as a versioned JSON document
+func FormatTimeseriesChangesResultsJSON(changesResults []changes.ChangesResult, opts changes.ChangesTimeseriesOptions) (string, error) {
+	return cli.FormatJSON("changes-timeseries", opts, changesResults)
+}
+
 func formatAuthorsTimeseries(changesResults []changes.ChangesResult) string {
 
 	str := ""
@@ -82,28 +92,32 @@ func formatAuthorsTimeseries(changesResults []changes.ChangesResult) string {
 		str += fmt.Sprintf("\n%s\n", authorNameLinesDate.AuthorName)
 
 		tblWriter := bytes.NewBufferString("")
-		tbl := table.New("Period", "Lines touched", "New lines", "Changed lines")
+		tbl := table.New("Period", "Lines touched", "New lines", "Changed lines", "Deleted lines")
 		tbl.WithWriter(tblWriter)
 
 		prevResult := changes.AuthorLinesDate{}
 
 		totalChanges := 0
 		totalNew := 0
+		totalDeleted := 0
 
 		for _, result := range authorNameLinesDate.AuthorLinesDates {
 			tbl.AddRow(fmt.Sprintf("%s", result.Since),
 				fmt.Sprintf("%d%s", totalTouched(result.AuthorLines.LinesTouched), utils.CalcDiffStr(totalTouched(result.AuthorLines.LinesTouched), totalTouched(prevResult.AuthorLines.LinesTouched))),
 				fmt.Sprintf("%d%s", result.AuthorLines.LinesTouched.New, utils.CalcDiffStr(result.AuthorLines.LinesTouched.New, prevResult.AuthorLines.LinesTouched.New)),
 				fmt.Sprintf("%d%s", result.AuthorLines.LinesTouched.Changes, utils.CalcDiffStr(result.AuthorLines.LinesTouched.Changes, prevResult.AuthorLines.LinesTouched.Changes)),
+				fmt.Sprintf("%d%s", result.AuthorLines.LinesTouched.Deleted, utils.CalcDiffStr(result.AuthorLines.LinesTouched.Deleted, prevResult.AuthorLines.LinesTouched.Deleted)),
 			)
 			totalNew += result.AuthorLines.LinesTouched.New
 			totalChanges += result.AuthorLines.LinesTouched.Changes
+			totalDeleted += result.AuthorLines.LinesTouched.Deleted
 			prevResult = result
 		}
 		tbl.AddRow("Total",
 			fmt.Sprintf("%d", totalNew+totalChanges),
 			fmt.Sprintf("%d", totalNew),
 			fmt.Sprintf("%d", totalChanges),
+			fmt.Sprintf("%d", totalDeleted),
 		)
 		tbl.Print()
 		str += tblWriter.String()
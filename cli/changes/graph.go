@@ -2,6 +2,8 @@ package changes
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/flaviostutz/gitwho/changes"
@@ -134,22 +136,31 @@ func ServeChangesTimeseries(changesResults []changes.ChangesResult, ownershipTim
 	linesTouched.SetXAxis(datesX)
 
 	totalValues := make([]opts.LineData, 0)
+	deletedValues := make([]opts.LineData, 0)
 	for _, date := range datesX {
 		totalValue := 0
+		deletedValue := 0
 		// look for value on this date
 		for _, changesResult := range changesResults {
 			if changesResult.UntilCommit.Date.Format(time.DateOnly) == date {
 				totalValue = changesResult.TotalLinesTouched.New + changesResult.TotalLinesTouched.Changes
+				deletedValue = changesResult.TotalLinesTouched.Deleted
 				break
 			}
 		}
 		totalValues = append(totalValues, opts.LineData{Value: totalValue})
+		deletedValues = append(deletedValues, opts.LineData{Value: deletedValue})
 	}
 	linesTouched.AddSeries("Lines Touched", totalValues,
 		charts.WithLineChartOpts(
 			opts.LineChart{Smooth: false},
 		),
 	)
+	linesTouched.AddSeries("Lines Deleted", deletedValues,
+		charts.WithLineChartOpts(
+			opts.LineChart{Smooth: false},
+		),
+	)
 
 	// LINES TOUCHED PER AUTHOR TIMESERIES
 	lineAuthor := charts.NewLine()
@@ -269,6 +280,45 @@ func ServeChanges(cresult changes.ChangesResult, changesOpts changes.ChangesOpti
 	page := components.NewPage()
 	page.AddCharts(sankey)
 
+	if cresult.TotalLinesTouched.Deleted > 0 {
+		page.AddCharts(deletedPie(cresult))
+	}
+
+	if len(cresult.AgeBucketNames) > 0 {
+		page.AddCharts(ageBucketsBar(cresult))
+	}
+
+	if len(cresult.Collaborations) > 0 {
+		page.AddCharts(collaborationGraph(cresult.Collaborations))
+	}
+
+	if len(cresult.TeamsLines) > 0 {
+		teamItems := make([]opts.PieData, 0)
+		for _, teamLines := range cresult.TeamsLines {
+			teamItems = append(teamItems, opts.PieData{Name: teamLines.TeamName, Value: teamLines.LinesTouched.New + teamLines.LinesTouched.Changes})
+		}
+		teamsPie := charts.NewPie()
+		teamsPie.SetGlobalOptions(
+			charts.WithInitializationOpts(opts.Initialization{Theme: types.ThemeShine}),
+			charts.WithTitleOpts(opts.Title{
+				Title: "Lines Touched per Team",
+			}),
+			charts.WithLegendOpts(opts.Legend{
+				Show: true,
+				Type: "scroll",
+				Top:  "23px",
+			}),
+		)
+		teamsPie.AddSeries("pie", teamItems).
+			SetSeriesOptions(charts.WithLabelOpts(
+				opts.Label{
+					Show:      true,
+					Formatter: "{b}: {c}",
+				}),
+			)
+		page.AddCharts(teamsPie)
+	}
+
 	info := "<pre style=\"display:flex;justify-content:center\"><code>"
 	info += utils.BaseOptsStr(changesOpts.BaseOptions)
 	info += changesOptsStr(changesOpts)
@@ -287,6 +337,10 @@ func ServeChanges(cresult changes.ChangesResult, changesOpts changes.ChangesOpti
 func changesOptsStr(changesOpts changes.ChangesOptions) string {
 	str := utils.AttrStr("since", changesOpts.SinceDate)
 	str += utils.AttrStr("until", changesOpts.UntilDate)
+	str += utils.AttrStr("churn-window", fmt.Sprintf("%d days", churnWindowDays(changesOpts.ChurnWindowDays)))
+	str += utils.AttrStr("age-buckets", changesOpts.AgeBuckets)
+	str += utils.AttrStr("merges", changesOpts.Merges)
+	str += utils.AttrStr("co-authors", changesOpts.CoAuthors)
 	return str
 }
 
@@ -294,5 +348,155 @@ func changesTimeseriesOptsStr(changesTimeseriesOpts changes.ChangesTimeseriesOpt
 	str := utils.AttrStr("since", changesTimeseriesOpts.Since)
 	str += utils.AttrStr("until", changesTimeseriesOpts.Until)
 	str += utils.AttrStr("period", changesTimeseriesOpts.Period)
+	str += utils.AttrStr("churn-window", fmt.Sprintf("%d days", churnWindowDays(changesTimeseriesOpts.ChurnWindowDays)))
+	str += utils.AttrStr("age-buckets", changesTimeseriesOpts.AgeBuckets)
+	str += utils.AttrStr("merges", changesTimeseriesOpts.Merges)
+	str += utils.AttrStr("co-authors", changesTimeseriesOpts.CoAuthors)
 	return str
 }
+
+func churnWindowDays(days int) int {
+	if days == 0 {
+		return changes.DefaultChurnWindowDays
+	}
+	return days
+}
+
+// ageBucketsBar histogram of changed lines per age for the authors with most changes
+// collaborationGraph force layout graph with authors as nodes and a directed link from
+// each author to the authors whose lines they changed, weighted by the number of lines
+func collaborationGraph(collaborations []changes.AuthorCollaboration) *charts.Graph {
+	authors, matrix := changes.CollaborationMatrix(collaborations, 50)
+
+	maxLines := 1
+	for i := range matrix {
+		for j := range matrix[i] {
+			if matrix[i][j] > maxLines {
+				maxLines = matrix[i][j]
+			}
+		}
+	}
+
+	nodes := make([]opts.GraphNode, 0)
+	links := make([]opts.GraphLink, 0)
+	for i, author := range authors {
+		lines := 0
+		for j := range authors {
+			lines += matrix[i][j] + matrix[j][i]
+			if matrix[i][j] > 0 {
+				links = append(links, opts.GraphLink{Source: author, Target: authors[j], Value: float32(matrix[i][j])})
+			}
+		}
+		nodes = append(nodes, opts.GraphNode{Name: author, Value: float32(lines), SymbolSize: 10 + 40*math.Sqrt(float64(lines)/float64(2*maxLines))})
+	}
+
+	graph := charts.NewGraph()
+	graph.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{Theme: types.ThemeShine}),
+		charts.WithTitleOpts(opts.Title{
+			Title:    "Collaboration",
+			Subtitle: "Arrows go from the author to the owner of the lines changed",
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{
+			Trigger: "item",
+			Show:    true,
+		}),
+	)
+	graph.AddSeries("lines changed", nodes, links,
+		charts.WithGraphChartOpts(opts.GraphChart{
+			Layout:             "force",
+			Force:              &opts.GraphForce{Repulsion: 300, EdgeLength: 150},
+			Roam:               true,
+			Draggable:          true,
+			FocusNodeAdjacency: true,
+			EdgeSymbol:         []string{"none", "arrow"},
+		}),
+		charts.WithLabelOpts(opts.Label{
+			Show:     true,
+			Position: "right",
+		}),
+		charts.WithLineStyleOpts(opts.LineStyle{
+			Curveness: 0.2,
+		}),
+	)
+	return graph
+}
+
+// deletedPie breakdown of the deleted lines by owner. Deleted lines are not a part of the lines
+// touched flow because the lines of deleted files are not counted as changed lines
+func deletedPie(cresult changes.ChangesResult) *charts.Pie {
+	deletedOther := cresult.TotalLinesTouched.DeletedReceived
+	deletedOwn := cresult.TotalLinesTouched.Deleted - deletedOther
+	if deletedOwn < 0 {
+		deletedOwn = 0
+	}
+	items := []opts.PieData{
+		{Name: "Own lines", Value: deletedOwn},
+		{Name: "Other's lines", Value: deletedOther},
+	}
+
+	pie := charts.NewPie()
+	pie.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{Theme: types.ThemeShine}),
+		charts.WithTitleOpts(opts.Title{
+			Title:    "Deleted Lines",
+			Subtitle: fmt.Sprintf("%d lines deleted", cresult.TotalLinesTouched.Deleted),
+		}),
+		charts.WithLegendOpts(opts.Legend{
+			Show: true,
+			Top:  "40px",
+		}),
+	)
+	pie.AddSeries("pie", items).
+		SetSeriesOptions(charts.WithLabelOpts(
+			opts.Label{
+				Show:      true,
+				Formatter: "{b}: {c}",
+			}),
+		)
+	return pie
+}
+
+func ageBucketsBar(cresult changes.ChangesResult) *charts.Bar {
+	authorsLines := append([]changes.AuthorLines{}, cresult.AuthorsLines...)
+	sort.Slice(authorsLines, func(i, j int) bool {
+		return authorsLines[i].LinesTouched.Changes > authorsLines[j].LinesTouched.Changes
+	})
+	if len(authorsLines) > 20 {
+		authorsLines = authorsLines[:20]
+	}
+
+	authorsX := make([]string, 0)
+	for _, authorLines := range authorsLines {
+		authorsX = append(authorsX, authorLines.AuthorName)
+	}
+
+	bar := charts.NewBar()
+	bar.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{Theme: types.ThemeShine}),
+		charts.WithTitleOpts(opts.Title{
+			Title: "Age of Changed Lines",
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{
+			Trigger: "axis",
+			Show:    true,
+		}),
+		charts.WithLegendOpts(opts.Legend{
+			Show: true,
+			Top:  "23px",
+		}),
+	)
+	bar.SetXAxis(authorsX)
+	for i, bucketName := range cresult.AgeBucketNames {
+		values := make([]opts.BarData, 0)
+		for _, authorLines := range authorsLines {
+			value := 0
+			if i < len(authorLines.LinesTouched.AgeBuckets) {
+				value = authorLines.LinesTouched.AgeBuckets[i]
+			}
+			values = append(values, opts.BarData{Value: value})
+		}
+		bar.AddSeries(bucketName, values, charts.WithBarChartOpts(opts.BarChart{Stack: "age"}))
+	}
+	return bar
+}
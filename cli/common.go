@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"net/http"
@@ -12,15 +13,33 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// JSONSchemaVersion is the version of the document generated by '--format json'.
+// It must be incremented whenever an attribute is renamed or removed from the output
+const JSONSchemaVersion = 1
+
 type CliOpts struct {
 	Verbose       bool
 	GoProfileFile string
 	Format        string
 }
 
-func SetupBasic(cliOpts CliOpts) chan<- utils.ProgressInfo {
-	if cliOpts.Format != "full" && cliOpts.Format != "short" && cliOpts.Format != "graph" && cliOpts.Format != "csv" {
-		fmt.Println("'--format' should be (full|short|graph)")
+// JSONOutput is the envelope of all documents generated by '--format json'
+type JSONOutput struct {
+	// SchemaVersion is incremented when the structure of the document changes in a non backward compatible way
+	SchemaVersion int `json:"schema_version"`
+	// Command is the gitwho command that generated the results
+	Command string `json:"command"`
+	// Options are the options used for the analysis
+	Options interface{} `json:"options"`
+	// Result is the analysis result. Its structure depends on the command
+	Result interface{} `json:"result"`
+}
+
+// SetupBasic validates the output format, configures logs and profiling and starts showing progress.
+// The git cli is required unless the go-git backend is used
+func SetupBasic(cliOpts CliOpts, baseOpts utils.BaseOptions) chan<- utils.ProgressInfo {
+	if cliOpts.Format != "full" && cliOpts.Format != "short" && cliOpts.Format != "graph" && cliOpts.Format != "csv" && cliOpts.Format != "json" {
+		fmt.Println("'--format' should be (full|short|graph|csv|json)")
 		os.Exit(1)
 	}
 
@@ -29,7 +48,13 @@ func SetupBasic(cliOpts CliOpts) chan<- utils.ProgressInfo {
 		logrus.SetLevel(logrus.DebugLevel)
 	}
 
-	utils.ExecCheckPrereqs()
+	if baseOpts.GitBackend != utils.GitBackendGoGit {
+		err := utils.ExecCheckPrereqs()
+		if err != nil {
+			fmt.Printf("git cli not found. Install git or use '--git-backend %s'. err=%s\n", utils.GitBackendGoGit, err)
+			os.Exit(1)
+		}
+	}
 
 	if cliOpts.GoProfileFile != "" {
 		// Start profiling
@@ -48,6 +73,21 @@ func SetupBasic(cliOpts CliOpts) chan<- utils.ProgressInfo {
 	return progressChan
 }
 
+// LatestCommit newest commit of the branch until a date, read with the git backend of the options.
+// Returns an error if the branch is not found
+func LatestCommit(baseOpts utils.BaseOptions, until string) (*utils.CommitInfo, error) {
+	gitOpts, err := utils.NewGitOptions(baseOpts)
+	if err != nil {
+		return nil, err
+	}
+	git, err := utils.NewGitBackend(baseOpts.GitBackend, baseOpts.RepoDir, gitOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer git.Close()
+	return git.LatestCommit(baseOpts.Branch, "", until)
+}
+
 func ServeGraphPage(page *components.Page, contents string) (string, *http.Server) {
 	port := rand.Intn(20000) + 20000
 	bindURL := fmt.Sprintf(":%d", port)
@@ -65,3 +105,17 @@ func ServeGraphPage(page *components.Page, contents string) (string, *http.Serve
 
 	return fmt.Sprintf("http://localhost%s", bindURL), srv
 }
+
+// FormatJSON renders the results of a command inside the versioned JSON envelope
+func FormatJSON(command string, options interface{}, result interface{}) (string, error) {
+	b, err := json.MarshalIndent(JSONOutput{
+		SchemaVersion: JSONSchemaVersion,
+		Command:       command,
+		Options:       options,
+		Result:        result,
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/flaviostutz/gitwho/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the config file looked up in the repo root when '--config' is not used
+const ConfigFileName = ".gitwho.yaml"
+
+// Config is the contents of a project config file. Keys of Defaults and Commands are flag names
+type Config struct {
+	// Defaults values used by all commands that have the flag
+	Defaults map[string]interface{} `yaml:"defaults"`
+	// Commands values for specific commands, indexed by command name. They override Defaults
+	Commands map[string]map[string]interface{} `yaml:"commands"`
+	// Identities maps multiple names/emails to the same person, as in '--identities' file
+	Identities []utils.Identity `yaml:"identities"`
+	// Excludes authors and files excluded from analysis in all commands
+	Excludes ConfigExcludes `yaml:"excludes"`
+}
+
+// ConfigExcludes lists of regexes and presets merged into '--authors-not', '--files-not' and '--exclude-preset'
+type ConfigExcludes struct {
+	Authors []string `yaml:"authors"`
+	Files   []string `yaml:"files"`
+	Presets []string `yaml:"presets"`
+}
+
+// AddBaseFlags defines the flags of BaseOptions that are common to all commands, and '--config'
+func AddBaseFlags(flags *flag.FlagSet, opts *utils.BaseOptions) {
+	flags.String("config", "", fmt.Sprintf("Config file with default values for flags, identities and excludes. Defaults to %s in repo, if it exists", ConfigFileName))
+	flags.StringVar(&opts.RepoDir, "repo", ".", "Repository path to analyse")
+	flags.StringVar(&opts.Branch, "branch", "main", "Branch name to analyse")
+	flags.StringVar(&opts.FilesRegex, "files", ".*", "Regex for selecting which file paths to include in analysis")
+	flags.StringVar(&opts.FilesNotRegex, "files-not", "", "Regex for filtering out files from analysis")
+	flags.StringVar(&opts.AuthorsRegex, "authors", ".*", "Regex for selecting which authors to include in analysis")
+	flags.StringVar(&opts.AuthorsNotRegex, "authors-not", "", "Regex for filtering out authors from analysis")
+	flags.StringVar(&opts.IdentitiesFile, "identities", "", "YAML file mapping multiple names/emails to the same person. The repo .mailmap is always used")
+	flags.StringVar(&opts.GitBackend, "git-backend", "exec", "How git repo is accessed. 'exec' (runs git cli) or 'go-git' (reads the repo in process without running git)")
+	flags.BoolVar(&opts.IgnoreWhitespace, "ignore-whitespace", false, "Ignore whitespace and blank line only changes when running blame and diffs")
+	flags.StringVar(&opts.IgnoreRevsFile, "ignore-revs-file", "", "File with commit ids to be ignored, one per line. Same format as .git-blame-ignore-revs")
+	flags.StringVar(&opts.ExcludePresets, "exclude-preset", "", "Comma separated presets of authors and files to exclude from analysis. 'bots' (dependabot, renovate etc), 'lockfiles' (package manager lock files), 'vendor' (vendored dependencies and files marked with linguist-vendored in .gitattributes) and 'generated' (generated code and files marked with linguist-generated)")
+	flags.StringVar(&opts.CacheFile, "cache-file", "", "If defined, stores results in a cache file that can be used in subsequent calls that uses the same parameters. Git results per file are also reused by calls with different filters")
+	flags.IntVar(&opts.CacheTTLSeconds, "cache-ttl", 5184000, "Time in seconds for old items in cache file to be deleted. Defaults to 2 months")
+}
+
+// ParseFlags parses the command line arguments and uses the values of the config file
+// for the flags that were not set in the command line. Identities of the config file
+// are added to baseOpts
+func ParseFlags(flags *flag.FlagSet, args []string, baseOpts *utils.BaseOptions) error {
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	config, _, err := LoadFlagsConfig(flags)
+	if err != nil {
+		return err
+	}
+	_, err = ApplyConfig(flags, config, baseOpts)
+	return err
+}
+
+// LoadFlagsConfig loads the config file defined in '--config' or, if not defined,
+// the config file in the root of '--repo'. Returns nil if there is no config file
+func LoadFlagsConfig(flags *flag.FlagSet) (*Config, string, error) {
+	configFile := flagValue(flags, "config")
+	if configFile == "" {
+		repoDir := flagValue(flags, "repo")
+		if repoDir == "" {
+			repoDir = "."
+		}
+		configFile = filepath.Join(repoDir, ConfigFileName)
+		if _, err := os.Stat(configFile); err != nil {
+			return nil, "", nil
+		}
+	}
+	config, err := LoadConfig(configFile)
+	if err != nil {
+		return nil, "", err
+	}
+	return config, configFile, nil
+}
+
+// LoadConfig reads and parses a config file
+func LoadConfig(configFile string) (*Config, error) {
+	contents, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read config file. err=%s", err)
+	}
+	config := Config{}
+	err = yaml.Unmarshal(contents, &config)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't parse config file. file=%s; err=%s", configFile, err)
+	}
+	return &config, nil
+}
+
+// ApplyConfig sets the flags that were not set in the command line with the values
+// of the config. Priority is: command line, command section, defaults, excludes.
+// Returns the names of the flags that were set from the config
+func ApplyConfig(flags *flag.FlagSet, config *Config, baseOpts *utils.BaseOptions) ([]string, error) {
+	if config == nil {
+		return []string{}, nil
+	}
+
+	for name := range config.Commands[flags.Name()] {
+		if flags.Lookup(name) == nil {
+			return nil, fmt.Errorf("Invalid config file. Command %s has no flag '%s'", flags.Name(), name)
+		}
+	}
+
+	values := config.FlagValues(flags.Name())
+	setInArgs := make(map[string]bool, 0)
+	flags.Visit(func(f *flag.Flag) {
+		setInArgs[f.Name] = true
+	})
+
+	applied := make([]string, 0)
+	for name, value := range values {
+		// defaults are shared by commands with different flags
+		if setInArgs[name] || flags.Lookup(name) == nil {
+			continue
+		}
+		err := flags.Set(name, value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid config file value for '%s'. err=%s", name, err)
+		}
+		applied = append(applied, name)
+	}
+	sort.Strings(applied)
+
+	baseOpts.Identities = append(baseOpts.Identities, config.Identities...)
+	return applied, nil
+}
+
+// FlagValues flag values defined in the config for a command. Excludes are
+// merged with the values of the same flags in Defaults and Commands
+func (c *Config) FlagValues(command string) map[string]string {
+	values := make(map[string]string, 0)
+	for name, value := range c.Defaults {
+		values[name] = configValueStr(value)
+	}
+	for name, value := range c.Commands[command] {
+		values[name] = configValueStr(value)
+	}
+	mergeConfigValue(values, "authors-not", c.Excludes.Authors, "|")
+	mergeConfigValue(values, "files-not", c.Excludes.Files, "|")
+	mergeConfigValue(values, "exclude-preset", c.Excludes.Presets, ",")
+	return values
+}
+
+// mergeConfigValue appends items to the value of a flag using sep
+func mergeConfigValue(values map[string]string, name string, items []string, sep string) {
+	if len(items) == 0 {
+		return
+	}
+	if values[name] != "" {
+		items = append([]string{values[name]}, items...)
+	}
+	values[name] = strings.Join(items, sep)
+}
+
+// configValueStr formats a yaml value as a flag value. Lists are comma separated
+func configValueStr(value interface{}) string {
+	if list, ok := value.([]interface{}); ok {
+		items := make([]string, 0, len(list))
+		for _, item := range list {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ",")
+	}
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func flagValue(flags *flag.FlagSet, name string) string {
+	f := flags.Lookup(name)
+	if f == nil {
+		return ""
+	}
+	return f.Value.String()
+}
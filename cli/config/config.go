@@ -0,0 +1,174 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/flaviostutz/gitwho/cli"
+	cliChanges "github.com/flaviostutz/gitwho/cli/changes"
+	cliHotspots "github.com/flaviostutz/gitwho/cli/hotspots"
+	cliOwnership "github.com/flaviostutz/gitwho/cli/ownership"
+	"github.com/flaviostutz/gitwho/utils"
+)
+
+const (
+	sourceDefault = "default"
+	sourceConfig  = "config"
+	sourceFlag    = "flag"
+)
+
+// EffectiveFlag value of a flag after applying the config file and the command line
+type EffectiveFlag struct {
+	Name  string
+	Value string
+	// Source is where the value came from. 'default', 'config' or 'flag'
+	Source string
+}
+
+// EffectiveConfig settings used by a command
+type EffectiveConfig struct {
+	Command    string
+	ConfigFile string
+	Flags      []EffectiveFlag
+	Identities []utils.Identity
+}
+
+func commandFlags() map[string]func() *flag.FlagSet {
+	commands := make(map[string]func() *flag.FlagSet, 0)
+	for name, newFlags := range cliChanges.CommandFlags {
+		commands[name] = newFlags
+	}
+	for name, newFlags := range cliOwnership.CommandFlags {
+		commands[name] = newFlags
+	}
+	for name, newFlags := range cliHotspots.CommandFlags {
+		commands[name] = newFlags
+	}
+	return commands
+}
+
+func commandNames() []string {
+	names := make([]string, 0)
+	for name := range commandFlags() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RunConfig runs 'gitwho config show [command] [flags]', which prints the settings
+// resulting from the config file and the flags for a command (or for all commands)
+func RunConfig(osArgs []string) {
+	usage := fmt.Sprintf("Usage: gitwho config show [%s] [flags]", strings.Join(commandNames(), "|"))
+	if len(osArgs) < 3 || osArgs[2] != "show" {
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+
+	commands := commandNames()
+	args := osArgs[3:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		if commandFlags()[args[0]] == nil {
+			fmt.Println(usage)
+			os.Exit(1)
+		}
+		commands = []string{args[0]}
+		args = args[1:]
+	}
+
+	for i, command := range commands {
+		effective, err := ResolveEffectiveConfig(command, args)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if i == 0 {
+			fmt.Print(FormatConfigFile(effective))
+		}
+		fmt.Print(FormatEffectiveFlags(effective))
+	}
+}
+
+// ResolveEffectiveConfig parses the command line args of a command and applies
+// the config file, keeping track of where each value came from
+func ResolveEffectiveConfig(command string, args []string) (EffectiveConfig, error) {
+	newFlags := commandFlags()[command]
+	if newFlags == nil {
+		return EffectiveConfig{}, fmt.Errorf("Invalid command %s", command)
+	}
+	flags := newFlags()
+	err := flags.Parse(args)
+	if err != nil {
+		return EffectiveConfig{}, err
+	}
+
+	sources := make(map[string]string, 0)
+	flags.Visit(func(f *flag.Flag) {
+		sources[f.Name] = sourceFlag
+	})
+
+	config, configFile, err := cli.LoadFlagsConfig(flags)
+	if err != nil {
+		return EffectiveConfig{}, err
+	}
+	baseOpts := utils.BaseOptions{}
+	applied, err := cli.ApplyConfig(flags, config, &baseOpts)
+	if err != nil {
+		return EffectiveConfig{}, err
+	}
+	for _, name := range applied {
+		sources[name] = sourceConfig
+	}
+
+	effective := EffectiveConfig{
+		Command:    command,
+		ConfigFile: configFile,
+		Flags:      make([]EffectiveFlag, 0),
+		Identities: baseOpts.Identities,
+	}
+	flags.VisitAll(func(f *flag.Flag) {
+		if f.Name == "config" {
+			return
+		}
+		source := sources[f.Name]
+		if source == "" {
+			source = sourceDefault
+		}
+		effective.Flags = append(effective.Flags, EffectiveFlag{Name: f.Name, Value: f.Value.String(), Source: source})
+	})
+	return effective, nil
+}
+
+// FormatConfigFile describes the config file and the identities it defines
+func FormatConfigFile(effective EffectiveConfig) string {
+	if effective.ConfigFile == "" {
+		return "Config file: none\n"
+	}
+	str := fmt.Sprintf("Config file: %s\n", effective.ConfigFile)
+	str += fmt.Sprintf("Identities: %d\n", len(effective.Identities))
+	for _, identity := range effective.Identities {
+		aliases := append(append([]string{}, identity.Names...), identity.Mails...)
+		str += fmt.Sprintf("- %s %s", identity.Name, identity.Mail)
+		if len(aliases) > 0 {
+			str += fmt.Sprintf(" (%s)", strings.Join(aliases, ", "))
+		}
+		str += "\n"
+	}
+	return str
+}
+
+// FormatEffectiveFlags lists the flags of a command with their values and sources
+func FormatEffectiveFlags(effective EffectiveConfig) string {
+	str := fmt.Sprintf("\n%s\n", effective.Command)
+	for _, f := range effective.Flags {
+		str += fmt.Sprintf("  --%s=%s", f.Name, f.Value)
+		if f.Source != sourceDefault {
+			str += fmt.Sprintf(" (%s)", f.Source)
+		}
+		str += "\n"
+	}
+	return str
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flaviostutz/gitwho/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveEffectiveConfig(t *testing.T) {
+	repoDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(repoDir, cli.ConfigFileName), []byte("defaults:\n  branch: develop\n  since: 1 year ago\ncommands:\n  ownership:\n    detect-moves: true\nidentities:\n  - name: Author 1\n    mail: author1@mail.com\n    names: [a1]\n"), 0644)
+	require.Nil(t, err)
+
+	effective, err := ResolveEffectiveConfig("ownership", []string{"--repo", repoDir, "--format", "short"})
+	require.Nil(t, err)
+	require.Equal(t, filepath.Join(repoDir, cli.ConfigFileName), effective.ConfigFile)
+	require.Len(t, effective.Identities, 1)
+
+	out := FormatEffectiveFlags(effective)
+	require.Contains(t, out, "\nownership\n")
+	require.Contains(t, out, "  --branch=develop (config)\n")
+	require.Contains(t, out, "  --detect-moves=true (config)\n")
+	require.Contains(t, out, "  --format=short (flag)\n")
+	require.Contains(t, out, "  --files=.*\n")
+	require.NotContains(t, out, "--since")
+
+	out = FormatConfigFile(effective)
+	require.Contains(t, out, "Identities: 1\n- Author 1 author1@mail.com (a1)\n")
+
+	_, err = ResolveEffectiveConfig("invalid", []string{})
+	require.NotNil(t, err)
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+defaults:
+  branch: develop
+  ignore-whitespace: true
+  since: 1 year ago
+commands:
+  test:
+    files: ^src/
+    churn-window: 30
+excludes:
+  authors: ['\[bot\]', '^ci@']
+  files: ['\.lock$']
+  presets: [bots, lockfiles]
+identities:
+  - name: Author 1
+    mail: author1@mail.com
+    mails: [a1@mail.com]
+`
+
+const testConfigMergedExcludes = `
+defaults:
+  authors-not: ^renovate
+  exclude-preset: generated
+commands:
+  test:
+    files-not: ^vendor/
+excludes:
+  authors: ['\[bot\]']
+  files: ['\.lock$']
+  presets: [bots]
+`
+
+func testFlags(opts *utils.BaseOptions, churnWindow *int) *flag.FlagSet {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	AddBaseFlags(flags, opts)
+	flags.IntVar(churnWindow, "churn-window", 10, "")
+	return flags
+}
+
+func TestParseFlagsConfig(t *testing.T) {
+	repoDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(repoDir, ConfigFileName), []byte(testConfig), 0644)
+	require.Nil(t, err)
+
+	// config file found in repo
+	opts := utils.BaseOptions{}
+	churnWindow := 0
+	err = ParseFlags(testFlags(&opts, &churnWindow), []string{"--repo", repoDir, "--branch", "main"}, &opts)
+	require.Nil(t, err)
+	require.Equal(t, "main", opts.Branch)
+	require.True(t, opts.IgnoreWhitespace)
+	require.Equal(t, "^src/", opts.FilesRegex)
+	require.Equal(t, 30, churnWindow)
+	require.Equal(t, `\[bot\]|^ci@`, opts.AuthorsNotRegex)
+	require.Equal(t, `\.lock$`, opts.FilesNotRegex)
+	require.Equal(t, "bots,lockfiles", opts.ExcludePresets)
+	require.Len(t, opts.Identities, 1)
+	require.Equal(t, "a1@mail.com", opts.Identities[0].Mails[0])
+
+	// flags override config
+	opts = utils.BaseOptions{}
+	err = ParseFlags(testFlags(&opts, &churnWindow), []string{"--repo", repoDir, "--churn-window", "5", "--authors-not", "x"}, &opts)
+	require.Nil(t, err)
+	require.Equal(t, "develop", opts.Branch)
+	require.Equal(t, 5, churnWindow)
+	require.Equal(t, "x", opts.AuthorsNotRegex)
+
+	// explicit config file
+	opts = utils.BaseOptions{}
+	err = ParseFlags(testFlags(&opts, &churnWindow), []string{"--config", filepath.Join(repoDir, ConfigFileName)}, &opts)
+	require.Nil(t, err)
+	require.Equal(t, "develop", opts.Branch)
+
+	// no config file
+	opts = utils.BaseOptions{}
+	err = ParseFlags(testFlags(&opts, &churnWindow), []string{"--repo", t.TempDir()}, &opts)
+	require.Nil(t, err)
+	require.Equal(t, "main", opts.Branch)
+	require.Len(t, opts.Identities, 0)
+
+	// excludes are merged with the values of the same flags in defaults and commands
+	err = os.WriteFile(filepath.Join(repoDir, ConfigFileName), []byte(testConfigMergedExcludes), 0644)
+	require.Nil(t, err)
+	opts = utils.BaseOptions{}
+	err = ParseFlags(testFlags(&opts, &churnWindow), []string{"--repo", repoDir}, &opts)
+	require.Nil(t, err)
+	require.Equal(t, `^renovate|\[bot\]`, opts.AuthorsNotRegex)
+	require.Equal(t, `^vendor/|\.lock$`, opts.FilesNotRegex)
+	require.Equal(t, "generated,bots", opts.ExcludePresets)
+
+	// unknown flag in command section
+	err = os.WriteFile(filepath.Join(repoDir, ConfigFileName), []byte("commands:\n  test:\n    invalid: 1\n"), 0644)
+	require.Nil(t, err)
+	err = ParseFlags(testFlags(&opts, &churnWindow), []string{"--repo", repoDir}, &opts)
+	require.NotNil(t, err)
+}
@@ -0,0 +1,38 @@
+package hotspots
+
+import (
+	"fmt"
+
+	"github.com/flaviostutz/gitwho/cli"
+	"github.com/flaviostutz/gitwho/hotspots"
+)
+
+// FormatHotspotsResults lists the top files by hotspot score. If full,
+// the factors used to calculate the score are shown for each file
+func FormatHotspotsResults(result hotspots.HotspotsResult, top int, full bool) string {
+	text := fmt.Sprintf("\nHotspots: %d files changed", len(result.Files))
+	if result.SinceCommit.CommitId != "" {
+		text += fmt.Sprintf(" from %s to %s", result.SinceCommit.Date.Format("2006-01-02"), result.UntilCommit.Date.Format("2006-01-02"))
+	}
+	text += "\n"
+
+	files := result.Files
+	if top > 0 && len(files) > top {
+		files = files[:top]
+		text += fmt.Sprintf("Top %d files:\n", top)
+	}
+	for _, file := range files {
+		text += fmt.Sprintf("  %s: %.1f\n", file.FilePath, file.Score)
+		if !full {
+			continue
+		}
+		text += fmt.Sprintf("    - Commits: %d; Authors: %d; Lines touched: %d; Churn: %.0f%%\n", file.Commits, file.Authors, file.LinesTouched, file.ChurnRatio*100)
+		text += fmt.Sprintf("    - Size: %d lines; Avg line age: %.0f days\n", file.TotalLines, file.AgeDaysAvg)
+	}
+	return text
+}
+
+// FormatHotspotsResultsJSON formats hotspots results as a versioned JSON document
+func FormatHotspotsResultsJSON(result hotspots.HotspotsResult, opts hotspots.HotspotsOptions) (string, error) {
+	return cli.FormatJSON("hotspots", opts, result)
+}
@@ -0,0 +1,29 @@
+package hotspots
+
+import (
+	"testing"
+
+	"github.com/flaviostutz/gitwho/hotspots"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatHotspots(t *testing.T) {
+	result := hotspots.HotspotsResult{
+		Files: []hotspots.FileHotspot{
+			{FilePath: "file1", Score: 80.5, Commits: 4, Authors: 2, LinesTouched: 6, ChurnRatio: 0.5, TotalLines: 10, AgeDaysAvg: 3},
+			{FilePath: "file2", Score: 20, Commits: 1, Authors: 1, LinesTouched: 5, TotalLines: 5, AgeDaysAvg: 10},
+		},
+	}
+
+	out := FormatHotspotsResults(result, 1, false)
+	require.Equal(t, "\nHotspots: 2 files changed\nTop 1 files:\n  file1: 80.5\n", out)
+
+	out = FormatHotspotsResults(result, 0, true)
+	require.Contains(t, out, "  file1: 80.5\n    - Commits: 4; Authors: 2; Lines touched: 6; Churn: 50%\n    - Size: 10 lines; Avg line age: 3 days\n")
+	require.Contains(t, out, "  file2: 20.0\n")
+
+	out, err := FormatHotspotsResultsJSON(result, hotspots.HotspotsOptions{})
+	require.Nil(t, err)
+	require.Contains(t, out, "\"command\": \"hotspots\"")
+	require.Contains(t, out, "\"churn_ratio\": 0.5")
+}
@@ -0,0 +1,66 @@
+package hotspots
+
+import (
+	"fmt"
+
+	"github.com/flaviostutz/gitwho/cli"
+	"github.com/flaviostutz/gitwho/hotspots"
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/components"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/go-echarts/go-echarts/v2/types"
+)
+
+// ServeHotspots Start server with a web page with a bubble chart of the files
+// (size x commits, bubble size by score) and returns the random URL generated for the page
+func ServeHotspots(result hotspots.HotspotsResult, hotspotsOpts hotspots.HotspotsOptions) (string, error) {
+	bubbles := make([]opts.ScatterData, 0)
+	for _, file := range result.Files {
+		bubbles = append(bubbles, opts.ScatterData{
+			Name:       file.FilePath,
+			Value:      []interface{}{file.TotalLines, file.Commits, file.Score, file.Authors},
+			SymbolSize: 5 + int(file.Score/2),
+		})
+	}
+
+	scatter := charts.NewScatter()
+	scatter.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{Theme: types.ThemeShine}),
+		charts.WithTitleOpts(opts.Title{
+			Title:    "Hotspots",
+			Subtitle: "Bubble size is the hotspot score",
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{
+			Show:      true,
+			Trigger:   "item",
+			Formatter: opts.FuncOpts("function (p) { return p.name + '<br/>score: ' + p.value[2] + '<br/>lines: ' + p.value[0] + '<br/>commits: ' + p.value[1] + '<br/>authors: ' + p.value[3] }"),
+		}),
+		charts.WithXAxisOpts(opts.XAxis{Name: "Lines", Type: "value"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Commits", Type: "value"}),
+	)
+	scatter.AddSeries("Files", bubbles)
+
+	page := components.NewPage()
+	page.SetLayout(components.PageFlexLayout)
+	page.AddCharts(scatter)
+
+	info := "<pre style=\"display:flex;justify-content:center\"><code>"
+	info += utils.BaseOptsStr(hotspotsOpts.BaseOptions)
+	info += hotspotsOptsStr(hotspotsOpts)
+	info += FormatHotspotsResults(result, 20, true)
+	info += "</code></pre>"
+
+	url, _ := cli.ServeGraphPage(page, info)
+	return url, nil
+}
+
+func hotspotsOptsStr(opts hotspots.HotspotsOptions) string {
+	str := utils.AttrStr("since", opts.SinceDate)
+	str += utils.AttrStr("until", opts.UntilDate)
+	if opts.ChurnWindowDays > 0 {
+		str += utils.AttrStr("churn-window", fmt.Sprintf("%d days", opts.ChurnWindowDays))
+	}
+	str += utils.AttrStr("min-duplicate", fmt.Sprintf("%d", opts.MinDuplicateLines))
+	return str
+}
@@ -0,0 +1,95 @@
+package hotspots
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/flaviostutz/gitwho/cli"
+	"github.com/flaviostutz/gitwho/hotspots"
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// CommandFlags creates the flags of each hotspots command, indexed by command name
+var CommandFlags = map[string]func() *flag.FlagSet{
+	"hotspots": func() *flag.FlagSet {
+		return hotspotsFlags(&hotspots.HotspotsOptions{}, &cli.CliOpts{}, new(int))
+	},
+}
+
+// hotspotsFlags defines the flags of the hotspots command
+func hotspotsFlags(opts *hotspots.HotspotsOptions, cliOpts *cli.CliOpts, top *int) *flag.FlagSet {
+	flags := flag.NewFlagSet("hotspots", flag.ExitOnError)
+	cli.AddBaseFlags(flags, &opts.BaseOptions)
+	flags.StringVar(&opts.SinceDate, "since", "3 months ago", "Consider changes made from this date")
+	flags.StringVar(&opts.UntilDate, "until", "now", "Consider changes made until this date. Size and age of files are calculated at the last commit until this date")
+	flags.IntVar(&opts.ChurnWindowDays, "churn-window", 0, "Changes to lines younger than this number of days are counted as churn. Defaults to the same as in 'changes'")
+	flags.IntVar(&opts.MinDuplicateLines, "min-dup-lines", 4, "Min number of similar lines in a row to be considered a duplicate")
+	flags.IntVar(top, "top", 20, "Number of files shown in 'full' and 'short' outputs. Use 0 to show all")
+	flags.StringVar(&cliOpts.Format, "format", "full", "Output format. 'full' (more details), 'short' (file and score), 'graph' (open browser) or 'json' (JSON document)")
+	flags.StringVar(&cliOpts.GoProfileFile, "profile-file", "", "Profile file to dump golang runtime data to")
+	flags.BoolVar(&cliOpts.Verbose, "verbose", false, "Show verbose logs during processing")
+	return flags
+}
+
+func RunHotspots(osArgs []string) {
+	opts := hotspots.HotspotsOptions{}
+	cliOpts := cli.CliOpts{}
+	top := 0
+	flags := hotspotsFlags(&opts, &cliOpts, &top)
+	err := cli.ParseFlags(flags, osArgs[2:], &opts.BaseOptions)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	progressChan := cli.SetupBasic(cliOpts, opts.BaseOptions)
+	defer close(progressChan)
+
+	_, err = cli.LatestCommit(opts.BaseOptions, "")
+	if err != nil {
+		fmt.Printf("Branch %s not found\n", opts.Branch)
+		os.Exit(1)
+	}
+
+	logrus.Debugf("Starting analysis of hotspots")
+	result, err := hotspots.AnalyseHotspots(opts, progressChan)
+	if err != nil {
+		fmt.Println("Failed to perform hotspots analysis. err=", err)
+		os.Exit(2)
+	}
+
+	switch cliOpts.Format {
+	case "full":
+		fmt.Println(FormatHotspotsResults(result, top, true))
+
+	case "short":
+		fmt.Println(FormatHotspotsResults(result, top, false))
+
+	case "graph":
+		url, err := ServeHotspots(result, opts)
+		if err != nil {
+			fmt.Printf("Couldn't format results. err=%s\n", err)
+			os.Exit(4)
+		}
+		_, err = utils.ExecShellf("", "open %s", url)
+		if err != nil {
+			fmt.Printf("Couldn't open browser automatically. See results at %s\n", url)
+		}
+		fmt.Printf("\nServing graph at %s\n", url)
+		select {}
+
+	case "csv":
+		fmt.Printf("format 'csv' is not supported\n")
+		os.Exit(3)
+
+	case "json":
+		output, err := FormatHotspotsResultsJSON(result, opts)
+		if err != nil {
+			fmt.Printf("Couldn't format results as JSON. err=%s\n", err)
+			os.Exit(4)
+		}
+		fmt.Println(output)
+	}
+}
@@ -0,0 +1,89 @@
+package ownership
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/flaviostutz/gitwho/cli"
+	"github.com/flaviostutz/gitwho/ownership"
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// busfactorFlags defines the flags of the busfactor command
+func busfactorFlags(opts *ownership.BusFactorOptions, cliOpts *cli.CliOpts, when *string) *flag.FlagSet {
+	flags := flag.NewFlagSet("busfactor", flag.ExitOnError)
+	cli.AddBaseFlags(flags, &opts.BaseOptions)
+	flags.IntVar(&opts.MinDuplicateLines, "min-dup-lines", 4, "Min number of similar lines in a row to be considered a duplicate")
+	flags.IntVar(&opts.MaxDepth, "depth", 3, "Max number of directory levels to analyse. Use 0 for unlimited")
+	flags.BoolVar(&opts.IncludeFiles, "show-files", false, "Calculate bus factor for each file too")
+	flags.StringVar(&opts.ActiveSince, "active-since", "6 months ago", "Authors without commits since this date are considered inactive. Areas whose main owners are all inactive are flagged as orphaned")
+	flags.StringVar(when, "when", "now", "Date to do analysis in repo")
+	flags.StringVar(&cliOpts.Format, "format", "full", "Output format. 'full' (bus factor per directory), 'short' (repo bus factor and orphaned areas), 'graph' (open browser) or 'json' (JSON document)")
+	flags.StringVar(&cliOpts.GoProfileFile, "profile-file", "", "Profile file to dump golang runtime data to")
+	flags.BoolVar(&cliOpts.Verbose, "verbose", false, "Show verbose logs during processing")
+	return flags
+}
+
+func RunBusFactor(osArgs []string) {
+	opts := ownership.BusFactorOptions{}
+	cliOpts := cli.CliOpts{}
+	when := ""
+	flags := busfactorFlags(&opts, &cliOpts, &when)
+	err := cli.ParseFlags(flags, osArgs[2:], &opts.BaseOptions)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	progressChan := cli.SetupBasic(cliOpts, opts.BaseOptions)
+	defer close(progressChan)
+
+	commit, err := cli.LatestCommit(opts.BaseOptions, when)
+	if err != nil {
+		fmt.Printf("Branch %s not found\n", opts.Branch)
+		os.Exit(1)
+	}
+	opts.CommitId = commit.CommitId
+
+	logrus.Debugf("Starting analysis of bus factor. commitId=%s", opts.CommitId)
+	result, err := ownership.AnalyseBusFactor(opts, progressChan)
+	if err != nil {
+		fmt.Println("Failed to perform bus factor analysis. err=", err)
+		os.Exit(2)
+	}
+
+	switch cliOpts.Format {
+	case "full":
+		fmt.Println(FormatBusFactorResults(result, opts, true))
+
+	case "short":
+		fmt.Println(FormatBusFactorResults(result, opts, false))
+
+	case "graph":
+		url, err := ServeBusFactor(result, opts)
+		if err != nil {
+			fmt.Printf("Couldn't format results. err=%s\n", err)
+			os.Exit(4)
+		}
+		_, err = utils.ExecShellf("", "open %s", url)
+		if err != nil {
+			fmt.Printf("Couldn't open browser automatically. See results at %s\n", url)
+		}
+		fmt.Printf("\nServing graph at %s\n", url)
+		select {}
+
+	case "csv":
+		fmt.Printf("format 'csv' is not supported\n")
+		os.Exit(3)
+
+	case "json":
+		output, err := FormatBusFactorResultsJSON(result, opts)
+		if err != nil {
+			fmt.Printf("Couldn't format results as JSON. err=%s\n", err)
+			os.Exit(4)
+		}
+		fmt.Println(output)
+	}
+}
@@ -0,0 +1,109 @@
+package ownership
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/flaviostutz/gitwho/cli"
+	"github.com/flaviostutz/gitwho/ownership"
+	"github.com/sirupsen/logrus"
+)
+
+// default locations of CODEOWNERS files in GitHub and GitLab
+var codeownersLocations = []string{"CODEOWNERS", ".github/CODEOWNERS", ".gitlab/CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeownersFlags defines the flags of the codeowners command
+func codeownersFlags(opts *ownership.CodeownersOptions, cliOpts *cli.CliOpts, when *string, check *bool) *flag.FlagSet {
+	flags := flag.NewFlagSet("codeowners", flag.ExitOnError)
+	cli.AddBaseFlags(flags, &opts.BaseOptions)
+	flags.StringVar(&opts.TeamsFile, "teams", "", "YAML file mapping team names to lists of author regexes (name or email). Used to match team owners such as @org/team in --check mode")
+	flags.IntVar(&opts.MinDuplicateLines, "min-dup-lines", 4, "Min number of similar lines in a row to be considered a duplicate")
+	flags.IntVar(&opts.MaxDepth, "depth", 3, "Max number of directory levels to generate rules for. Use 0 for unlimited")
+	flags.BoolVar(&opts.IncludeFiles, "show-files", false, "Generate rules for files too")
+	flags.Float64Var(&opts.MinShare, "min-share", 20, "Min percentage of the lines of a path an author must own to be one of its owners. In --check mode, min percentage the declared owners must own together")
+	flags.IntVar(&opts.MaxOwners, "max-owners", 3, "Max number of owners per path")
+	flags.BoolVar(check, "check", false, "Check an existing CODEOWNERS file and report paths whose declared owners own less than --min-share of the code")
+	flags.StringVar(&opts.CheckFile, "codeowners-file", "", "CODEOWNERS file to check. Defaults to CODEOWNERS, .github/CODEOWNERS, .gitlab/CODEOWNERS or docs/CODEOWNERS in repo")
+	flags.StringVar(when, "when", "now", "Date to do analysis in repo")
+	flags.StringVar(&cliOpts.Format, "format", "full", "Output format. 'full' (CODEOWNERS with ownership comments), 'short' (plain CODEOWNERS) or 'json' (JSON document)")
+	flags.StringVar(&cliOpts.GoProfileFile, "profile-file", "", "Profile file to dump golang runtime data to")
+	flags.BoolVar(&cliOpts.Verbose, "verbose", false, "Show verbose logs during processing")
+	return flags
+}
+
+func RunCodeowners(osArgs []string) {
+	opts := ownership.CodeownersOptions{}
+	cliOpts := cli.CliOpts{}
+	when := ""
+	check := false
+	flags := codeownersFlags(&opts, &cliOpts, &when, &check)
+	err := cli.ParseFlags(flags, osArgs[2:], &opts.BaseOptions)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if !check {
+		opts.CheckFile = ""
+	}
+	if check && opts.CheckFile == "" {
+		for _, location := range codeownersLocations {
+			file := filepath.Join(opts.RepoDir, location)
+			if _, err := os.Stat(file); err == nil {
+				opts.CheckFile = file
+				break
+			}
+		}
+		if opts.CheckFile == "" {
+			fmt.Printf("CODEOWNERS file not found in repo\n")
+			os.Exit(1)
+		}
+	}
+
+	progressChan := cli.SetupBasic(cliOpts, opts.BaseOptions)
+	defer close(progressChan)
+
+	commit, err := cli.LatestCommit(opts.BaseOptions, when)
+	if err != nil {
+		fmt.Printf("Branch %s not found\n", opts.Branch)
+		os.Exit(1)
+	}
+	opts.CommitId = commit.CommitId
+
+	logrus.Debugf("Starting analysis of code owners. commitId=%s", opts.CommitId)
+	result, err := ownership.AnalyseCodeowners(opts, progressChan)
+	if err != nil {
+		fmt.Println("Failed to perform codeowners analysis. err=", err)
+		os.Exit(2)
+	}
+
+	switch cliOpts.Format {
+	case "full", "short":
+		if check {
+			output, failed := FormatCodeownersCheckResults(result, opts, cliOpts.Format == "full")
+			fmt.Println(output)
+			if failed {
+				os.Exit(5)
+			}
+			return
+		}
+		fmt.Print(FormatCodeownersResults(result, cliOpts.Format == "full"))
+
+	case "graph", "csv":
+		fmt.Printf("format '%s' is not supported\n", cliOpts.Format)
+		os.Exit(3)
+
+	case "json":
+		output, err := FormatCodeownersResultsJSON(result, opts)
+		if err != nil {
+			fmt.Printf("Couldn't format results as JSON. err=%s\n", err)
+			os.Exit(4)
+		}
+		fmt.Println(output)
+		if check && len(result.FailedRules(opts.MinShare)) > 0 {
+			os.Exit(5)
+		}
+	}
+}
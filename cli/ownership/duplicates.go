@@ -7,35 +7,36 @@ import (
 
 	"github.com/flaviostutz/gitwho/cli"
 	"github.com/flaviostutz/gitwho/ownership"
-	"github.com/flaviostutz/gitwho/utils"
 	"github.com/sirupsen/logrus"
 )
 
-func RunDuplicates(osArgs []string) {
-	opts := ownership.OwnershipOptions{}
-	cliOpts := cli.CliOpts{}
-	when := ""
+// duplicatesFlags defines the flags of the duplicates command
+func duplicatesFlags(opts *ownership.OwnershipOptions, cliOpts *cli.CliOpts, when *string) *flag.FlagSet {
 	flags := flag.NewFlagSet("duplicates", flag.ExitOnError)
-	flags.StringVar(&opts.RepoDir, "repo", ".", "Repository path to analyse")
-	flags.StringVar(&opts.Branch, "branch", "main", "Branch name to analyse")
-	flags.StringVar(&opts.FilesRegex, "files", ".*", "Regex for selecting which file paths to include in analysis")
-	flags.StringVar(&opts.FilesNotRegex, "files-not", "", "Regex for filtering out files from analysis")
-	flags.StringVar(&opts.AuthorsRegex, "authors", ".*", "Regex for selecting which authors to include in analysis")
-	flags.StringVar(&opts.AuthorsNotRegex, "authors-not", "", "Regex for filtering out authors from analysis")
-	flags.StringVar(&opts.CacheFile, "cache-file", "", "If defined, stores results in a cache file that can be used in subsequent calls that uses the same parameters.")
-	flags.IntVar(&opts.CacheTTLSeconds, "cache-ttl", 5184000, "Time in seconds for old items in cache file to be deleted. Defaults to 2 months")
+	cli.AddBaseFlags(flags, &opts.BaseOptions)
 	flags.IntVar(&opts.MinDuplicateLines, "min-dup-lines", 4, "Min number of similar lines in a row to be considered a duplicate")
-	flags.StringVar(&when, "when", "now", "Date to do analysis in repo")
-	flags.StringVar(&cliOpts.Format, "format", "full", "Output format. 'full' (more details) or 'short' (lines per author)")
+	flags.StringVar(when, "when", "now", "Date to do analysis in repo")
+	flags.StringVar(&cliOpts.Format, "format", "full", "Output format. 'full' (more details), 'short' (lines per author) or 'json' (JSON document)")
 	flags.StringVar(&cliOpts.GoProfileFile, "profile-file", "", "Profile file to dump golang runtime data to")
 	flags.BoolVar(&cliOpts.Verbose, "verbose", false, "Show verbose logs during processing")
+	return flags
+}
 
-	flags.Parse(osArgs[2:])
+func RunDuplicates(osArgs []string) {
+	opts := ownership.OwnershipOptions{}
+	cliOpts := cli.CliOpts{}
+	when := ""
+	flags := duplicatesFlags(&opts, &cliOpts, &when)
+	err := cli.ParseFlags(flags, osArgs[2:], &opts.BaseOptions)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
-	progressChan := cli.SetupBasic(cliOpts)
+	progressChan := cli.SetupBasic(cliOpts, opts.BaseOptions)
 	defer close(progressChan)
 
-	commit, err := utils.ExecGetLastestCommit(opts.RepoDir, opts.Branch, "", when)
+	commit, err := cli.LatestCommit(opts.BaseOptions, when)
 	if err != nil {
 		fmt.Printf("Branch %s not found\n", opts.Branch)
 		os.Exit(1)
@@ -54,6 +55,16 @@ func RunDuplicates(osArgs []string) {
 		os.Exit(3)
 	}
 
+	if cliOpts.Format == "json" {
+		output, err := FormatDuplicatesResultsJSON(ownershipResults, opts)
+		if err != nil {
+			fmt.Printf("Couldn't format results as JSON. err=%s\n", err)
+			os.Exit(4)
+		}
+		fmt.Println(output)
+		return
+	}
+
 	output := FormatDuplicatesResults(ownershipResults, cliOpts.Format == "full")
 	fmt.Println(output)
 }
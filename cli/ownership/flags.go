@@ -0,0 +1,33 @@
+package ownership
+
+import (
+	"flag"
+
+	"github.com/flaviostutz/gitwho/cli"
+	"github.com/flaviostutz/gitwho/ownership"
+)
+
+// CommandFlags creates the flags of each ownership command, indexed by command name
+var CommandFlags = map[string]func() *flag.FlagSet{
+	"ownership": func() *flag.FlagSet {
+		return ownershipFlags(&ownership.OwnershipOptions{}, &cli.CliOpts{}, new(string), &ownership.LeaveOptions{})
+	},
+	"ownership-timeseries": func() *flag.FlagSet {
+		return ownershipTimeseriesFlags(&ownership.OwnershipTimeseriesOptions{}, &cli.CliOpts{})
+	},
+	"ownership-tree": func() *flag.FlagSet {
+		return ownershipTreeFlags(&ownership.OwnershipTreeOptions{}, &cli.CliOpts{}, new(string))
+	},
+	"busfactor": func() *flag.FlagSet {
+		return busfactorFlags(&ownership.BusFactorOptions{}, &cli.CliOpts{}, new(string))
+	},
+	"codeowners": func() *flag.FlagSet {
+		return codeownersFlags(&ownership.CodeownersOptions{}, &cli.CliOpts{}, new(string), new(bool))
+	},
+	"duplicates": func() *flag.FlagSet {
+		return duplicatesFlags(&ownership.OwnershipOptions{}, &cli.CliOpts{}, new(string))
+	},
+	"survival": func() *flag.FlagSet {
+		return survivalFlags(&ownership.OwnershipTimeseriesOptions{}, &cli.CliOpts{})
+	},
+}
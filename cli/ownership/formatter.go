@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/flaviostutz/gitwho/cli"
 	"github.com/flaviostutz/gitwho/ownership"
 	"github.com/flaviostutz/gitwho/utils"
 )
@@ -15,6 +16,13 @@ type authorLinesDate struct {
 	authorLines ownership.AuthorLines
 }
 
+type duplicatesResult struct {
+	Commit               utils.CommitInfo  `json:"commit"`
+	TotalLines           int               `json:"total_lines"`
+	TotalLinesDuplicated int               `json:"total_lines_duplicated"`
+	DuplicateLineGroups  []utils.LineGroup `json:"duplicate_line_groups"`
+}
+
 func FormatCodeOwnershipResults(oresult ownership.OwnershipResult, full bool) (string, error) {
 	// author clusters
 	text := fmt.Sprintf("\nTotal authors: %d\n", len(oresult.AuthorsLines))
@@ -23,6 +31,9 @@ func FormatCodeOwnershipResults(oresult ownership.OwnershipResult, full bool) (s
 	if full {
 		text += fmt.Sprintf("Avg line age: %s\n", avgLineAgeStr(oresult.LinesAgeDaysSum, oresult.TotalLines))
 		text += fmt.Sprintf("Duplicated lines: %d (%d%%)\n", oresult.TotalLinesDuplicated, int(100*float64(oresult.TotalLinesDuplicated)/float64(oresult.TotalLines)))
+		if oresult.TotalLinesMoved > 0 {
+			text += fmt.Sprintf("Lines moved from other files: %d (%d%%)\n", oresult.TotalLinesMoved, int(100*float64(oresult.TotalLinesMoved)/float64(oresult.TotalLines)))
+		}
 	}
 
 	// author clusters
@@ -42,6 +53,9 @@ func FormatCodeOwnershipResults(oresult ownership.OwnershipResult, full bool) (s
 				authorLines.OwnedLinesDuplicate,
 				authorLines.OwnedLinesDuplicateOriginal,
 				authorLines.OwnedLinesDuplicateOriginalOthers)
+			if authorLines.OwnedLinesMoved > 0 {
+				additional += fmt.Sprintf(" moved:%d", authorLines.OwnedLinesMoved)
+			}
 			mailStr = fmt.Sprintf(" %s", authorLines.AuthorMail)
 		}
 		text += fmt.Sprintf("  %s%s: %d (%s%%)%s\n",
@@ -52,9 +66,40 @@ func FormatCodeOwnershipResults(oresult ownership.OwnershipResult, full bool) (s
 			additional)
 	}
 
+	text += formatTeamsLines(oresult, full)
+
 	return text, nil
 }
 
+func formatTeamsLines(oresult ownership.OwnershipResult, full bool) string {
+	if len(oresult.TeamsLines) == 0 {
+		return ""
+	}
+	text := fmt.Sprintf("Total teams: %d\n", len(oresult.TeamsLines))
+	for _, teamLines := range oresult.TeamsLines {
+		additional := ""
+		if full {
+			additional = fmt.Sprintf(" avg-days:%d dup:%d orig:%d dup-others:%d",
+				int((teamLines.OwnedLinesAgeDaysSum / float64(teamLines.OwnedLinesTotal))),
+				teamLines.OwnedLinesDuplicate,
+				teamLines.OwnedLinesDuplicateOriginal,
+				teamLines.OwnedLinesDuplicateOriginalOthers)
+			if teamLines.OwnedLinesMoved > 0 {
+				additional += fmt.Sprintf(" moved:%d", teamLines.OwnedLinesMoved)
+			}
+		}
+		text += fmt.Sprintf("  %s: %d (%s%%)%s\n",
+			teamLines.TeamName,
+			teamLines.OwnedLinesTotal,
+			strconv.FormatFloat(float64(100)*(float64(teamLines.OwnedLinesTotal)/float64(oresult.TotalLines)), 'f', 1, 32),
+			additional)
+		if full {
+			text += fmt.Sprintf("    authors: %s\n", utils.JoinWithLimit(teamLines.AuthorNames, ", ", 10))
+		}
+	}
+	return text
+}
+
 func FormatDuplicatesResults(ownershipResult ownership.OwnershipResult, full bool) string {
 	text := fmt.Sprintf("Total lines: %d\n", ownershipResult.TotalLines)
 	text += fmt.Sprintf("Duplicated lines: %d (%d%%)\n", ownershipResult.TotalLinesDuplicated, int(100*float64(ownershipResult.TotalLinesDuplicated)/float64(ownershipResult.TotalLines)))
@@ -75,6 +120,21 @@ func FormatDuplicatesResults(ownershipResult ownership.OwnershipResult, full boo
 	return text
 }
 
+// FormatCodeOwnershipResultsJSON formats ownership results as a versioned JSON document
+func FormatCodeOwnershipResultsJSON(oresult ownership.OwnershipResult, opts ownership.OwnershipOptions) (string, error) {
+	return cli.FormatJSON("ownership", opts, oresult)
+}
+
+// FormatDuplicatesResultsJSON formats the duplicated lines found during ownership analysis as a versioned JSON document
+func FormatDuplicatesResultsJSON(oresult ownership.OwnershipResult, opts ownership.OwnershipOptions) (string, error) {
+	return cli.FormatJSON("duplicates", opts, duplicatesResult{
+		Commit:               oresult.Commit,
+		TotalLines:           oresult.TotalLines,
+		TotalLinesDuplicated: oresult.TotalLinesDuplicated,
+		DuplicateLineGroups:  oresult.DuplicateLineGroups,
+	})
+}
+
 func avgLineAgeStr(linesAgeDaysSum float64, totalLines int) string {
 	return fmt.Sprintf("%1.f days", (linesAgeDaysSum / float64(totalLines)))
 }
@@ -100,7 +160,9 @@ func formatAuthorClusters(cresult []ownership.OwnershipResult) (string, error) {
 	return text, nil
 }
 
-// FormatCodeOwnershipResultsCSV formats ownership results as CSV
+// FormatCodeOwnershipResultsCSV formats ownership results as CSV.
+// If teams were defined, a "Team" column is added to the author rows and
+// a row with the totals of each team (with empty author name and mail) is added after them
 func FormatCodeOwnershipResultsCSV(oresult ownership.OwnershipResult) (string, error) {
 	var buf bytes.Buffer
 	writer := csv.NewWriter(&buf)
@@ -116,6 +178,16 @@ func FormatCodeOwnershipResultsCSV(oresult ownership.OwnershipResult) (string, e
 		"OwnedLinesDuplicateOriginal",
 		"OwnedLinesDuplicateOriginalOthers",
 	}
+	withTeams := len(oresult.TeamsLines) > 0
+	authorTeams := make(map[string]string, 0)
+	if withTeams {
+		header = append(header, "Team")
+		for _, teamLines := range oresult.TeamsLines {
+			for _, authorName := range teamLines.AuthorNames {
+				authorTeams[authorName] = teamLines.TeamName
+			}
+		}
+	}
 	if err := writer.Write(header); err != nil {
 		return "", fmt.Errorf("failed to write CSV header: %v", err)
 	}
@@ -131,6 +203,25 @@ func FormatCodeOwnershipResultsCSV(oresult ownership.OwnershipResult) (string, e
 			strconv.Itoa(result.OwnedLinesDuplicateOriginal),
 			strconv.Itoa(result.OwnedLinesDuplicateOriginalOthers),
 		}
+		if withTeams {
+			row = append(row, authorTeams[result.AuthorName])
+		}
+		if err := writer.Write(row); err != nil {
+			return "", fmt.Errorf("failed to write CSV row: %v", err)
+		}
+	}
+
+	for _, teamLines := range oresult.TeamsLines {
+		row := []string{
+			"",
+			"",
+			strconv.Itoa(teamLines.OwnedLinesTotal),
+			fmt.Sprintf("%.2f", teamLines.OwnedLinesAgeDaysSum),
+			strconv.Itoa(teamLines.OwnedLinesDuplicate),
+			strconv.Itoa(teamLines.OwnedLinesDuplicateOriginal),
+			strconv.Itoa(teamLines.OwnedLinesDuplicateOriginalOthers),
+			teamLines.TeamName,
+		}
 		if err := writer.Write(row); err != nil {
 			return "", fmt.Errorf("failed to write CSV row: %v", err)
 		}
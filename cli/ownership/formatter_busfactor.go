@@ -0,0 +1,64 @@
+package ownership
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flaviostutz/gitwho/cli"
+	"github.com/flaviostutz/gitwho/ownership"
+	"github.com/flaviostutz/gitwho/utils"
+)
+
+// FormatBusFactorResults shows the repo bus factor, the orphaned areas and,
+// if full, the bus factor of each directory
+func FormatBusFactorResults(result ownership.BusFactorResult, opts ownership.BusFactorOptions, full bool) string {
+	root := result.Root
+	text := fmt.Sprintf("\nBus factor: %d (%s)\n", root.BusFactor, strings.Join(root.MainOwners, ", "))
+	text += fmt.Sprintf("Total lines: %d\n", root.TotalLines)
+	if opts.ActiveSince == "" {
+		return text + formatBusFactorTree(root, full)
+	}
+
+	text += fmt.Sprintf("Active authors since %s: %d\n", opts.ActiveSince, len(result.ActiveAuthors))
+	text += fmt.Sprintf("Lines owned by inactive authors: %d%s\n", root.InactiveLines, utils.CalcPercStr(root.InactiveLines, root.TotalLines))
+
+	orphanedNodes := root.OrphanedNodes()
+	orphanedLines := 0
+	for _, node := range orphanedNodes {
+		orphanedLines += node.TotalLines
+	}
+	text += fmt.Sprintf("Orphaned areas: %d (%d lines%s)\n", len(orphanedNodes), orphanedLines, utils.CalcPercStr(orphanedLines, root.TotalLines))
+	for _, node := range orphanedNodes {
+		text += fmt.Sprintf("  %s (%d lines): %s\n", node.Path, node.TotalLines, strings.Join(node.InactiveMainOwners, ", "))
+	}
+
+	return text + formatBusFactorTree(root, full)
+}
+
+// FormatBusFactorResultsJSON formats bus factor results as a versioned JSON document
+func FormatBusFactorResultsJSON(result ownership.BusFactorResult, opts ownership.BusFactorOptions) (string, error) {
+	return cli.FormatJSON("busfactor", opts, result)
+}
+
+func formatBusFactorTree(root *ownership.BusFactorNode, full bool) string {
+	if !full {
+		return ""
+	}
+	return "\nBus factor per directory:\n" + formatBusFactorNode(root, 1)
+}
+
+func formatBusFactorNode(node *ownership.BusFactorNode, level int) string {
+	name := node.Name
+	if !node.IsFile && level > 1 {
+		name += "/"
+	}
+	orphaned := ""
+	if node.Orphaned {
+		orphaned = " [orphaned]"
+	}
+	text := fmt.Sprintf("%s%s bus-factor:%d (%d lines): %s%s\n", strings.Repeat("  ", level), name, node.BusFactor, node.TotalLines, utils.JoinWithLimit(node.MainOwners, ", ", 5), orphaned)
+	for _, child := range node.Children {
+		text += formatBusFactorNode(child, level+1)
+	}
+	return text
+}
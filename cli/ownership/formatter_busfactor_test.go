@@ -0,0 +1,46 @@
+package ownership
+
+import (
+	"testing"
+
+	"github.com/flaviostutz/gitwho/ownership"
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatBusFactor(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	opts := ownership.BusFactorOptions{
+		OwnershipTreeOptions: ownership.OwnershipTreeOptions{
+			OwnershipOptions: ownership.OwnershipOptions{
+				BaseOptions: utils.BaseOptions{
+					RepoDir: repoDir,
+					Branch:  "main",
+				},
+				MinDuplicateLines: 2,
+				CommitId:          commit.CommitId,
+			},
+		},
+		ActiveSince: "1 year ago",
+	}
+	result, err := ownership.AnalyseBusFactor(opts, nil)
+	require.Nil(t, err)
+
+	out := FormatBusFactorResults(result, opts, false)
+	require.Contains(t, out, "Bus factor: 1 (author3)\nTotal lines: 7\nActive authors since 1 year ago: 3\n")
+	require.Contains(t, out, "Orphaned areas: 0 (0 lines (0%))\n")
+	require.NotContains(t, out, "Bus factor per directory")
+
+	out = FormatBusFactorResults(result, opts, true)
+	require.Contains(t, out, "Bus factor per directory:\n  . bus-factor:1 (7 lines): author3\n    dir1/ bus-factor:1 (5 lines): author3\n")
+
+	out, err = FormatBusFactorResultsJSON(result, opts)
+	require.Nil(t, err)
+	require.Contains(t, out, "\"command\": \"busfactor\"")
+	require.Contains(t, out, "\"bus_factor\": 1")
+}
@@ -0,0 +1,45 @@
+package ownership
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flaviostutz/gitwho/cli"
+	"github.com/flaviostutz/gitwho/ownership"
+)
+
+// FormatCodeownersResults formats the generated rules as a CODEOWNERS file
+func FormatCodeownersResults(result ownership.CodeownersResult, full bool) string {
+	text := fmt.Sprintf("# Generated by gitwho based on the ownership of lines at commit %s\n", result.Commit.CommitId)
+	for _, rule := range result.Rules {
+		if full {
+			text += fmt.Sprintf("\n# owners have %.1f%% of %d lines\n", rule.OwnersShare(), rule.TotalLines)
+		}
+		text += fmt.Sprintf("%s %s\n", rule.Pattern, strings.Join(rule.Owners, " "))
+	}
+	return text
+}
+
+// FormatCodeownersCheckResults shows the rules of an existing CODEOWNERS file whose declared
+// owners own less than opts.MinShare of the code. Returns true if any rule failed the check
+func FormatCodeownersCheckResults(result ownership.CodeownersResult, opts ownership.CodeownersOptions, full bool) (string, bool) {
+	text := fmt.Sprintf("\nChecked rules: %d\n", len(result.Rules))
+	failed := result.FailedRules(opts.MinShare)
+	text += fmt.Sprintf("Rules with owners below %.0f%%: %d\n", opts.MinShare, len(failed))
+	for _, rule := range failed {
+		text += fmt.Sprintf("  %s %s: %.1f%% of %d lines\n", rule.Pattern, strings.Join(rule.Owners, " "), rule.OwnersShare(), rule.TotalLines)
+	}
+
+	if full {
+		text += "\nAll rules:\n"
+		for _, rule := range result.Rules {
+			text += fmt.Sprintf("  %s %s: %.1f%% of %d lines\n", rule.Pattern, strings.Join(rule.Owners, " "), rule.OwnersShare(), rule.TotalLines)
+		}
+	}
+	return text, len(failed) > 0
+}
+
+// FormatCodeownersResultsJSON formats codeowners results as a versioned JSON document
+func FormatCodeownersResultsJSON(result ownership.CodeownersResult, opts ownership.CodeownersOptions) (string, error) {
+	return cli.FormatJSON("codeowners", opts, result)
+}
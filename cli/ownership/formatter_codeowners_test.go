@@ -0,0 +1,29 @@
+package ownership
+
+import (
+	"testing"
+
+	"github.com/flaviostutz/gitwho/ownership"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatCodeowners(t *testing.T) {
+	result := ownership.CodeownersResult{
+		Rules: []ownership.CodeownersRule{
+			{Pattern: "*", Owners: []string{"author3@mail.com"}, TotalLines: 5, OwnedLines: 5},
+			{Pattern: "/file1", Owners: []string{"author1@mail.com", "author2@mail.com"}, TotalLines: 2, OwnedLines: 2},
+		},
+	}
+	result.Commit.CommitId = "abc"
+
+	out := FormatCodeownersResults(result, false)
+	require.Equal(t, "# Generated by gitwho based on the ownership of lines at commit abc\n* author3@mail.com\n/file1 author1@mail.com author2@mail.com\n", out)
+
+	out = FormatCodeownersResults(result, true)
+	require.Contains(t, out, "\n# owners have 100.0% of 2 lines\n/file1 author1@mail.com author2@mail.com\n")
+
+	result.Rules[1].OwnedLines = 0
+	out, failed := FormatCodeownersCheckResults(result, ownership.CodeownersOptions{MinShare: 50}, false)
+	require.True(t, failed)
+	require.Contains(t, out, "Rules with owners below 50%: 1\n  /file1 author1@mail.com author2@mail.com: 0.0% of 2 lines\n")
+}
@@ -0,0 +1,50 @@
+package ownership
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flaviostutz/gitwho/cli"
+	"github.com/flaviostutz/gitwho/ownership"
+	"github.com/flaviostutz/gitwho/utils"
+)
+
+// FormatLeaveResults shows the lines orphaned by the leaving authors and the areas that would
+// drop below the min share of lines owned by active authors. If full, successors are shown too
+func FormatLeaveResults(result ownership.LeaveResult, opts ownership.LeaveOptions, full bool) string {
+	if len(result.LeavingAuthors) == 0 {
+		return fmt.Sprintf("\nNo authors matching '%s' own lines in the repo\n", opts.LeavingAuthors)
+	}
+
+	text := fmt.Sprintf("\nSimulating leave of: %s\n", strings.Join(result.LeavingAuthors, ", "))
+	text += fmt.Sprintf("Total lines: %d\n", result.TotalLines)
+	text += fmt.Sprintf("Orphaned lines: %d%s\n", result.OrphanedLines, utils.CalcPercStr(result.OrphanedLines, result.TotalLines))
+	if opts.ActiveSince != "" {
+		text += fmt.Sprintf("Active authors since %s: %d\n", opts.ActiveSince, len(result.ActiveAuthors))
+	}
+	text += fmt.Sprintf("Lines owned by active authors: %.1f%% -> %.1f%%\n", result.ActiveShareBefore, result.ActiveShareAfter)
+
+	text += fmt.Sprintf("\nAreas that would drop below %.0f%% of lines owned by active authors: %d\n", opts.MinActiveShare, len(result.Areas))
+	for _, area := range result.Areas {
+		name := area.Path
+		if !area.IsFile && area.Path != "." {
+			name += "/"
+		}
+		text += fmt.Sprintf("  %s (%d lines, %d orphaned): %.1f%% -> %.1f%%\n", name, area.TotalLines, area.OrphanedLines, area.ActiveShareBefore, area.ActiveShareAfter)
+		if !full {
+			continue
+		}
+		if len(area.Successors) == 0 {
+			text += "    - no successors found\n"
+		}
+		for _, successor := range area.Successors {
+			text += fmt.Sprintf("    - %s %s: score %.1f (%d lines owned, %d recent commits)\n", successor.AuthorName, successor.AuthorMail, successor.Score, successor.OwnedLines, successor.RecentCommits)
+		}
+	}
+	return text
+}
+
+// FormatLeaveResultsJSON formats leave simulation results as a versioned JSON document
+func FormatLeaveResultsJSON(result ownership.LeaveResult, opts ownership.LeaveOptions) (string, error) {
+	return cli.FormatJSON("ownership-leave", opts, result)
+}
@@ -0,0 +1,57 @@
+package ownership
+
+import (
+	"testing"
+
+	"github.com/flaviostutz/gitwho/ownership"
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatLeave(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	opts := ownership.LeaveOptions{
+		OwnershipTreeOptions: ownership.OwnershipTreeOptions{
+			OwnershipOptions: ownership.OwnershipOptions{
+				BaseOptions: utils.BaseOptions{
+					RepoDir: repoDir,
+					Branch:  "main",
+				},
+				MinDuplicateLines: 2,
+				CommitId:          commit.CommitId,
+			},
+			MaxDepth:     1,
+			IncludeFiles: true,
+		},
+		LeavingAuthors: "author3",
+		MinActiveShare: 50,
+		ActiveSince:    "1 year ago",
+		MaxSuccessors:  3,
+	}
+	result, err := ownership.AnalyseLeave(opts, nil)
+	require.Nil(t, err)
+
+	out := FormatLeaveResults(result, opts, false)
+	require.Contains(t, out, "Simulating leave of: author3\nTotal lines: 7\nOrphaned lines: 5 (71%)\nActive authors since 1 year ago: 3\nLines owned by active authors: 100.0% -> 28.6%\n")
+	require.Contains(t, out, "Areas that would drop below 50% of lines owned by active authors: 2\n  . (7 lines, 5 orphaned): 100.0% -> 28.6%\n  dir1/ (5 lines, 5 orphaned): 100.0% -> 0.0%\n")
+	require.NotContains(t, out, "score")
+
+	out = FormatLeaveResults(result, opts, true)
+	require.Contains(t, out, "  . (7 lines, 5 orphaned): 100.0% -> 28.6%\n    - author1 <author1@mail.com>: score 100.0 (1 lines owned, 3 recent commits)\n")
+	require.Contains(t, out, "  dir1/ (5 lines, 5 orphaned): 100.0% -> 0.0%\n    - no successors found\n")
+
+	opts.LeavingAuthors = "nobody"
+	result, err = ownership.AnalyseLeave(opts, nil)
+	require.Nil(t, err)
+	require.Equal(t, "\nNo authors matching 'nobody' own lines in the repo\n", FormatLeaveResults(result, opts, true))
+
+	out, err = FormatLeaveResultsJSON(result, opts)
+	require.Nil(t, err)
+	require.Contains(t, out, "\"command\": \"ownership-leave\"")
+	require.Contains(t, out, "\"leaving_authors\": \"nobody\"")
+}
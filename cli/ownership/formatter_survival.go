@@ -0,0 +1,89 @@
+package ownership
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/flaviostutz/gitwho/cli"
+	"github.com/flaviostutz/gitwho/ownership"
+	"github.com/rodaine/table"
+)
+
+// FormatSurvivalResults shows the survival curve of all the lines and of each author.
+// If full, the lines that survived from each cohort are shown too
+func FormatSurvivalResults(result ownership.SurvivalResult, full bool) string {
+	text := fmt.Sprintf("\nSnapshots: %d (each %s)\n", len(result.Snapshots), result.Period)
+	if len(result.Snapshots) > 0 {
+		text += fmt.Sprintf("From %s to %s\n", result.Snapshots[0].Date.Format("2006-01-02"), result.Snapshots[len(result.Snapshots)-1].Date.Format("2006-01-02"))
+	}
+	text += fmt.Sprintf("Lines written: %d\n", result.Total.Lines)
+	if result.Total.Lines == 0 {
+		return text
+	}
+	text += fmt.Sprintf("Half-life: %s\n", halfLifeStr(result.Total))
+	text += fmt.Sprintf("Survival after each period: %s\n", survivalStr(result.Total.Survival))
+
+	text += "\nAuthors:\n"
+	for _, curve := range result.Authors {
+		text += fmt.Sprintf("  %s %s: %d lines, half-life %s\n", curve.AuthorName, curve.AuthorMail, curve.Lines, halfLifeStr(curve))
+		if full {
+			text += fmt.Sprintf("    survival: %s\n", survivalStr(curve.Survival))
+		}
+	}
+
+	if full {
+		text += formatSurvivalCohorts(result.Total.Cohorts)
+	}
+	return text
+}
+
+// FormatSurvivalResultsJSON formats survival results as a versioned JSON document
+func FormatSurvivalResultsJSON(result ownership.SurvivalResult, opts ownership.OwnershipTimeseriesOptions) (string, error) {
+	return cli.FormatJSON("survival", opts, result)
+}
+
+// formatSurvivalCohorts table with the percentage of the lines of each cohort that
+// survived after each number of periods
+func formatSurvivalCohorts(cohorts []ownership.SurvivalCohort) string {
+	maxPeriods := 0
+	for _, cohort := range cohorts {
+		if len(cohort.Surviving) > maxPeriods {
+			maxPeriods = len(cohort.Surviving)
+		}
+	}
+
+	header := []interface{}{"Written until", "Lines"}
+	for t := 1; t < maxPeriods; t++ {
+		header = append(header, fmt.Sprintf("+%d", t))
+	}
+	tblWriter := bytes.NewBufferString("")
+	tbl := table.New(header...)
+	tbl.WithWriter(tblWriter)
+	for _, cohort := range cohorts {
+		row := []interface{}{cohort.Until.Format("2006-01-02"), fmt.Sprintf("%d", cohort.Lines)}
+		for _, surviving := range cohort.Surviving[1:] {
+			row = append(row, fmt.Sprintf("%d%%", 100*surviving/cohort.Lines))
+		}
+		tbl.AddRow(row...)
+	}
+	tbl.Print()
+
+	text := "\nLines surviving after each period since they were written:\n"
+	return text + tblWriter.String()
+}
+
+func halfLifeStr(curve ownership.SurvivalCurve) string {
+	if curve.HalfLife == 0 {
+		return fmt.Sprintf("not reached in %d periods", len(curve.Survival)-1)
+	}
+	return fmt.Sprintf("%d periods", curve.HalfLife)
+}
+
+func survivalStr(survival []float64) string {
+	values := make([]string, 0)
+	for _, value := range survival {
+		values = append(values, fmt.Sprintf("%.0f%%", 100*value))
+	}
+	return strings.Join(values, " ")
+}
@@ -0,0 +1,48 @@
+package ownership
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/gitwho/ownership"
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatSurvival(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	cohorts := []ownership.SurvivalCohort{
+		{Since: start, Until: start.AddDate(0, 1, 0), Lines: 10, Surviving: []int{10, 6, 3}},
+		{Since: start.AddDate(0, 1, 0), Until: start.AddDate(0, 2, 0), Lines: 4, Surviving: []int{4, 4}},
+	}
+	result := ownership.SurvivalResult{
+		Period: "1 month",
+		Snapshots: []utils.CommitInfo{
+			{Date: start}, {Date: start.AddDate(0, 1, 0)}, {Date: start.AddDate(0, 2, 0)}, {Date: start.AddDate(0, 3, 0)},
+		},
+		Total: ownership.SurvivalCurve{Lines: 14, Survival: []float64{1, 0.714, 0.357}, HalfLife: 2, Cohorts: cohorts},
+		Authors: []ownership.SurvivalCurve{
+			{AuthorName: "author1", AuthorMail: "<author1@mail.com>", Lines: 10, Survival: []float64{1, 0.6, 0.3}, HalfLife: 2, Cohorts: cohorts[:1]},
+			{AuthorName: "author2", AuthorMail: "<author2@mail.com>", Lines: 4, Survival: []float64{1, 1}, Cohorts: cohorts[1:]},
+		},
+	}
+
+	out := FormatSurvivalResults(result, false)
+	require.Contains(t, out, "Snapshots: 4 (each 1 month)\nFrom 2023-01-01 to 2023-04-01\nLines written: 14\nHalf-life: 2 periods\nSurvival after each period: 100% 71% 36%\n")
+	require.Contains(t, out, "  author1 <author1@mail.com>: 10 lines, half-life 2 periods\n  author2 <author2@mail.com>: 4 lines, half-life not reached in 1 periods\n")
+	require.NotContains(t, out, "Lines surviving")
+
+	out = FormatSurvivalResults(result, true)
+	require.Contains(t, out, "  author1 <author1@mail.com>: 10 lines, half-life 2 periods\n    survival: 100% 60% 30%\n")
+	require.Contains(t, out, "Lines surviving after each period since they were written:\n")
+	require.Regexp(t, "2023-02-01 +10 +60% +30%", out)
+	require.Regexp(t, "2023-03-01 +4 +100%", out)
+
+	out = FormatSurvivalResults(ownership.SurvivalResult{Period: "1 month"}, true)
+	require.Equal(t, "\nSnapshots: 0 (each 1 month)\nLines written: 0\n", out)
+
+	out, err := FormatSurvivalResultsJSON(result, ownership.OwnershipTimeseriesOptions{Period: "1 month"})
+	require.Nil(t, err)
+	require.Contains(t, out, "\"command\": \"survival\"")
+	require.Contains(t, out, "\"half_life\": 2")
+}
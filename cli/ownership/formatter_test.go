@@ -1,6 +1,7 @@
 package ownership
 
 import (
+	"os"
 	"testing"
 
 	"github.com/flaviostutz/gitwho/ownership"
@@ -52,6 +53,30 @@ func TestFormatCodeOwnershipFull(t *testing.T) {
 	require.Contains(t, out, "Total authors: 3\nTotal files: 2\nAvg line age: 0 days\nDuplicated lines: 0")
 }
 
+func TestFormatCodeOwnershipDetectMoves(t *testing.T) {
+	repoDir, err := utils.ResolveTestMovedLinesRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	results, err := ownership.AnalyseOwnership(ownership.OwnershipOptions{
+		BaseOptions: utils.BaseOptions{
+			RepoDir: repoDir,
+			Branch:  "main",
+		},
+		MinDuplicateLines: 2,
+		CommitId:          commit.CommitId,
+		DetectMoves:       true,
+	}, nil)
+	require.Nil(t, err)
+
+	out, err := FormatCodeOwnershipResults(results, true)
+	require.Nil(t, err)
+	require.Contains(t, out, "Lines moved from other files: 3 (60%)\n")
+	require.Contains(t, out, " moved:3\n")
+}
+
 func TestFormatDuplicatesFull(t *testing.T) {
 	repoDir, err := utils.ResolveTestOwnershipRepo()
 	require.Nil(t, err)
@@ -98,3 +123,69 @@ func TestFormatCodeOwnershipResultsCSV(t *testing.T) {
 	require.Contains(t, csvData, "author2;<author2@mail.com>;1;0.00;0;0;0")
 	require.Contains(t, csvData, "author1;<author1@mail.com>;1;0.00;0;0;0")
 }
+
+func TestFormatCodeOwnershipResultsJSON(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	opts := ownership.OwnershipOptions{
+		BaseOptions: utils.BaseOptions{
+			RepoDir: repoDir,
+			Branch:  "main",
+		},
+		MinDuplicateLines: 2,
+		CommitId:          commit.CommitId,
+	}
+	results, err := ownership.AnalyseOwnership(opts, nil)
+	require.Nil(t, err)
+
+	out, err := FormatCodeOwnershipResultsJSON(results, opts)
+	require.Nil(t, err)
+	require.Contains(t, out, "\"schema_version\": 1")
+	require.Contains(t, out, "\"command\": \"ownership\"")
+	require.Contains(t, out, "\"total_lines\": 7")
+	require.Contains(t, out, "\"author_name\": \"author3\"")
+
+	out, err = FormatDuplicatesResultsJSON(results, opts)
+	require.Nil(t, err)
+	require.Contains(t, out, "\"command\": \"duplicates\"")
+	require.Contains(t, out, "\"total_lines_duplicated\": 0")
+	require.Contains(t, out, "\"duplicate_line_groups\": []")
+}
+
+func TestFormatCodeOwnershipResultsTeams(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	teamsFile := t.TempDir() + "/teams.yml"
+	err = os.WriteFile(teamsFile, []byte("squad-a:\n  - author1\n  - author2\n"), 0644)
+	require.Nil(t, err)
+
+	results, err := ownership.AnalyseOwnership(ownership.OwnershipOptions{
+		BaseOptions: utils.BaseOptions{
+			RepoDir:   repoDir,
+			Branch:    "main",
+			TeamsFile: teamsFile,
+		},
+		MinDuplicateLines: 2,
+		CommitId:          commit.CommitId,
+	}, nil)
+	require.Nil(t, err)
+
+	out, err := FormatCodeOwnershipResults(results, false)
+	require.Nil(t, err)
+	require.Contains(t, out, "Total teams: 2\n  (no team): 5 (71.4%)\n  squad-a: 2 (28.6%)\n")
+
+	csvData, err := FormatCodeOwnershipResultsCSV(results)
+	require.Nil(t, err)
+	require.Contains(t, csvData, "OwnedLinesDuplicateOriginalOthers;Team\n")
+	require.Contains(t, csvData, "author3;<author3@mail.com>;5;0.00;0;0;0;(no team)\n")
+	require.Contains(t, csvData, "author1;<author1@mail.com>;1;0.00;0;0;0;squad-a\n")
+	require.Contains(t, csvData, ";;2;0.00;0;0;0;squad-a\n")
+}
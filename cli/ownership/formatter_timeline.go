@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/flaviostutz/gitwho/cli"
 	"github.com/flaviostutz/gitwho/ownership"
 	"github.com/flaviostutz/gitwho/utils"
 	"github.com/rodaine/table"
@@ -52,6 +53,11 @@ func FormatTimeseriesOwnershipResults(oresults []ownership.OwnershipResult, full
 	return str, nil
 }
 
+// FormatTimeseriesOwnershipResultsJSON formats ownership timeseries results as a versioned JSON document
+func FormatTimeseriesOwnershipResultsJSON(oresults []ownership.OwnershipResult, opts ownership.OwnershipTimeseriesOptions) (string, error) {
+	return cli.FormatJSON("ownership-timeseries", opts, oresults)
+}
+
 func formatAuthorsTimeseries(ownershipResults []ownership.OwnershipResult) string {
 
 	str := ""
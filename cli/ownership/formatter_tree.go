@@ -0,0 +1,48 @@
+package ownership
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flaviostutz/gitwho/cli"
+	"github.com/flaviostutz/gitwho/ownership"
+)
+
+// FormatOwnershipTreeResults shows the top owners of each directory of the tree
+func FormatOwnershipTreeResults(tree *ownership.OwnershipNode, full bool) string {
+	return formatOwnershipNode(tree, 0, full)
+}
+
+// FormatOwnershipTreeResultsJSON formats the ownership tree as a versioned JSON document
+func FormatOwnershipTreeResultsJSON(tree *ownership.OwnershipNode, opts ownership.OwnershipTreeOptions) (string, error) {
+	return cli.FormatJSON("ownership-tree", opts, tree)
+}
+
+func formatOwnershipNode(node *ownership.OwnershipNode, level int, full bool) string {
+	name := node.Name
+	if !node.IsFile && level > 0 {
+		name += "/"
+	}
+
+	maxOwners := 3
+	details := fmt.Sprintf("%d lines", node.TotalLines)
+	if full {
+		maxOwners = 5
+		details = fmt.Sprintf("%d files, %d lines, avg-days:%d", node.TotalFiles, node.TotalLines, int(node.AvgLineAgeDays()))
+	}
+
+	owners := make([]string, 0)
+	for i := 0; i < len(node.AuthorsLines) && i < maxOwners; i++ {
+		authorLines := node.AuthorsLines[i]
+		owners = append(owners, fmt.Sprintf("%s %.1f%%", authorLines.AuthorName, 100*float64(authorLines.OwnedLinesTotal)/float64(node.TotalLines)))
+	}
+	if len(node.AuthorsLines) > maxOwners {
+		owners = append(owners, fmt.Sprintf("(+%d)", len(node.AuthorsLines)-maxOwners))
+	}
+
+	text := fmt.Sprintf("%s%s (%s): %s\n", strings.Repeat("  ", level), name, details, strings.Join(owners, ", "))
+	for _, child := range node.Children {
+		text += formatOwnershipNode(child, level+1, full)
+	}
+	return text
+}
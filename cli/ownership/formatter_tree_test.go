@@ -0,0 +1,47 @@
+package ownership
+
+import (
+	"testing"
+
+	"github.com/flaviostutz/gitwho/ownership"
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatOwnershipTree(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	opts := ownership.OwnershipTreeOptions{
+		OwnershipOptions: ownership.OwnershipOptions{
+			BaseOptions: utils.BaseOptions{
+				RepoDir: repoDir,
+				Branch:  "main",
+			},
+			MinDuplicateLines: 2,
+			CommitId:          commit.CommitId,
+		},
+		IncludeFiles: true,
+	}
+	tree, err := ownership.AnalyseOwnershipTree(opts, nil)
+	require.Nil(t, err)
+
+	out := FormatOwnershipTreeResults(tree, false)
+	require.Equal(t, `. (7 lines): author3 71.4%, author1 14.3%, author2 14.3%
+  dir1/ (5 lines): author3 100.0%
+    dir1.1/ (5 lines): author3 100.0%
+      file2 (5 lines): author3 100.0%
+  file1 (2 lines): author1 50.0%, author2 50.0%
+`, out)
+
+	out = FormatOwnershipTreeResults(tree, true)
+	require.Contains(t, out, ". (2 files, 7 lines, avg-days:0): author3 71.4%")
+
+	out, err = FormatOwnershipTreeResultsJSON(tree, opts)
+	require.Nil(t, err)
+	require.Contains(t, out, "\"command\": \"ownership-tree\"")
+	require.Contains(t, out, "\"path\": \"dir1/dir1.1/file2\"")
+}
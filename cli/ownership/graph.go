@@ -214,6 +214,37 @@ func ServeOwnership(ownershipResult ownership.OwnershipResult, ownershipOpts own
 	page.SetLayout(components.PageFlexLayout)
 	page.AddCharts(pie)
 
+	if len(ownershipResult.TeamsLines) > 0 {
+		teamItems := make([]opts.PieData, 0)
+		for _, teamLines := range ownershipResult.TeamsLines {
+			teamItems = append(teamItems, opts.PieData{Name: teamLines.TeamName, Value: teamLines.OwnedLinesTotal})
+		}
+		teamsPie := charts.NewPie()
+		teamsPie.SetGlobalOptions(
+			charts.WithInitializationOpts(opts.Initialization{Theme: types.ThemeShine}),
+			charts.WithTitleOpts(opts.Title{
+				Title: "Ownership per Team",
+			}),
+			charts.WithTooltipOpts(opts.Tooltip{
+				Trigger: "axis",
+				Show:    true,
+			}),
+			charts.WithLegendOpts(opts.Legend{
+				Show: true,
+				Type: "scroll",
+				Top:  "23px",
+			}),
+		)
+		teamsPie.AddSeries("pie", teamItems).
+			SetSeriesOptions(charts.WithLabelOpts(
+				opts.Label{
+					Show:      true,
+					Formatter: "{b}: {c}",
+				}),
+			)
+		page.AddCharts(teamsPie)
+	}
+
 	info := "<pre style=\"display:flex;justify-content:center\"><code>"
 	info += utils.BaseOptsStr(ownershipOpts.BaseOptions)
 	info += ownershipOptsStr(ownershipOpts)
@@ -235,11 +266,17 @@ func ownershipTimeseriesOptsStr(opts ownership.OwnershipTimeseriesOptions) strin
 	str += utils.AttrStr("until", opts.Until)
 	str += utils.AttrStr("period", opts.Period)
 	str += utils.AttrStr("min-duplicate", fmt.Sprintf("%d", opts.MinDuplicateLines))
+	if opts.DetectMoves {
+		str += utils.AttrStr("detect-moves", "true")
+	}
 	return str
 }
 
 func ownershipOptsStr(opts ownership.OwnershipOptions) string {
 	str := utils.AttrStr("commit-id", opts.CommitId)
 	str += utils.AttrStr("min-duplicate", fmt.Sprintf("%d", opts.MinDuplicateLines))
+	if opts.DetectMoves {
+		str += utils.AttrStr("detect-moves", "true")
+	}
 	return str
 }
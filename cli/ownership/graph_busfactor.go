@@ -0,0 +1,73 @@
+package ownership
+
+import (
+	"github.com/flaviostutz/gitwho/cli"
+	"github.com/flaviostutz/gitwho/ownership"
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/components"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/go-echarts/go-echarts/v2/types"
+)
+
+// ServeBusFactor Start server with a web page with the bus factor of the
+// top level directories and returns the random URL generated for the page
+func ServeBusFactor(result ownership.BusFactorResult, busFactorOpts ownership.BusFactorOptions) (string, error) {
+	dirsX := make([]string, 0)
+	busFactorValues := make([]opts.BarData, 0)
+	activeValues := make([]opts.BarData, 0)
+	inactiveValues := make([]opts.BarData, 0)
+	for _, node := range result.Root.Children {
+		dirsX = append(dirsX, node.Path)
+		busFactorValues = append(busFactorValues, opts.BarData{Value: node.BusFactor})
+		activeValues = append(activeValues, opts.BarData{Value: node.TotalLines - node.InactiveLines})
+		inactiveValues = append(inactiveValues, opts.BarData{Value: node.InactiveLines})
+	}
+
+	barBusFactor := charts.NewBar()
+	barBusFactor.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{Theme: types.ThemeShine}),
+		charts.WithTitleOpts(opts.Title{
+			Title: "Bus Factor per Directory",
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{
+			Trigger: "axis",
+			Show:    true,
+		}),
+	)
+	barBusFactor.SetXAxis(dirsX).
+		AddSeries("Bus factor", busFactorValues)
+
+	barLines := charts.NewBar()
+	barLines.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{Theme: types.ThemeShine}),
+		charts.WithTitleOpts(opts.Title{
+			Title: "Lines Owned by Active Authors",
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{
+			Trigger: "axis",
+			Show:    true,
+		}),
+		charts.WithLegendOpts(opts.Legend{
+			Show: true,
+			Top:  "23px",
+		}),
+	)
+	barLines.SetXAxis(dirsX).
+		AddSeries("Active", activeValues, charts.WithBarChartOpts(opts.BarChart{Stack: "lines"})).
+		AddSeries("Inactive", inactiveValues, charts.WithBarChartOpts(opts.BarChart{Stack: "lines"}))
+
+	page := components.NewPage()
+	page.SetLayout(components.PageFlexLayout)
+	page.AddCharts(barBusFactor, barLines)
+
+	info := "<pre style=\"display:flex;justify-content:center\"><code>"
+	info += utils.BaseOptsStr(busFactorOpts.BaseOptions)
+	info += ownershipOptsStr(busFactorOpts.OwnershipOptions)
+	info += utils.AttrStr("active-since", busFactorOpts.ActiveSince)
+	info += FormatBusFactorResults(result, busFactorOpts, true)
+	info += "</code></pre>"
+
+	url, _ := cli.ServeGraphPage(page, info)
+	return url, nil
+}
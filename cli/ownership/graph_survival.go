@@ -0,0 +1,73 @@
+package ownership
+
+import (
+	"fmt"
+
+	"github.com/flaviostutz/gitwho/cli"
+	"github.com/flaviostutz/gitwho/ownership"
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/components"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/go-echarts/go-echarts/v2/types"
+)
+
+// ServeSurvival Start server with a web page with the survival curves of
+// the lines and returns the random URL generated for the page
+func ServeSurvival(result ownership.SurvivalResult, survivalOpts ownership.OwnershipTimeseriesOptions) (string, error) {
+	curves := []ownership.SurvivalCurve{result.Total}
+	for i := 0; i < len(result.Authors) && i < 10; i++ {
+		curves = append(curves, result.Authors[i])
+	}
+
+	periodsX := make([]string, 0)
+	for t := range result.Total.Survival {
+		periodsX = append(periodsX, fmt.Sprintf("%d", t))
+	}
+
+	lineSurvival := charts.NewLine()
+	lineSurvival.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{Theme: types.ThemeShine}),
+		charts.WithTitleOpts(opts.Title{
+			Title:    "Line Survival",
+			Subtitle: fmt.Sprintf("%% of lines that survive after each period of %s", survivalOpts.Period),
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{
+			Trigger: "axis",
+			Show:    true,
+		}),
+		charts.WithLegendOpts(opts.Legend{
+			Show: true,
+			Top:  "40px",
+		}),
+		charts.WithYAxisOpts(opts.YAxis{
+			Min: 0,
+			Max: 100,
+		}),
+	)
+	lineSurvival.SetXAxis(periodsX)
+	for i, curve := range curves {
+		name := curve.AuthorName
+		if i == 0 {
+			name = "All authors"
+		}
+		values := make([]opts.LineData, 0)
+		for _, survival := range curve.Survival {
+			values = append(values, opts.LineData{Value: 100 * survival})
+		}
+		lineSurvival.AddSeries(name, values, charts.WithLineChartOpts(opts.LineChart{Step: "end"}))
+	}
+
+	page := components.NewPage()
+	page.SetLayout(components.PageFlexLayout)
+	page.AddCharts(lineSurvival)
+
+	info := "<pre style=\"display:flex;justify-content:center\"><code>"
+	info += utils.BaseOptsStr(survivalOpts.BaseOptions)
+	info += ownershipTimeseriesOptsStr(survivalOpts)
+	info += FormatSurvivalResults(result, true)
+	info += "</code></pre>"
+
+	url, _ := cli.ServeGraphPage(page, info)
+	return url, nil
+}
@@ -0,0 +1,106 @@
+package ownership
+
+import (
+	"fmt"
+
+	"github.com/flaviostutz/gitwho/cli"
+	"github.com/flaviostutz/gitwho/ownership"
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/components"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/go-echarts/go-echarts/v2/types"
+)
+
+// ServeOwnershipTree Start server with a web page with a treemap and a sunburst
+// of the ownership per directory and returns the random URL generated for the page
+func ServeOwnershipTree(tree *ownership.OwnershipNode, treeOpts ownership.OwnershipTreeOptions) (string, error) {
+	treemap := charts.NewTreeMap()
+	treemap.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{
+			Theme:  types.ThemeShine,
+			Width:  "1200px",
+			Height: "700px",
+		}),
+		charts.WithTitleOpts(opts.Title{
+			Title: "Ownership per Directory",
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{
+			Show: true,
+		}),
+		charts.WithLegendOpts(opts.Legend{
+			Show: false,
+		}),
+	)
+	treemap.AddSeries("ownership", treeMapNodes(tree).Children)
+
+	sunburst := charts.NewSunburst()
+	sunburst.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{
+			Theme:  types.ThemeShine,
+			Width:  "900px",
+			Height: "900px",
+		}),
+		charts.WithTitleOpts(opts.Title{
+			Title: "Ownership Sunburst",
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{
+			Show: true,
+		}),
+	)
+	sunburstData := make([]opts.SunBurstData, 0)
+	for _, child := range sunburstNodes(tree).Children {
+		sunburstData = append(sunburstData, *child)
+	}
+	sunburst.AddSeries("ownership", sunburstData).
+		SetSeriesOptions(charts.WithLabelOpts(
+			opts.Label{
+				Show:      true,
+				Formatter: "{b}",
+			}),
+		)
+
+	page := components.NewPage()
+	page.SetLayout(components.PageFlexLayout)
+	page.AddCharts(treemap, sunburst)
+
+	info := "<pre style=\"display:flex;justify-content:center\"><code>"
+	info += utils.BaseOptsStr(treeOpts.BaseOptions)
+	info += ownershipOptsStr(treeOpts.OwnershipOptions)
+	info += utils.AttrStr("depth", fmt.Sprintf("%d", treeOpts.MaxDepth))
+	info += FormatOwnershipTreeResults(tree, true)
+	info += "</code></pre>"
+
+	url, _ := cli.ServeGraphPage(page, info)
+	return url, nil
+}
+
+// treeMapNodes converts the ownership tree to treemap nodes. The leaves
+// of the tree are split in the lines owned by each author
+func treeMapNodes(node *ownership.OwnershipNode) opts.TreeMapNode {
+	tnode := opts.TreeMapNode{Name: node.Name, Value: node.TotalLines, Children: make([]opts.TreeMapNode, 0)}
+	if len(node.Children) == 0 {
+		for _, authorLines := range node.AuthorsLines {
+			tnode.Children = append(tnode.Children, opts.TreeMapNode{Name: authorLines.AuthorName, Value: authorLines.OwnedLinesTotal})
+		}
+		return tnode
+	}
+	for _, child := range node.Children {
+		tnode.Children = append(tnode.Children, treeMapNodes(child))
+	}
+	return tnode
+}
+
+func sunburstNodes(node *ownership.OwnershipNode) *opts.SunBurstData {
+	snode := &opts.SunBurstData{Name: node.Name, Value: float64(node.TotalLines), Children: make([]*opts.SunBurstData, 0)}
+	if len(node.Children) == 0 {
+		for _, authorLines := range node.AuthorsLines {
+			snode.Children = append(snode.Children, &opts.SunBurstData{Name: authorLines.AuthorName, Value: float64(authorLines.OwnedLinesTotal)})
+		}
+		return snode
+	}
+	for _, child := range node.Children {
+		snode.Children = append(snode.Children, sunburstNodes(child))
+	}
+	return snode
+}
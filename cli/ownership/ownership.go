@@ -11,37 +11,54 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func RunOwnership(osArgs []string) {
-	opts := ownership.OwnershipOptions{}
-	cliOpts := cli.CliOpts{}
-	when := ""
+// ownershipFlags defines the flags of the ownership command
+func ownershipFlags(opts *ownership.OwnershipOptions, cliOpts *cli.CliOpts, when *string, leaveOpts *ownership.LeaveOptions) *flag.FlagSet {
 	flags := flag.NewFlagSet("ownership", flag.ExitOnError)
-	flags.StringVar(&opts.RepoDir, "repo", ".", "Repository path to analyse")
-	flags.StringVar(&opts.Branch, "branch", "main", "Branch name to analyse")
-	flags.StringVar(&opts.FilesRegex, "files", ".*", "Regex for selecting which file paths to include in analysis")
-	flags.StringVar(&opts.FilesNotRegex, "files-not", "", "Regex for filtering out files from analysis")
-	flags.StringVar(&opts.AuthorsRegex, "authors", ".*", "Regex for selecting which authors to include in analysis")
-	flags.StringVar(&opts.AuthorsNotRegex, "authors-not", "", "Regex for filtering out authors from analysis")
-	flags.StringVar(&opts.CacheFile, "cache-file", "", "If defined, stores results in a cache file that can be used in subsequent calls that uses the same parameters.")
-	flags.IntVar(&opts.CacheTTLSeconds, "cache-ttl", 5184000, "Time in seconds for old items in cache file to be deleted. Defaults to 2 months")
+	cli.AddBaseFlags(flags, &opts.BaseOptions)
+	flags.StringVar(&opts.TeamsFile, "teams", "", "YAML file mapping team names to lists of author regexes (name or email). Results are also aggregated per team")
 	flags.IntVar(&opts.MinDuplicateLines, "min-dup-lines", 4, "Min number of similar lines in a row to be considered a duplicate")
-	flags.StringVar(&when, "when", "now", "Date to do analysis in repo")
-	flags.StringVar(&cliOpts.Format, "format", "full", "Output format. 'full' (more details), 'short' (lines per author), 'graph' (open browser), or 'csv' (CSV format)")
+	flags.BoolVar(&opts.DetectMoves, "detect-moves", false, "Detect lines moved or copied between files (git blame -M -C) so that they keep their original author")
+	flags.StringVar(when, "when", "now", "Date to do analysis in repo")
+	flags.StringVar(&leaveOpts.LeavingAuthors, "simulate-leave", "", "Regex of the names or emails of the authors to simulate leaving. Shows the orphaned lines, the areas that would drop below --leave-threshold and the best successors for them")
+	flags.Float64Var(&leaveOpts.MinActiveShare, "leave-threshold", 50, "Min percentage of the lines of an area that should be owned by active authors. Used with --simulate-leave")
+	flags.IntVar(&leaveOpts.MaxDepth, "leave-depth", 3, "Max number of directory levels to simulate leave. Use 0 for unlimited. Used with --simulate-leave")
+	flags.StringVar(&leaveOpts.ActiveSince, "active-since", "6 months ago", "Authors without commits since this date are considered inactive and commits since this date are used to rank successors. Used with --simulate-leave")
+	flags.IntVar(&leaveOpts.MaxSuccessors, "successors", 3, "Max number of successors shown for each area. Used with --simulate-leave")
+	flags.StringVar(&cliOpts.Format, "format", "full", "Output format. 'full' (more details), 'short' (lines per author), 'graph' (open browser), 'csv' (CSV format) or 'json' (JSON document)")
 	flags.StringVar(&cliOpts.GoProfileFile, "profile-file", "", "Profile file to dump golang runtime data to")
 	flags.BoolVar(&cliOpts.Verbose, "verbose", false, "Show verbose logs during processing")
+	return flags
+}
 
-	flags.Parse(osArgs[2:])
+func RunOwnership(osArgs []string) {
+	opts := ownership.OwnershipOptions{}
+	cliOpts := cli.CliOpts{}
+	when := ""
+	leaveOpts := ownership.LeaveOptions{}
+	flags := ownershipFlags(&opts, &cliOpts, &when, &leaveOpts)
+	err := cli.ParseFlags(flags, osArgs[2:], &opts.BaseOptions)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
-	progressChan := cli.SetupBasic(cliOpts)
+	progressChan := cli.SetupBasic(cliOpts, opts.BaseOptions)
 	defer close(progressChan)
 
-	commit, err := utils.ExecGetLastestCommit(opts.RepoDir, opts.Branch, "", when)
+	commit, err := cli.LatestCommit(opts.BaseOptions, when)
 	if err != nil {
 		fmt.Printf("Branch %s not found\n", opts.Branch)
 		os.Exit(1)
 	}
 	opts.CommitId = commit.CommitId
 
+	if leaveOpts.LeavingAuthors != "" {
+		leaveOpts.OwnershipOptions = opts
+		leaveOpts.IncludeFiles = true
+		runLeave(leaveOpts, cliOpts, progressChan)
+		return
+	}
+
 	logrus.Debugf("Starting analysis of code ownership. commitId=%s", opts.CommitId)
 	ownershipResult, err := ownership.AnalyseOwnership(opts, progressChan)
 	if err != nil {
@@ -83,5 +100,42 @@ func RunOwnership(osArgs []string) {
 			fmt.Printf("Couldn't format results as CSV. err=%s", err)
 		}
 		fmt.Println(output)
+
+	case "json":
+		output, err := FormatCodeOwnershipResultsJSON(ownershipResult, opts)
+		if err != nil {
+			fmt.Printf("Couldn't format results as JSON. err=%s\n", err)
+			os.Exit(4)
+		}
+		fmt.Println(output)
+	}
+}
+
+func runLeave(opts ownership.LeaveOptions, cliOpts cli.CliOpts, progressChan chan<- utils.ProgressInfo) {
+	logrus.Debugf("Starting leave simulation. commitId=%s leavingAuthors=%s", opts.CommitId, opts.LeavingAuthors)
+	result, err := ownership.AnalyseLeave(opts, progressChan)
+	if err != nil {
+		fmt.Println("Failed to perform leave simulation. err=", err)
+		os.Exit(2)
+	}
+
+	switch cliOpts.Format {
+	case "full":
+		fmt.Println(FormatLeaveResults(result, opts, true))
+
+	case "short":
+		fmt.Println(FormatLeaveResults(result, opts, false))
+
+	case "json":
+		output, err := FormatLeaveResultsJSON(result, opts)
+		if err != nil {
+			fmt.Printf("Couldn't format results as JSON. err=%s\n", err)
+			os.Exit(4)
+		}
+		fmt.Println(output)
+
+	default:
+		fmt.Printf("format '%s' is not supported with --simulate-leave\n", cliOpts.Format)
+		os.Exit(3)
 	}
 }
@@ -11,33 +11,37 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func RunOwnershipTimeseries(osArgs []string) {
-	opts := ownership.OwnershipTimeseriesOptions{}
-	cliOpts := cli.CliOpts{}
-
+// ownershipTimeseriesFlags defines the flags of the ownership-timeseries command
+func ownershipTimeseriesFlags(opts *ownership.OwnershipTimeseriesOptions, cliOpts *cli.CliOpts) *flag.FlagSet {
 	flags := flag.NewFlagSet("ownership-timeseries", flag.ExitOnError)
-	flags.StringVar(&opts.RepoDir, "repo", ".", "Repository path to analyse")
-	flags.StringVar(&opts.Branch, "branch", "main", "Branch name to analyse")
-	flags.StringVar(&opts.FilesRegex, "files", ".*", "Regex for selecting which file paths to include in analysis")
-	flags.StringVar(&opts.FilesNotRegex, "files-not", "", "Regex for filtering out files from analysis")
-	flags.StringVar(&opts.AuthorsRegex, "authors", ".*", "Regex for selecting which authors to include in analysis")
-	flags.StringVar(&opts.AuthorsNotRegex, "authors-not", "", "Regex for filtering out authors from analysis")
-	flags.StringVar(&opts.CacheFile, "cache-file", "", "If defined, stores results in a cache file that can be used in subsequent calls that uses the same parameters.")
-	flags.IntVar(&opts.CacheTTLSeconds, "cache-ttl", 5184000, "Time in seconds for old items in cache file to be deleted. Defaults to 2 months")
+	cli.AddBaseFlags(flags, &opts.BaseOptions)
+	flags.StringVar(&opts.TeamsFile, "teams", "", "YAML file mapping team names to lists of author regexes (name or email). Results are also aggregated per team")
 	flags.StringVar(&opts.Since, "since", "3 months ago", "Starting date for historical analysis. Eg: '1 year ago'")
 	flags.StringVar(&opts.Until, "until", "now", "Ending date for historical analysis. Eg: 'now'")
 	flags.StringVar(&opts.Period, "period", "2 weeks", "Show ownership data each [period] in the range [since]-[until]. Eg.: '7 days', '1 month'")
 	flags.IntVar(&opts.MinDuplicateLines, "min-dup-lines", 4, "Min number of similar lines in a row to be considered a duplicate")
-	flags.StringVar(&cliOpts.Format, "format", "full", "Output format. 'full' (more details) or 'short' (lines per author)")
+	flags.BoolVar(&opts.DetectMoves, "detect-moves", false, "Detect lines moved or copied between files (git blame -M -C) so that they keep their original author")
+	flags.StringVar(&cliOpts.Format, "format", "full", "Output format. 'full' (more details), 'short' (lines per author), 'graph' (open browser) or 'json' (JSON document)")
 	flags.StringVar(&cliOpts.GoProfileFile, "profile-file", "", "Profile file to dump golang runtime data to")
 	flags.BoolVar(&cliOpts.Verbose, "verbose", false, "Show verbose logs during processing")
+	return flags
+}
 
-	flags.Parse(osArgs[2:])
+func RunOwnershipTimeseries(osArgs []string) {
+	opts := ownership.OwnershipTimeseriesOptions{}
+	cliOpts := cli.CliOpts{}
 
-	progressChan := cli.SetupBasic(cliOpts)
+	flags := ownershipTimeseriesFlags(&opts, &cliOpts)
+	err := cli.ParseFlags(flags, osArgs[2:], &opts.BaseOptions)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	progressChan := cli.SetupBasic(cliOpts, opts.BaseOptions)
 	defer close(progressChan)
 
-	_, err := utils.ExecGetCommitsInDateRange(opts.RepoDir, opts.Branch, "", "")
+	_, err = cli.LatestCommit(opts.BaseOptions, "")
 	if err != nil {
 		fmt.Printf("Branch %s not found\n", opts.Branch)
 		os.Exit(1)
@@ -77,5 +81,13 @@ func RunOwnershipTimeseries(osArgs []string) {
 		}
 		fmt.Printf("\nServing graph at %s\n", url)
 		select {}
+
+	case "json":
+		str, err := FormatTimeseriesOwnershipResultsJSON(ownershipResults, opts)
+		if err != nil {
+			fmt.Printf("Couldn't format results as JSON. err=%s\n", err)
+			os.Exit(4)
+		}
+		fmt.Println(str)
 	}
 }
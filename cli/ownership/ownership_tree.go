@@ -0,0 +1,88 @@
+package ownership
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/flaviostutz/gitwho/cli"
+	"github.com/flaviostutz/gitwho/ownership"
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// ownershipTreeFlags defines the flags of the ownership-tree command
+func ownershipTreeFlags(opts *ownership.OwnershipTreeOptions, cliOpts *cli.CliOpts, when *string) *flag.FlagSet {
+	flags := flag.NewFlagSet("ownership-tree", flag.ExitOnError)
+	cli.AddBaseFlags(flags, &opts.BaseOptions)
+	flags.IntVar(&opts.MinDuplicateLines, "min-dup-lines", 4, "Min number of similar lines in a row to be considered a duplicate")
+	flags.IntVar(&opts.MaxDepth, "depth", 3, "Max number of directory levels to show. Use 0 for unlimited")
+	flags.BoolVar(&opts.IncludeFiles, "show-files", false, "Show files as leaves of the tree")
+	flags.StringVar(when, "when", "now", "Date to do analysis in repo")
+	flags.StringVar(&cliOpts.Format, "format", "full", "Output format. 'full' (more details), 'short' (top owners per directory), 'graph' (open browser) or 'json' (JSON document)")
+	flags.StringVar(&cliOpts.GoProfileFile, "profile-file", "", "Profile file to dump golang runtime data to")
+	flags.BoolVar(&cliOpts.Verbose, "verbose", false, "Show verbose logs during processing")
+	return flags
+}
+
+func RunOwnershipTree(osArgs []string) {
+	opts := ownership.OwnershipTreeOptions{}
+	cliOpts := cli.CliOpts{}
+	when := ""
+	flags := ownershipTreeFlags(&opts, &cliOpts, &when)
+	err := cli.ParseFlags(flags, osArgs[2:], &opts.BaseOptions)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	progressChan := cli.SetupBasic(cliOpts, opts.BaseOptions)
+	defer close(progressChan)
+
+	commit, err := cli.LatestCommit(opts.BaseOptions, when)
+	if err != nil {
+		fmt.Printf("Branch %s not found\n", opts.Branch)
+		os.Exit(1)
+	}
+	opts.CommitId = commit.CommitId
+
+	logrus.Debugf("Starting analysis of code ownership tree. commitId=%s", opts.CommitId)
+	tree, err := ownership.AnalyseOwnershipTree(opts, progressChan)
+	if err != nil {
+		fmt.Println("Failed to perform ownership analysis. err=", err)
+		os.Exit(2)
+	}
+
+	switch cliOpts.Format {
+	case "full":
+		fmt.Println(FormatOwnershipTreeResults(tree, true))
+
+	case "short":
+		fmt.Println(FormatOwnershipTreeResults(tree, false))
+
+	case "graph":
+		url, err := ServeOwnershipTree(tree, opts)
+		if err != nil {
+			fmt.Printf("Couldn't format results. err=%s\n", err)
+			os.Exit(4)
+		}
+		_, err = utils.ExecShellf("", "open %s", url)
+		if err != nil {
+			fmt.Printf("Couldn't open browser automatically. See results at %s\n", url)
+		}
+		fmt.Printf("\nServing graph at %s\n", url)
+		select {}
+
+	case "csv":
+		fmt.Printf("format 'csv' is not supported\n")
+		os.Exit(3)
+
+	case "json":
+		output, err := FormatOwnershipTreeResultsJSON(tree, opts)
+		if err != nil {
+			fmt.Printf("Couldn't format results as JSON. err=%s\n", err)
+			os.Exit(4)
+		}
+		fmt.Println(output)
+	}
+}
@@ -0,0 +1,88 @@
+package ownership
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/flaviostutz/gitwho/cli"
+	"github.com/flaviostutz/gitwho/ownership"
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// survivalFlags defines the flags of the survival command
+func survivalFlags(opts *ownership.OwnershipTimeseriesOptions, cliOpts *cli.CliOpts) *flag.FlagSet {
+	flags := flag.NewFlagSet("survival", flag.ExitOnError)
+	cli.AddBaseFlags(flags, &opts.BaseOptions)
+	flags.StringVar(&opts.Since, "since", "1 year ago", "Starting date for survival analysis. Eg: '2 years ago'")
+	flags.StringVar(&opts.Until, "until", "now", "Ending date for survival analysis. Eg: 'now'")
+	flags.StringVar(&opts.Period, "period", "1 month", "Lines are grouped by the [period] in which they were written and checked again each [period] in the range [since]-[until]. Eg.: '2 weeks', '3 months'")
+	flags.IntVar(&opts.MinDuplicateLines, "min-dup-lines", 4, "Min number of similar lines in a row to be considered a duplicate")
+	flags.BoolVar(&opts.DetectMoves, "detect-moves", false, "Detect lines moved or copied between files (git blame -M -C) so that they keep their original author")
+	flags.StringVar(&cliOpts.Format, "format", "full", "Output format. 'full' (survival per author and per cohort), 'short' (half-life per author), 'graph' (open browser) or 'json' (JSON document)")
+	flags.StringVar(&cliOpts.GoProfileFile, "profile-file", "", "Profile file to dump golang runtime data to")
+	flags.BoolVar(&cliOpts.Verbose, "verbose", false, "Show verbose logs during processing")
+	return flags
+}
+
+func RunSurvival(osArgs []string) {
+	opts := ownership.OwnershipTimeseriesOptions{}
+	cliOpts := cli.CliOpts{}
+
+	flags := survivalFlags(&opts, &cliOpts)
+	err := cli.ParseFlags(flags, osArgs[2:], &opts.BaseOptions)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	progressChan := cli.SetupBasic(cliOpts, opts.BaseOptions)
+	defer close(progressChan)
+
+	_, err = cli.LatestCommit(opts.BaseOptions, "")
+	if err != nil {
+		fmt.Printf("Branch %s not found\n", opts.Branch)
+		os.Exit(1)
+	}
+
+	logrus.Debugf("Starting survival analysis")
+	result, err := ownership.AnalyseSurvival(opts, progressChan)
+	if err != nil {
+		fmt.Println("Failed to perform survival analysis. err=", err)
+		os.Exit(2)
+	}
+
+	switch cliOpts.Format {
+	case "full":
+		fmt.Println(FormatSurvivalResults(result, true))
+
+	case "short":
+		fmt.Println(FormatSurvivalResults(result, false))
+
+	case "graph":
+		url, err := ServeSurvival(result, opts)
+		if err != nil {
+			fmt.Printf("Couldn't format results. err=%s\n", err)
+			os.Exit(4)
+		}
+		_, err = utils.ExecShellf("", "open %s", url)
+		if err != nil {
+			fmt.Printf("Couldn't open browser automatically. See results at %s\n", url)
+		}
+		fmt.Printf("\nServing graph at %s\n", url)
+		select {}
+
+	case "csv":
+		fmt.Printf("format 'csv' is not supported\n")
+		os.Exit(3)
+
+	case "json":
+		output, err := FormatSurvivalResultsJSON(result, opts)
+		if err != nil {
+			fmt.Printf("Couldn't format results as JSON. err=%s\n", err)
+			os.Exit(4)
+		}
+		fmt.Println(output)
+	}
+}
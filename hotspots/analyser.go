@@ -0,0 +1,160 @@
+package hotspots
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/flaviostutz/gitwho/changes"
+	"github.com/flaviostutz/gitwho/ownership"
+	"github.com/flaviostutz/gitwho/utils"
+)
+
+// weights of each factor in the hotspot score. They sum up to 1
+const (
+	weightCommits = 0.35
+	weightChurn   = 0.2
+	weightAuthors = 0.15
+	weightSize    = 0.2
+	weightYouth   = 0.1
+)
+
+type HotspotsOptions struct {
+	utils.BaseOptions
+	SinceDate string `json:"since_date"`
+	UntilDate string `json:"until_date"`
+	// ChurnWindowDays changes to lines younger than this are counted as churn. changes.DefaultChurnWindowDays if zero
+	ChurnWindowDays   int `json:"churn_window_days"`
+	MinDuplicateLines int `json:"min_duplicate_lines"`
+}
+
+// FileHotspot change and ownership stats of a file, combined in a risk score
+type FileHotspot struct {
+	FilePath string `json:"file_path"`
+	// Score from 0 to 100. The higher, the more the file changes, is big, is touched by many authors etc
+	Score float64 `json:"score"`
+	// Commits number of analysed commits that changed the file
+	Commits int `json:"commits"`
+	// LinesTouched new and changed lines in the analysed commits
+	LinesTouched int `json:"lines_touched"`
+	// ChurnRatio share of the lines touched that were churn (changes to lines younger than the churn window)
+	ChurnRatio float64 `json:"churn_ratio"`
+	// Authors number of distinct authors that changed the file in the analysed commits
+	Authors int `json:"authors"`
+	// TotalLines current size of the file
+	TotalLines int `json:"total_lines"`
+	// AgeDaysAvg average age of the current lines of the file
+	AgeDaysAvg float64 `json:"age_days_avg"`
+}
+
+type HotspotsResult struct {
+	SinceCommit utils.CommitInfo `json:"since_commit"`
+	UntilCommit utils.CommitInfo `json:"until_commit"`
+	// Files files changed in the period that still exist, sorted by score (highest first)
+	Files []FileHotspot `json:"files"`
+}
+
+// AnalyseHotspots ranks the files changed in a period by combining how often and by how many
+// authors they were changed (changes analysis) with their current size and age (ownership analysis)
+func AnalyseHotspots(opts HotspotsOptions, progressChan chan<- utils.ProgressInfo) (HotspotsResult, error) {
+	changesResult, err := changes.AnalyseChanges(changes.ChangesOptions{
+		BaseOptions:     opts.BaseOptions,
+		SinceDate:       opts.SinceDate,
+		UntilDate:       opts.UntilDate,
+		ChurnWindowDays: opts.ChurnWindowDays,
+	}, progressChan)
+	if err != nil {
+		return HotspotsResult{}, fmt.Errorf("Couldn't analyse changes. err=%s", err)
+	}
+
+	result := HotspotsResult{
+		SinceCommit: changesResult.SinceCommit,
+		UntilCommit: changesResult.UntilCommit,
+		Files:       make([]FileHotspot, 0),
+	}
+	if changesResult.UntilCommit.CommitId == "" {
+		return result, nil
+	}
+
+	ownershipResult, err := ownership.AnalyseOwnership(ownership.OwnershipOptions{
+		BaseOptions:       opts.BaseOptions,
+		MinDuplicateLines: opts.MinDuplicateLines,
+		CommitId:          changesResult.UntilCommit.CommitId,
+	}, progressChan)
+	if err != nil {
+		return HotspotsResult{}, fmt.Errorf("Couldn't analyse ownership. err=%s", err)
+	}
+
+	result.Files = CalcHotspots(changesResult.FilesLines, ownershipResult.FilesOwnership)
+	return result, nil
+}
+
+// CalcHotspots combines the change stats and the ownership of the files that exist in both.
+// Each factor is normalized by its max value among the files before being weighted
+func CalcHotspots(filesLines []changes.FileLines, filesOwnership []ownership.FileOwnership) []FileHotspot {
+	ownershipMap := make(map[string]ownership.FileOwnership, 0)
+	for _, fileOwnership := range filesOwnership {
+		ownershipMap[fileOwnership.FilePath] = fileOwnership
+	}
+
+	hotspots := make([]FileHotspot, 0)
+	maxCommits, maxAuthors, maxLines, maxAge := 0, 0, 0, 0.0
+	for _, fileLines := range filesLines {
+		fileOwnership, ok := ownershipMap[fileLines.FilePath]
+		if !ok || fileOwnership.TotalLines == 0 {
+			continue
+		}
+		touched := fileLines.LinesTouched.New + fileLines.LinesTouched.Changes
+		hotspot := FileHotspot{
+			FilePath:     fileLines.FilePath,
+			Commits:      fileLines.Commits,
+			LinesTouched: touched,
+			Authors:      fileLines.Authors,
+			TotalLines:   fileOwnership.TotalLines,
+			AgeDaysAvg:   fileOwnership.LinesAgeDaysSum / float64(fileOwnership.TotalLines),
+		}
+		if touched > 0 {
+			hotspot.ChurnRatio = float64(fileLines.LinesTouched.ChurnOwn+fileLines.LinesTouched.ChurnOther) / float64(touched)
+		}
+		if hotspot.Commits > maxCommits {
+			maxCommits = hotspot.Commits
+		}
+		if hotspot.Authors > maxAuthors {
+			maxAuthors = hotspot.Authors
+		}
+		if hotspot.TotalLines > maxLines {
+			maxLines = hotspot.TotalLines
+		}
+		if hotspot.AgeDaysAvg > maxAge {
+			maxAge = hotspot.AgeDaysAvg
+		}
+		hotspots = append(hotspots, hotspot)
+	}
+
+	for i := range hotspots {
+		hotspot := &hotspots[i]
+		score := weightCommits*normalize(float64(hotspot.Commits), float64(maxCommits)) +
+			weightChurn*hotspot.ChurnRatio +
+			weightAuthors*normalize(float64(hotspot.Authors), float64(maxAuthors)) +
+			// size grows in log scale so that a few huge files don't flatten all the others
+			weightSize*normalize(math.Log1p(float64(hotspot.TotalLines)), math.Log1p(float64(maxLines))) +
+			// recently written code is less proven than code that survived for a long time
+			weightYouth*(1-normalize(hotspot.AgeDaysAvg, maxAge))
+		hotspot.Score = math.Round(score*1000) / 10
+	}
+
+	sort.SliceStable(hotspots, func(i, j int) bool {
+		if hotspots[i].Score == hotspots[j].Score {
+			return hotspots[i].FilePath < hotspots[j].FilePath
+		}
+		return hotspots[i].Score > hotspots[j].Score
+	})
+	return hotspots
+}
+
+func normalize(value float64, max float64) float64 {
+	if max == 0 {
+		return 0
+	}
+	return value / max
+}
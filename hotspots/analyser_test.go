@@ -0,0 +1,56 @@
+package hotspots
+
+import (
+	"testing"
+
+	"github.com/flaviostutz/gitwho/changes"
+	"github.com/flaviostutz/gitwho/ownership"
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyseHotspots(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	result, err := AnalyseHotspots(HotspotsOptions{
+		BaseOptions:       utils.BaseOptions{RepoDir: repoDir, Branch: "main", FilesRegex: ".*", AuthorsRegex: ".*"},
+		MinDuplicateLines: 4,
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(result.Files))
+
+	// file1 was changed 4 times by 2 authors. The deleted line is not counted
+	require.Equal(t, "file1", result.Files[0].FilePath)
+	require.Equal(t, 4, result.Files[0].Commits)
+	require.Equal(t, 2, result.Files[0].Authors)
+	require.Equal(t, 5, result.Files[0].LinesTouched)
+	require.True(t, result.Files[0].TotalLines > 0)
+
+	require.Equal(t, "dir1/dir1.1/file2", result.Files[1].FilePath)
+	require.Equal(t, 1, result.Files[1].Commits)
+	require.True(t, result.Files[0].Score > result.Files[1].Score)
+}
+
+func TestCalcHotspots(t *testing.T) {
+	filesLines := []changes.FileLines{
+		{FilePath: "a", Commits: 10, Authors: 4, LinesTouched: changes.LinesTouched{New: 50, Changes: 50, ChurnOther: 100}},
+		{FilePath: "b", Commits: 1, Authors: 1, LinesTouched: changes.LinesTouched{New: 10}},
+		{FilePath: "deleted", Commits: 20, Authors: 5, LinesTouched: changes.LinesTouched{New: 10}},
+	}
+	filesOwnership := []ownership.FileOwnership{
+		{FilePath: "a", TotalLines: 1000, LinesAgeDaysSum: 0},
+		{FilePath: "b", TotalLines: 10, LinesAgeDaysSum: 1000},
+		{FilePath: "untouched", TotalLines: 100},
+	}
+
+	hotspots := CalcHotspots(filesLines, filesOwnership)
+	require.Equal(t, 2, len(hotspots))
+	require.Equal(t, "a", hotspots[0].FilePath)
+	require.Equal(t, 100.0, hotspots[0].Score)
+	require.Equal(t, 1.0, hotspots[0].ChurnRatio)
+
+	require.Equal(t, "b", hotspots[1].FilePath)
+	require.Equal(t, 100.0, hotspots[1].AgeDaysAvg)
+	require.True(t, hotspots[1].Score < 50)
+}
@@ -5,13 +5,15 @@ import (
 	"os"
 
 	cliChanges "github.com/flaviostutz/gitwho/cli/changes"
+	cliConfig "github.com/flaviostutz/gitwho/cli/config"
+	cliHotspots "github.com/flaviostutz/gitwho/cli/hotspots"
 	cliOwnership "github.com/flaviostutz/gitwho/cli/ownership"
 )
 
 func main() {
 
 	if len(os.Args) < 2 {
-		fmt.Println("Usage: gitwho [changes|changes-timeseries|ownership|ownership-timeseries|duplicates]")
+		fmt.Println("Usage: gitwho [changes|changes-timeseries|ownership|ownership-timeseries|ownership-tree|busfactor|codeowners|duplicates|hotspots|coupling|survival|config]")
 		os.Exit(1)
 	}
 
@@ -28,11 +30,32 @@ func main() {
 	case "ownership-timeseries":
 		cliOwnership.RunOwnershipTimeseries(os.Args)
 
+	case "ownership-tree":
+		cliOwnership.RunOwnershipTree(os.Args)
+
+	case "busfactor":
+		cliOwnership.RunBusFactor(os.Args)
+
+	case "codeowners":
+		cliOwnership.RunCodeowners(os.Args)
+
 	case "duplicates":
 		cliOwnership.RunDuplicates(os.Args)
 
+	case "hotspots":
+		cliHotspots.RunHotspots(os.Args)
+
+	case "coupling":
+		cliChanges.RunCoupling(os.Args)
+
+	case "survival":
+		cliOwnership.RunSurvival(os.Args)
+
+	case "config":
+		cliConfig.RunConfig(os.Args)
+
 	default:
-		fmt.Println("Usage: gitwho [changes|changes-timeseries|ownership|ownership-timeseries|duplicates]")
+		fmt.Println("Usage: gitwho [changes|changes-timeseries|ownership|ownership-timeseries|ownership-tree|busfactor|codeowners|duplicates|hotspots|coupling|survival|config]")
 		os.Exit(1)
 	}
 }
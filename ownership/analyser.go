@@ -1,6 +1,7 @@
 package ownership
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
@@ -19,6 +20,8 @@ type OwnershipOptions struct {
 	utils.BaseOptions
 	MinDuplicateLines int    `json:"min_duplicate_lines"`
 	CommitId          string `json:"commit_id"`
+	// DetectMoves lines moved or copied from other files are owned by their original author
+	DetectMoves bool `json:"detect_moves"`
 }
 
 type OwnershipTimeseriesOptions struct {
@@ -27,6 +30,7 @@ type OwnershipTimeseriesOptions struct {
 	Since             string `json:"since"`
 	Until             string `json:"until"`
 	Period            string `json:"period"`
+	DetectMoves       bool   `json:"detect_moves"`
 }
 
 type AuthorLines struct {
@@ -40,31 +44,86 @@ type AuthorLines struct {
 	OwnedLinesDuplicateOriginal int `json:"owned_lines_duplicate_original"`
 	// OwnedLinesDuplicateOriginalOthers total lines owned that were found duplicated by someone else (your code was duplicated by others)
 	OwnedLinesDuplicateOriginalOthers int `json:"owned_lines_duplicate_original_others"`
+	// OwnedLinesMoved total lines owned that were originally written in another file and were moved or copied
+	// to where they are now by someone else. Only calculated when DetectMoves is enabled
+	OwnedLinesMoved int `json:"owned_lines_moved"`
 }
+
+// FileOwnership lines owned per author in a single file
+type FileOwnership struct {
+	FilePath        string        `json:"file_path"`
+	TotalLines      int           `json:"total_lines"`
+	LinesAgeDaysSum float64       `json:"lines_age_days_sum"`
+	AuthorsLines    []AuthorLines `json:"authors_lines"`
+}
+
+type TeamLines struct {
+	TeamName                          string   `json:"team_name"`
+	AuthorNames                       []string `json:"author_names"`
+	OwnedLinesTotal                   int      `json:"owned_lines_total"`
+	OwnedLinesAgeDaysSum              float64  `json:"owned_lines_age_days_sum"`
+	OwnedLinesDuplicate               int      `json:"owned_lines_duplicate"`
+	OwnedLinesDuplicateOriginal       int      `json:"owned_lines_duplicate_original"`
+	OwnedLinesDuplicateOriginalOthers int      `json:"owned_lines_duplicate_original_others"`
+	OwnedLinesMoved                   int      `json:"owned_lines_moved"`
+}
+
 type OwnershipResult struct {
-	Commit               utils.CommitInfo       `json:"commit"`
-	TotalFiles           int                    `json:"total_files"`
-	TotalLines           int                    `json:"total_lines"`
-	TotalLinesDuplicated int                    `json:"total_files_duplicated"`
-	LinesAgeDaysSum      float64                `json:"lines_age_days_sum"`
-	authorLinesMap       map[string]AuthorLines // temporary map used during processing
-	AuthorsLines         []AuthorLines          `json:"authors_lines"`
-	FilePath             string                 `json:"file_path"`
-	DuplicateLineGroups  []utils.LineGroup      `json:"duplicate_line_groups"`
-	blameTime            time.Duration
-	skippedFiles         int
+	Commit               utils.CommitInfo `json:"commit"`
+	TotalFiles           int              `json:"total_files"`
+	TotalLines           int              `json:"total_lines"`
+	TotalLinesDuplicated int              `json:"total_lines_duplicated"`
+	// TotalLinesMoved lines that were moved or copied from other files by someone other than their owner.
+	// Lines of renamed files are not counted. Only calculated when DetectMoves is enabled
+	TotalLinesMoved int                    `json:"total_lines_moved"`
+	LinesAgeDaysSum float64                `json:"lines_age_days_sum"`
+	authorLinesMap  map[string]AuthorLines // temporary map used during processing
+	AuthorsLines    []AuthorLines          `json:"authors_lines"`
+	// TeamsLines lines owned per team. Empty if teams were not defined
+	TeamsLines []TeamLines `json:"teams_lines"`
+	FilePath   string      `json:"file_path"`
+	// FilesOwnership lines owned per author for each file analysed, sorted by file path
+	FilesOwnership      []FileOwnership   `json:"files_ownership"`
+	DuplicateLineGroups []utils.LineGroup `json:"duplicate_line_groups"`
+	blameTime           time.Duration
+	skippedFiles        int
+	blameLines          []utils.BlameLine
+	// linesCreatedMap lines owned per author and per time (unix) in which they were written. It is used
+	// in survival analysis and is not cached, so it's nil in results that came from cache
+	linesCreatedMap map[string]map[int64]int
 }
 
 type fileWorkerRequest struct {
 	repoDir           string
+	git               utils.GitBackend
 	filePath          string
 	commitId          string
 	minDuplicateLines int
 	authorsRegex      string
 	authorsNotRegex   string
+	detectMoves       bool
+	identities        *utils.IdentityResolver
+	// prevBlame blame of the same file in another commit in which its contents were the same
+	prevBlame      []utils.BlameLine
+	reusePrevBlame bool
+	// fileCache stores blame results per file. nil if cache is disabled
+	fileCache *utils.CacheDB
+}
+
+// blameSnapshot blame of the files of a commit that can be reused when analysing
+// other commits in which the same files didn't change
+type blameSnapshot struct {
+	commitId   string
+	fileBlames map[string][]utils.BlameLine
 }
 
 func AnalyseTimeseriesOwnership(opts OwnershipTimeseriesOptions, progressChan chan<- utils.ProgressInfo) ([]OwnershipResult, error) {
+	return analyseTimeseriesOwnership(opts, false, progressChan)
+}
+
+// analyseTimeseriesOwnership analyses the ownership at each period. If skipCachedResults, results
+// are always calculated (the blame of each file can still come from cache) so that they have linesCreatedMap
+func analyseTimeseriesOwnership(opts OwnershipTimeseriesOptions, skipCachedResults bool, progressChan chan<- utils.ProgressInfo) ([]OwnershipResult, error) {
 	if opts.Period == "" {
 		return nil, fmt.Errorf("opts.Period is required")
 	}
@@ -72,18 +131,30 @@ func AnalyseTimeseriesOwnership(opts OwnershipTimeseriesOptions, progressChan ch
 		return nil, fmt.Errorf("opts.Until is required")
 	}
 
+	gitOpts, err := utils.NewGitOptions(opts.BaseOptions)
+	if err != nil {
+		return nil, err
+	}
+	git, err := utils.NewGitBackend(opts.GitBackend, opts.RepoDir, gitOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer git.Close()
+
 	result := make([]OwnershipResult, 0)
 	when := opts.Until
 	analysisOpts := OwnershipOptions{
 		BaseOptions:       opts.BaseOptions,
 		MinDuplicateLines: opts.MinDuplicateLines,
+		DetectMoves:       opts.DetectMoves,
 	}
 
 	prevCommitId := ""
+	var prevSnapshot *blameSnapshot
 	processedCommits := make([]string, 0)
 
 	for {
-		commit, err := utils.ExecGetLastestCommit(opts.RepoDir, opts.Branch, opts.Since, when)
+		commit, err := git.LatestCommit(opts.Branch, opts.Since, when)
 		if err != nil {
 			return nil, err
 		}
@@ -107,10 +178,13 @@ func AnalyseTimeseriesOwnership(opts OwnershipTimeseriesOptions, progressChan ch
 		}
 
 		analysisOpts.CommitId = commit.CommitId
-		onwershipResult, err := AnalyseOwnership(analysisOpts, progressChan)
+		// commits are analysed from newest to oldest, so reuse the blame of the files
+		// that didn't change since the last analysed commit
+		onwershipResult, snapshot, err := analyseOwnership(analysisOpts, prevSnapshot, skipCachedResults, progressChan)
 		if err != nil {
 			return nil, err
 		}
+		prevSnapshot = snapshot
 		result = append(result, onwershipResult)
 		processedCommits = append(processedCommits, analysisOpts.CommitId)
 
@@ -126,66 +200,143 @@ func AnalyseTimeseriesOwnership(opts OwnershipTimeseriesOptions, progressChan ch
 }
 
 func AnalyseOwnership(opts OwnershipOptions, progressChan chan<- utils.ProgressInfo) (OwnershipResult, error) {
+	result, _, err := analyseOwnership(opts, nil, false, progressChan)
+	return result, err
+}
+
+// analyseOwnership analyses the ownership of a commit reusing the blame of the files
+// from prevSnapshot that didn't change between both commits. It returns the blame of the
+// analysed files so it can be reused in the analysis of another commit. The returned snapshot
+// is nil if the result came from cache. If skipCachedResults, cached results are ignored
+func analyseOwnership(opts OwnershipOptions, prevSnapshot *blameSnapshot, skipCachedResults bool, progressChan chan<- utils.ProgressInfo) (OwnershipResult, *blameSnapshot, error) {
 	if opts.CommitId == "" {
-		return OwnershipResult{}, fmt.Errorf("opts.CommitId is required")
+		return OwnershipResult{}, nil, fmt.Errorf("opts.CommitId is required")
 	}
 
 	// check if cached results exists
-	if opts.CacheFile != "" {
+	if opts.CacheFile != "" && !skipCachedResults {
 		cachedResults, err := GetFromCache(opts)
 		if err != nil {
-			return OwnershipResult{}, err
+			return OwnershipResult{}, nil, err
 		}
 		if cachedResults != nil {
-			return *cachedResults, nil
+			return *cachedResults, nil, nil
 		}
 	}
 
-	commit, err := utils.ExecGitCommitInfo(opts.RepoDir, opts.CommitId)
+	identities, err := utils.NewIdentityResolver(opts.RepoDir, opts.IdentitiesFile)
+	if err != nil {
+		return OwnershipResult{}, nil, err
+	}
+	identities.AddIdentities(opts.Identities)
+
+	gitOpts, err := utils.NewGitOptions(opts.BaseOptions)
+	if err != nil {
+		return OwnershipResult{}, nil, err
+	}
+	gitOpts.DetectMoves = opts.DetectMoves
+
+	git, err := utils.NewGitBackend(opts.GitBackend, opts.RepoDir, gitOpts)
 	if err != nil {
-		return OwnershipResult{}, err
+		return OwnershipResult{}, nil, err
+	}
+	defer git.Close()
+
+	commit, err := git.CommitInfo(opts.CommitId)
+	if err != nil {
+		return OwnershipResult{}, nil, err
+	}
+	commit = identities.ResolveCommitInfo(commit)
+
+	teams, err := utils.NewTeamResolver(opts.TeamsFile)
+	if err != nil {
+		return OwnershipResult{}, nil, err
 	}
 
 	var duplicateLineTracker = utils.NewDuplicateLineTracker()
 	result := OwnershipResult{
-		TotalLines:     0,
-		authorLinesMap: make(map[string]AuthorLines, 0),
-		AuthorsLines:   make([]AuthorLines, 0),
-		Commit:         commit,
+		TotalLines:      0,
+		authorLinesMap:  make(map[string]AuthorLines, 0),
+		AuthorsLines:    make([]AuthorLines, 0),
+		TeamsLines:      make([]TeamLines, 0),
+		FilesOwnership:  make([]FileOwnership, 0),
+		Commit:          commit,
+		linesCreatedMap: make(map[string]map[int64]int, 0),
 	}
 
 	progressInfo := utils.ProgressInfo{}
 
 	if opts.MinDuplicateLines == 0 {
-		return OwnershipResult{}, fmt.Errorf("MinDuplicateLines must be > 0")
+		return OwnershipResult{}, nil, fmt.Errorf("MinDuplicateLines must be > 0")
 	}
 
 	fileRe, err := regexp.Compile(opts.FilesRegex)
 	if err != nil {
-		return result, errors.New("file filter regex is invalid. err=" + err.Error())
+		return result, nil, errors.New("file filter regex is invalid. err=" + err.Error())
 	}
 
 	fileReNot, err := regexp.Compile(opts.FilesNotRegex)
 	if err != nil {
-		return result, errors.New("files-not filter regex is invalid. err=" + err.Error())
+		return result, nil, errors.New("files-not filter regex is invalid. err=" + err.Error())
 	}
 
 	_, err = regexp.Compile(opts.AuthorsRegex)
 	if err != nil {
-		return result, errors.New("authors filter regex is invalid. err=" + err.Error())
+		return result, nil, errors.New("authors filter regex is invalid. err=" + err.Error())
 	}
 
 	_, err = regexp.Compile(opts.AuthorsNotRegex)
 	if err != nil {
-		return result, errors.New("authors-not filter regex is invalid. err=" + err.Error())
+		return result, nil, errors.New("authors-not filter regex is invalid. err=" + err.Error())
+	}
+
+	excludeFilter, err := utils.NewExcludeFilter(git, opts.ExcludePresets, opts.CommitId)
+	if err != nil {
+		return result, nil, err
 	}
 
 	logrus.Debugf("Analysing branch %s at %s", opts.Branch, opts.CommitId)
 
+	// files touched by any commit between the previous snapshot and this commit must be blamed again.
+	// Comparing only both trees isn't enough, because a file that was changed and then reverted
+	// to the same contents is blamed to the commit that reverted it
+	var changedFiles map[string]bool
+	if prevSnapshot != nil {
+		changedFiles = make(map[string]bool, 0)
+		// snapshots might be analysed from the newest to the oldest or the opposite
+		for _, commitRange := range [][]string{{prevSnapshot.commitId, opts.CommitId}, {opts.CommitId, prevSnapshot.commitId}} {
+			files, err := git.FilesChangedInRange(commitRange[0], commitRange[1])
+			if err != nil {
+				return OwnershipResult{}, nil, fmt.Errorf("Couldn't get files changed since previous snapshot. err=%s", err)
+			}
+			for _, file := range files {
+				changedFiles[file] = true
+			}
+		}
+		logrus.Debugf("Reusing blame of unchanged files from commit %s. changedFiles=%d", prevSnapshot.commitId, len(changedFiles))
+	}
+	// blame results per file can be reused by analyses with different options
+	var fileCache *utils.CacheDB
+	if opts.CacheFile != "" {
+		fileCache, err = utils.NewCacheDB(opts.CacheFile, cacheTable, opts.CacheTTLSeconds)
+		if err != nil {
+			return OwnershipResult{}, nil, fmt.Errorf("Cannot use file to cache results. err=%s", err)
+		}
+		defer fileCache.Close()
+	}
+
+	snapshot := &blameSnapshot{
+		commitId:   opts.CommitId,
+		fileBlames: make(map[string][]utils.BlameLine, 0),
+	}
+
 	// MAP REDUCE - analyse files in parallel goroutines
 	// we need to start workers in the reverse order so that all the chain
 	// is prepared when submitting tasks to avoid deadlocks
 	nrWorkers := runtime.NumCPU() - 1
+	if nrWorkers < 1 {
+		nrWorkers = 1
+	}
 	// nrWorkers := 1
 	logrus.Debugf("Preparing a pool of workers to process file analysis in parallel")
 	fileWorkerInputChan := make(chan fileWorkerRequest, 5000)
@@ -202,6 +353,7 @@ func AnalyseOwnership(opts OwnershipOptions, progressChan chan<- utils.ProgressI
 			result.TotalFiles += fileResult.TotalFiles
 			result.TotalLines += fileResult.TotalLines
 			result.TotalLinesDuplicated += fileResult.TotalLinesDuplicated
+			result.TotalLinesMoved += fileResult.TotalLinesMoved
 			result.LinesAgeDaysSum += fileResult.LinesAgeDaysSum
 			for author := range fileResult.authorLinesMap {
 				fileAuthorLines := fileResult.authorLinesMap[author]
@@ -213,8 +365,28 @@ func AnalyseOwnership(opts OwnershipOptions, progressChan chan<- utils.ProgressI
 				resultAuthorLines.OwnedLinesDuplicate += fileAuthorLines.OwnedLinesDuplicate
 				resultAuthorLines.OwnedLinesDuplicateOriginal += fileAuthorLines.OwnedLinesDuplicateOriginal
 				resultAuthorLines.OwnedLinesDuplicateOriginalOthers += fileAuthorLines.OwnedLinesDuplicateOriginalOthers
+				resultAuthorLines.OwnedLinesMoved += fileAuthorLines.OwnedLinesMoved
 				result.authorLinesMap[author] = resultAuthorLines
 			}
+			for author, fileLinesCreated := range fileResult.linesCreatedMap {
+				linesCreated, ok := result.linesCreatedMap[author]
+				if !ok {
+					linesCreated = make(map[int64]int, 0)
+					result.linesCreatedMap[author] = linesCreated
+				}
+				for created, lines := range fileLinesCreated {
+					linesCreated[created] += lines
+				}
+			}
+			if fileResult.TotalFiles > 0 {
+				result.FilesOwnership = append(result.FilesOwnership, FileOwnership{
+					FilePath:        fileResult.FilePath,
+					TotalLines:      fileResult.TotalLines,
+					LinesAgeDaysSum: fileResult.LinesAgeDaysSum,
+					AuthorsLines:    sortedAuthorsLines(fileResult.authorLinesMap),
+				})
+			}
+			snapshot.fileBlames[fileResult.FilePath] = fileResult.blameLines
 			progressInfo.CompletedTasks += 1 + fileResult.skippedFiles
 			progressInfo.CompletedTotalTime += fileResult.blameTime
 			progressInfo.Message = fmt.Sprintf("%s (%dms)", fileResult.FilePath, fileResult.blameTime.Milliseconds())
@@ -227,16 +399,12 @@ func AnalyseOwnership(opts OwnershipOptions, progressChan chan<- utils.ProgressI
 		// result.DuplicateLines = groupDuplicateLines(duplicateLineTracker)
 
 		logrus.Debugf("Sorting and preparing summary for each author")
-		authorsLines := make([]AuthorLines, 0)
-		for author := range result.authorLinesMap {
-			lines := result.authorLinesMap[author]
-			authorsLines = append(authorsLines, lines)
-		}
-
-		sort.Slice(authorsLines, func(i, j int) bool {
-			return authorsLines[i].OwnedLinesTotal > authorsLines[j].OwnedLinesTotal
-		})
+		authorsLines := sortedAuthorsLines(result.authorLinesMap)
 		result.AuthorsLines = authorsLines
+		sort.Slice(result.FilesOwnership, func(i, j int) bool {
+			return result.FilesOwnership[i].FilePath < result.FilesOwnership[j].FilePath
+		})
+		result.TeamsLines = sumTeamsLines(authorsLines, teams)
 	}()
 
 	// MAP - start analyser workers (STEP 2/3)
@@ -252,26 +420,37 @@ func AnalyseOwnership(opts OwnershipOptions, progressChan chan<- utils.ProgressI
 		logrus.Debugf("Scheduling files for analysis. filesRegex=%s", opts.FilesRegex)
 		totalFiles := 0
 		progressInfo.TotalTasksKnown = false
-		files, err := utils.ExecListTree(opts.RepoDir, opts.CommitId)
+		files, err := git.ListTree(opts.CommitId)
 		if err != nil {
 			logrus.Errorf("Error getting commit tree. err=%s", err)
 			panic(5)
 		}
 
 		for _, fileName := range files {
-			if strings.Trim(fileName, " ") == "" || !fileRe.MatchString(fileName) || (opts.FilesNotRegex != "" && fileReNot.MatchString(fileName)) {
+			if strings.Trim(fileName, " ") == "" || !fileRe.MatchString(fileName) || (opts.FilesNotRegex != "" && fileReNot.MatchString(fileName)) || excludeFilter.FileExcluded(fileName) {
 				// logrus.Debugf("Ignoring file %s", file.Name)
 				continue
 			}
 			totalFiles += 1
 			progressInfo.TotalTasks += 1
+			var prevBlame []utils.BlameLine
+			reusePrevBlame := false
+			if prevSnapshot != nil && !changedFiles[fileName] {
+				prevBlame, reusePrevBlame = prevSnapshot.fileBlames[fileName]
+			}
 			fileWorkerInputChan <- fileWorkerRequest{
 				repoDir:           opts.RepoDir,
+				git:               git,
 				filePath:          fileName,
 				commitId:          opts.CommitId,
 				minDuplicateLines: opts.MinDuplicateLines,
 				authorsRegex:      opts.AuthorsRegex,
-				authorsNotRegex:   opts.AuthorsNotRegex,
+				authorsNotRegex:   excludeFilter.AuthorsNotRegex(opts.AuthorsNotRegex),
+				detectMoves:       opts.DetectMoves,
+				identities:        identities,
+				prevBlame:         prevBlame,
+				reusePrevBlame:    reusePrevBlame,
+				fileCache:         fileCache,
 			}
 		}
 
@@ -307,7 +486,7 @@ func AnalyseOwnership(opts OwnershipOptions, progressChan chan<- utils.ProgressI
 		SaveToCache(opts, result)
 	}
 
-	return result, nil
+	return result, snapshot, nil
 }
 
 // this will be run by multiple goroutines
@@ -320,44 +499,32 @@ func fileWorker(fileWorkerInputChan <-chan fileWorkerRequest,
 	skippedFiles := 0
 	for req := range fileWorkerInputChan {
 		startTime := time.Now()
-		ownershipResult := OwnershipResult{TotalLines: 0, authorLinesMap: make(map[string]AuthorLines, 0)}
+		ownershipResult := OwnershipResult{TotalLines: 0, authorLinesMap: make(map[string]AuthorLines, 0), linesCreatedMap: make(map[string]map[int64]int, 0)}
 		ownershipResult.FilePath = req.filePath
 
-		commitInfo, err := utils.ExecGitCommitInfo(req.repoDir, req.commitId)
+		commitInfo, err := req.git.CommitInfo(req.commitId)
 		if err != nil {
 			fileWorkerErrChan <- errors.New(fmt.Sprintf("Couldn't get commit info. commitId=%s; err=%s", req.commitId, err))
 			break
 		}
-
-		fsize, err := utils.ExecTreeFileSize(req.repoDir, req.commitId, req.filePath)
-		if err != nil {
-			// can't get file size when the file was deleted by commit, so it's not present anymore
-			// TODO get previous version of the file and count these lines as "changed" because they were deleted?
-			skippedFiles++
-			continue
-		}
-		if fsize > 80000 {
-			logrus.Debugf("Ignoring file because it's too big. file=%s, size=%d", req.filePath, fsize)
-			skippedFiles++
-			continue
-		}
-
-		isBin, err := utils.ExecDiffIsBinary(req.repoDir, req.commitId, req.filePath)
-		if err != nil {
-			fileWorkerErrChan <- errors.New(fmt.Sprintf("Couldn't determine if file is binary. file=%s; commitId=%s; err=%s", req.filePath, req.commitId, err))
-			break
-		}
-		if isBin {
-			logrus.Debugf("Ignoring binary file. file=%s, commitId=%s", req.filePath, req.commitId)
-			skippedFiles++
-			continue
-		}
-
-		blameResult, err := utils.ExecGitBlame(req.repoDir, req.filePath, req.commitId)
-		if err != nil {
-			fileWorkerErrChan <- errors.New(fmt.Sprintf("Error on git blame. file=%s. err=%s", req.filePath, err))
-			break
+		commitInfo = req.identities.ResolveCommitInfo(commitInfo)
+
+		// reuse blame from a previous analysis if the file didn't change
+		blameResult := req.prevBlame
+		if !req.reusePrevBlame {
+			var skipped bool
+			blameResult, skipped, err = blameFile(req)
+			if err != nil {
+				fileWorkerErrChan <- err
+				break
+			}
+			if skipped {
+				skippedFiles++
+				continue
+			}
+			blameResult = req.identities.ResolveBlameLines(blameResult)
 		}
+		ownershipResult.blameLines = blameResult
 
 		// go over each line of the file
 		fileTouched := false
@@ -382,6 +549,18 @@ func fileWorker(fileWorkerInputChan <-chan fileWorkerRequest,
 			if countAuthor {
 				authorLines.OwnedLinesTotal += 1
 				authorLines.OwnedLinesAgeDaysSum += lineAge
+				linesCreated, ok := ownershipResult.linesCreatedMap[lineAuthor.AuthorName]
+				if !ok {
+					linesCreated = make(map[int64]int, 0)
+					ownershipResult.linesCreatedMap[lineAuthor.AuthorName] = linesCreated
+				}
+				linesCreated[lineAuthor.AuthorDate.Unix()]++
+				// blame kept the author from the file the line was moved or copied from.
+				// Lines of renamed files and lines moved by their own author are not counted
+				if req.detectMoves && lineAuthor.MovedCommitId != "" && lineAuthor.MovedByName != lineAuthor.AuthorName {
+					ownershipResult.TotalLinesMoved += 1
+					authorLines.OwnedLinesMoved += 1
+				}
 			}
 
 			// Duplication analysis
@@ -447,6 +626,77 @@ func fileWorker(fileWorkerInputChan <-chan fileWorkerRequest,
 	}
 }
 
+// fileBlameCacheVersion must be changed when fileBlame or the way it's calculated changes
+var fileBlameCacheVersion = "ownership-blame-4"
+
+// fileBlame raw results of git blame for a file in a commit, stored in the files cache
+type fileBlame struct {
+	// Skipped the file is not analysed because it's too big, binary or not present in the commit
+	Skipped bool
+	Lines   []utils.BlameLine
+}
+
+// blameFile runs git blame for the file or gets its results from the files cache.
+// The identities of the authors are not resolved, so the cached results can be
+// reused with other identities files
+func blameFile(req fileWorkerRequest) ([]utils.BlameLine, bool, error) {
+	cachedValue, err := req.fileCache.GetFileValue(req.commitId, req.filePath, fileBlameCacheVersion+":"+req.git.CacheVersion())
+	if err != nil {
+		logrus.Debugf("Couldn't get file blame from cache. file=%s; err=%s", req.filePath, err)
+	}
+	if cachedValue != nil {
+		cached := fileBlame{}
+		err = json.Unmarshal([]byte(*cachedValue), &cached)
+		if err == nil {
+			return cached.Lines, cached.Skipped, nil
+		}
+		logrus.Debugf("Ignoring invalid file blame in cache. file=%s; err=%s", req.filePath, err)
+	}
+
+	result, err := execBlameFile(req)
+	if err != nil {
+		return nil, false, err
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		return nil, false, err
+	}
+	err = req.fileCache.PutFileValue(req.commitId, req.filePath, fileBlameCacheVersion+":"+req.git.CacheVersion(), string(b))
+	if err != nil {
+		logrus.Warnf("Couldn't save file blame to cache. file=%s; err=%s", req.filePath, err)
+	}
+	return result.Lines, result.Skipped, nil
+}
+
+func execBlameFile(req fileWorkerRequest) (fileBlame, error) {
+	fsize, err := req.git.TreeFileSize(req.commitId, req.filePath)
+	if err != nil {
+		// can't get file size when the file was deleted by commit, so it's not present anymore
+		// TODO get previous version of the file and count these lines as "changed" because they were deleted?
+		return fileBlame{Skipped: true}, nil
+	}
+	if fsize > 80000 {
+		logrus.Debugf("Ignoring file because it's too big. file=%s, size=%d", req.filePath, fsize)
+		return fileBlame{Skipped: true}, nil
+	}
+
+	isBin, err := req.git.IsBinary(req.commitId, req.filePath)
+	if err != nil {
+		return fileBlame{}, fmt.Errorf("Couldn't determine if file is binary. file=%s; commitId=%s; err=%s", req.filePath, req.commitId, err)
+	}
+	if isBin {
+		logrus.Debugf("Ignoring binary file. file=%s, commitId=%s", req.filePath, req.commitId)
+		return fileBlame{Skipped: true}, nil
+	}
+
+	lines, err := req.git.Blame(req.filePath, req.commitId)
+	if err != nil {
+		return fileBlame{}, fmt.Errorf("Error on git blame. file=%s. err=%s", req.filePath, err)
+	}
+	return fileBlame{Lines: lines}, nil
+}
+
 func authorCounted(req fileWorkerRequest, authorName string, authorMail string) bool {
 	authorsRe := regexp.MustCompile(req.authorsRegex)
 	authorsNotRe := regexp.MustCompile(req.authorsNotRegex)
@@ -454,3 +704,53 @@ func authorCounted(req fileWorkerRequest, authorName string, authorMail string)
 		(req.authorsNotRegex == "" ||
 			(!authorsNotRe.MatchString(authorName) && !authorsNotRe.MatchString(authorMail)))
 }
+
+func sortedAuthorsLines(authorLinesMap map[string]AuthorLines) []AuthorLines {
+	authorsLines := make([]AuthorLines, 0)
+	for author := range authorLinesMap {
+		authorsLines = append(authorsLines, authorLinesMap[author])
+	}
+	sort.Slice(authorsLines, func(i, j int) bool {
+		if authorsLines[i].OwnedLinesTotal == authorsLines[j].OwnedLinesTotal {
+			return authorsLines[i].AuthorName < authorsLines[j].AuthorName
+		}
+		return authorsLines[i].OwnedLinesTotal > authorsLines[j].OwnedLinesTotal
+	})
+	return authorsLines
+}
+
+// sumTeamsLines aggregates the lines owned by authors of the same team
+func sumTeamsLines(authorsLines []AuthorLines, teams *utils.TeamResolver) []TeamLines {
+	teamsLines := make([]TeamLines, 0)
+	if teams == nil {
+		return teamsLines
+	}
+
+	teamsLinesMap := make(map[string]TeamLines, 0)
+	for _, authorLines := range authorsLines {
+		teamName := teams.TeamOf(authorLines.AuthorName, authorLines.AuthorMail)
+		teamLines, ok := teamsLinesMap[teamName]
+		if !ok {
+			teamLines = TeamLines{TeamName: teamName, AuthorNames: make([]string, 0)}
+		}
+		teamLines.AuthorNames = append(teamLines.AuthorNames, authorLines.AuthorName)
+		teamLines.OwnedLinesTotal += authorLines.OwnedLinesTotal
+		teamLines.OwnedLinesAgeDaysSum += authorLines.OwnedLinesAgeDaysSum
+		teamLines.OwnedLinesDuplicate += authorLines.OwnedLinesDuplicate
+		teamLines.OwnedLinesDuplicateOriginal += authorLines.OwnedLinesDuplicateOriginal
+		teamLines.OwnedLinesDuplicateOriginalOthers += authorLines.OwnedLinesDuplicateOriginalOthers
+		teamLines.OwnedLinesMoved += authorLines.OwnedLinesMoved
+		teamsLinesMap[teamName] = teamLines
+	}
+
+	for _, teamLines := range teamsLinesMap {
+		teamsLines = append(teamsLines, teamLines)
+	}
+	sort.Slice(teamsLines, func(i, j int) bool {
+		if teamsLines[i].OwnedLinesTotal == teamsLines[j].OwnedLinesTotal {
+			return teamsLines[i].TeamName < teamsLines[j].TeamName
+		}
+		return teamsLines[i].OwnedLinesTotal > teamsLines[j].OwnedLinesTotal
+	})
+	return teamsLines
+}
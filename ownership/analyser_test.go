@@ -2,6 +2,7 @@ package ownership
 
 import (
 	"fmt"
+	"os"
 	"testing"
 
 	"github.com/flaviostutz/gitwho/utils"
@@ -288,3 +289,349 @@ func TestAnalyseCodeOwnershipRegexNotFiles(t *testing.T) {
 	require.Equal(t, 0, results.TotalLines)
 	require.Equal(t, 0, len(results.AuthorsLines))
 }
+
+func TestAnalyseCodeOwnershipIdentities(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	identitiesFile := t.TempDir() + "/identities.yml"
+	err = os.WriteFile(identitiesFile, []byte(`
+- name: Author One
+  mail: author1@mail.com
+  names: [author2]
+`), 0644)
+	require.Nil(t, err)
+
+	results, err := AnalyseOwnership(OwnershipOptions{
+		BaseOptions: utils.BaseOptions{
+			RepoDir:        repoDir,
+			Branch:         "main",
+			IdentitiesFile: identitiesFile,
+		},
+		MinDuplicateLines: 2,
+		CommitId:          commit.CommitId,
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 7, results.TotalLines)
+	require.Equal(t, 2, len(results.AuthorsLines))
+	require.Equal(t, "author3", results.AuthorsLines[0].AuthorName)
+	require.Equal(t, "Author One", results.AuthorsLines[1].AuthorName)
+	require.Equal(t, "<author1@mail.com>", results.AuthorsLines[1].AuthorMail)
+	require.Equal(t, 2, results.AuthorsLines[1].OwnedLinesTotal)
+}
+
+func TestAnalyseCodeOwnershipTeams(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	teamsFile := t.TempDir() + "/teams.yml"
+	err = os.WriteFile(teamsFile, []byte("squad-a:\n  - author1\n  - author2@mail.com\n"), 0644)
+	require.Nil(t, err)
+
+	results, err := AnalyseOwnership(OwnershipOptions{
+		BaseOptions: utils.BaseOptions{
+			RepoDir:   repoDir,
+			Branch:    "main",
+			TeamsFile: teamsFile,
+		},
+		MinDuplicateLines: 2,
+		CommitId:          commit.CommitId,
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(results.AuthorsLines))
+	require.Equal(t, 2, len(results.TeamsLines))
+	require.Equal(t, utils.NoTeamName, results.TeamsLines[0].TeamName)
+	require.Equal(t, 5, results.TeamsLines[0].OwnedLinesTotal)
+	require.Equal(t, "squad-a", results.TeamsLines[1].TeamName)
+	require.Equal(t, 2, results.TeamsLines[1].OwnedLinesTotal)
+	require.ElementsMatch(t, []string{"author1", "author2"}, results.TeamsLines[1].AuthorNames)
+}
+
+func TestTimeseriesOwnershipIncremental(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+	baseOpts := utils.BaseOptions{
+		RepoDir: repoDir,
+		Branch:  "main",
+	}
+	results, err := AnalyseTimeseriesOwnership(OwnershipTimeseriesOptions{
+		BaseOptions:       baseOpts,
+		MinDuplicateLines: 2,
+		Until:             "now",
+		Period:            "1 second",
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(results))
+
+	// results reusing blame from other snapshots must be the same as a full analysis
+	for _, tsResult := range results {
+		opts := OwnershipOptions{
+			BaseOptions:       baseOpts,
+			MinDuplicateLines: 2,
+			CommitId:          tsResult.Commit.CommitId,
+		}
+		fullResult, err := AnalyseOwnership(opts, nil)
+		require.Nil(t, err)
+		require.Equal(t, fullResult.TotalLines, tsResult.TotalLines)
+		require.Equal(t, fullResult.TotalFiles, tsResult.TotalFiles)
+		require.Equal(t, fullResult.LinesAgeDaysSum, tsResult.LinesAgeDaysSum)
+		require.Equal(t, fullResult.AuthorsLines, tsResult.AuthorsLines)
+		require.Equal(t, fullResult.FilesOwnership, tsResult.FilesOwnership)
+	}
+}
+
+func TestAnalyseOwnershipReuseSnapshot(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	opts := OwnershipOptions{
+		BaseOptions: utils.BaseOptions{
+			RepoDir: repoDir,
+			Branch:  "main",
+		},
+		MinDuplicateLines: 2,
+		CommitId:          commit.CommitId,
+	}
+	result, snapshot, err := analyseOwnership(opts, nil, false, nil)
+	require.Nil(t, err)
+	require.Equal(t, commit.CommitId, snapshot.commitId)
+	require.Equal(t, 2, len(snapshot.fileBlames))
+	require.Equal(t, 3, len(result.AuthorsLines))
+
+	// change the blame of an unchanged file to check it's being reused
+	fileBlame := make([]utils.BlameLine, 0)
+	for _, line := range snapshot.fileBlames["file1"] {
+		line.AuthorName = "author9"
+		line.AuthorMail = "<author9@mail.com>"
+		fileBlame = append(fileBlame, line)
+	}
+	snapshot.fileBlames["file1"] = fileBlame
+
+	result, _, err = analyseOwnership(opts, snapshot, false, nil)
+	require.Nil(t, err)
+	require.Equal(t, 7, result.TotalLines)
+	require.Equal(t, 2, len(result.AuthorsLines))
+	require.Equal(t, "author3", result.AuthorsLines[0].AuthorName)
+	require.Equal(t, "author9", result.AuthorsLines[1].AuthorName)
+	require.Equal(t, 2, result.AuthorsLines[1].OwnedLinesTotal)
+}
+
+func TestAnalyseCodeOwnershipReusedBlameReverted(t *testing.T) {
+	repoDir, err := utils.ResolveTestRevertedChangesRepo()
+	require.Nil(t, err)
+
+	commits, err := utils.ExecGetCommitsInDateRange(repoDir, "main", "", "now")
+	require.Nil(t, err)
+	require.Equal(t, 3, len(commits))
+
+	for _, backend := range []string{utils.GitBackendExec, utils.GitBackendGoGit} {
+		// commit 3 reverted the line changed by commit 2, so the line is owned by author2
+		opts := OwnershipOptions{
+			BaseOptions: utils.BaseOptions{
+				RepoDir:    repoDir,
+				Branch:     "main",
+				GitBackend: backend,
+			},
+			MinDuplicateLines: 2,
+			CommitId:          commits[0].CommitId,
+		}
+		result, snapshot, err := analyseOwnership(opts, nil, false, nil)
+		require.Nil(t, err)
+		require.Equal(t, 2, len(result.AuthorsLines))
+
+		// file1 has the same contents in commit 1 and 3, but its blame can't be reused
+		opts.CommitId = commits[2].CommitId
+		result, _, err = analyseOwnership(opts, snapshot, false, nil)
+		require.Nil(t, err)
+		require.Equal(t, 1, len(result.AuthorsLines))
+		require.Equal(t, "author1", result.AuthorsLines[0].AuthorName)
+		require.Equal(t, 3, result.AuthorsLines[0].OwnedLinesTotal)
+	}
+}
+
+func TestAnalyseCodeOwnershipGoGit(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	opts := OwnershipOptions{
+		BaseOptions: utils.BaseOptions{
+			RepoDir: repoDir,
+			Branch:  "main",
+		},
+		MinDuplicateLines: 2,
+		CommitId:          commit.CommitId,
+	}
+	execResult, err := AnalyseOwnership(opts, nil)
+	require.Nil(t, err)
+
+	opts.GitBackend = utils.GitBackendGoGit
+	goGitResult, err := AnalyseOwnership(opts, nil)
+	require.Nil(t, err)
+	require.Equal(t, execResult.TotalLines, goGitResult.TotalLines)
+	require.Equal(t, execResult.TotalFiles, goGitResult.TotalFiles)
+	require.Equal(t, execResult.AuthorsLines, goGitResult.AuthorsLines)
+	require.Equal(t, execResult.FilesOwnership, goGitResult.FilesOwnership)
+}
+
+func TestAnalyseCodeOwnershipIgnoreWhitespace(t *testing.T) {
+	repoDir, err := utils.ResolveTestWhitespaceRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	results, err := AnalyseOwnership(OwnershipOptions{
+		BaseOptions: utils.BaseOptions{
+			RepoDir:          repoDir,
+			Branch:           "main",
+			IgnoreWhitespace: true,
+		},
+		MinDuplicateLines: 2,
+		CommitId:          commit.CommitId,
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 3, results.TotalLines)
+
+	// the reformatting commit of author2 doesn't own any lines
+	owned := map[string]int{}
+	for _, al := range results.AuthorsLines {
+		owned[al.AuthorName] = al.OwnedLinesTotal
+	}
+	require.Equal(t, 2, owned["author1"])
+	require.Equal(t, 0, owned["author2"])
+	require.Equal(t, 1, owned["author3"])
+}
+
+func TestAnalyseCodeOwnershipDetectMoves(t *testing.T) {
+	repoDir, err := utils.ResolveTestMovedLinesRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	opts := OwnershipOptions{
+		BaseOptions: utils.BaseOptions{
+			RepoDir: repoDir,
+			Branch:  "main",
+		},
+		MinDuplicateLines: 2,
+		CommitId:          commit.CommitId,
+	}
+	results, err := AnalyseOwnership(opts, nil)
+	require.Nil(t, err)
+	require.Equal(t, 5, results.TotalLines)
+	require.Equal(t, 0, results.TotalLinesMoved)
+	require.Equal(t, "author2", results.AuthorsLines[0].AuthorName)
+	require.Equal(t, 4, results.AuthorsLines[0].OwnedLinesTotal)
+
+	// lines moved by author2 are kept with author1
+	opts.DetectMoves = true
+	results, err = AnalyseOwnership(opts, nil)
+	require.Nil(t, err)
+	require.Equal(t, 5, results.TotalLines)
+	require.Equal(t, 3, results.TotalLinesMoved)
+	require.Equal(t, 2, len(results.AuthorsLines))
+	require.Equal(t, "author1", results.AuthorsLines[0].AuthorName)
+	require.Equal(t, 4, results.AuthorsLines[0].OwnedLinesTotal)
+	require.Equal(t, 3, results.AuthorsLines[0].OwnedLinesMoved)
+	require.Equal(t, "author2", results.AuthorsLines[1].AuthorName)
+	require.Equal(t, 0, results.AuthorsLines[1].OwnedLinesMoved)
+
+	opts.GitBackend = utils.GitBackendGoGit
+	_, err = AnalyseOwnership(opts, nil)
+	require.NotNil(t, err)
+}
+
+func TestAnalyseCodeOwnershipDetectMovesRenamed(t *testing.T) {
+	repoDir, err := utils.ResolveTestRenamedMovedLinesRepo()
+	require.Nil(t, err)
+
+	commits, err := utils.ExecGetCommitsInDateRange(repoDir, "main", "", "now")
+	require.Nil(t, err)
+	require.Equal(t, 3, len(commits))
+
+	// a plain git mv doesn't move lines
+	opts := OwnershipOptions{
+		BaseOptions: utils.BaseOptions{
+			RepoDir: repoDir,
+			Branch:  "main",
+		},
+		MinDuplicateLines: 2,
+		CommitId:          commits[1].CommitId,
+		DetectMoves:       true,
+	}
+	results, err := AnalyseOwnership(opts, nil)
+	require.Nil(t, err)
+	require.Equal(t, 4, results.TotalLines)
+	require.Equal(t, 0, results.TotalLinesMoved)
+	require.Equal(t, 1, len(results.AuthorsLines))
+	require.Equal(t, "author1", results.AuthorsLines[0].AuthorName)
+	require.Equal(t, 0, results.AuthorsLines[0].OwnedLinesMoved)
+
+	// lines moved by their own author aren't counted as moved
+	opts.CommitId = commits[0].CommitId
+	results, err = AnalyseOwnership(opts, nil)
+	require.Nil(t, err)
+	require.Equal(t, 5, results.TotalLines)
+	require.Equal(t, 0, results.TotalLinesMoved)
+	require.Equal(t, "author1", results.AuthorsLines[0].AuthorName)
+	require.Equal(t, 0, results.AuthorsLines[0].OwnedLinesMoved)
+}
+
+func TestAnalyseCodeOwnershipExcludePresets(t *testing.T) {
+	repoDir, err := utils.ResolveTestExcludePresetsRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	results, err := AnalyseOwnership(OwnershipOptions{
+		BaseOptions: utils.BaseOptions{
+			RepoDir: repoDir,
+			Branch:  "main",
+		},
+		MinDuplicateLines: 2,
+		CommitId:          commit.CommitId,
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 7, results.TotalFiles)
+	require.Equal(t, 2, len(results.AuthorsLines))
+
+	results, err = AnalyseOwnership(OwnershipOptions{
+		BaseOptions: utils.BaseOptions{
+			RepoDir:        repoDir,
+			Branch:         "main",
+			ExcludePresets: "bots,lockfiles,vendor,generated",
+		},
+		MinDuplicateLines: 2,
+		CommitId:          commit.CommitId,
+	}, nil)
+	require.Nil(t, err)
+	// file1, keep.pb.go and .gitattributes
+	require.Equal(t, 3, results.TotalFiles)
+	require.Equal(t, 1, len(results.AuthorsLines))
+	require.Equal(t, "author1", results.AuthorsLines[0].AuthorName)
+
+	_, err = AnalyseOwnership(OwnershipOptions{
+		BaseOptions: utils.BaseOptions{
+			RepoDir:        repoDir,
+			Branch:         "main",
+			ExcludePresets: "tests",
+		},
+		MinDuplicateLines: 2,
+		CommitId:          commit.CommitId,
+	}, nil)
+	require.NotNil(t, err)
+}
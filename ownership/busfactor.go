@@ -0,0 +1,165 @@
+package ownership
+
+import (
+	"sort"
+
+	"github.com/flaviostutz/gitwho/utils"
+)
+
+type BusFactorOptions struct {
+	OwnershipTreeOptions
+	// ActiveSince authors without commits since this date are considered inactive. If empty, all authors are considered active
+	ActiveSince string `json:"active_since"`
+}
+
+// BusFactorNode knowledge concentration of a directory (or file) considering all the files inside it
+type BusFactorNode struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	IsFile     bool   `json:"is_file"`
+	TotalLines int    `json:"total_lines"`
+	// BusFactor min number of authors that together own more than 50% of the lines
+	BusFactor int `json:"bus_factor"`
+	// MainOwners the authors that together own more than 50% of the lines
+	MainOwners []string `json:"main_owners"`
+	// InactiveMainOwners main owners that have no commits since ActiveSince
+	InactiveMainOwners []string `json:"inactive_main_owners"`
+	// InactiveLines lines owned by authors that have no commits since ActiveSince
+	InactiveLines int `json:"inactive_lines"`
+	// Orphaned all main owners are inactive
+	Orphaned bool             `json:"orphaned"`
+	Children []*BusFactorNode `json:"children"`
+}
+
+type BusFactorResult struct {
+	Commit utils.CommitInfo `json:"commit"`
+	// ActiveAuthors authors with commits since ActiveSince
+	ActiveAuthors []string       `json:"active_authors"`
+	Root          *BusFactorNode `json:"root"`
+}
+
+// AnalyseBusFactor calculates the bus factor of each directory based on
+// the ownership of the lines and the authors that are still active
+func AnalyseBusFactor(opts BusFactorOptions, progressChan chan<- utils.ProgressInfo) (BusFactorResult, error) {
+	oresult, err := AnalyseOwnership(opts.OwnershipOptions, progressChan)
+	if err != nil {
+		return BusFactorResult{}, err
+	}
+	tree := BuildOwnershipTree(oresult, opts.MaxDepth, opts.IncludeFiles)
+
+	var activeAuthors map[string]bool
+	if opts.ActiveSince != "" {
+		activeAuthors, err = activeAuthorsSince(opts.OwnershipOptions, opts.ActiveSince)
+		if err != nil {
+			return BusFactorResult{}, err
+		}
+	}
+
+	result := BusFactorResult{
+		Commit:        oresult.Commit,
+		ActiveAuthors: make([]string, 0),
+		Root:          BuildBusFactorTree(tree, activeAuthors),
+	}
+	for authorName := range activeAuthors {
+		result.ActiveAuthors = append(result.ActiveAuthors, authorName)
+	}
+	sort.Strings(result.ActiveAuthors)
+	return result, nil
+}
+
+// activeAuthorsSince authors with commits since a date, resolved by the identities.
+// Commits of ignored revisions don't make their authors active
+func activeAuthorsSince(opts OwnershipOptions, since string) (map[string]bool, error) {
+	identities, err := utils.NewIdentityResolver(opts.RepoDir, opts.IdentitiesFile)
+	if err != nil {
+		return nil, err
+	}
+	identities.AddIdentities(opts.Identities)
+
+	gitOpts, err := utils.NewGitOptions(opts.BaseOptions)
+	if err != nil {
+		return nil, err
+	}
+	git, err := utils.NewGitBackend(opts.GitBackend, opts.RepoDir, gitOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer git.Close()
+
+	commits, err := git.CommitAuthorsInDateRange(opts.CommitId, since, "")
+	if err != nil {
+		return nil, err
+	}
+	activeAuthors := make(map[string]bool, 0)
+	for _, commit := range commits {
+		if gitOpts.IsIgnoredRev(commit.CommitId) {
+			continue
+		}
+		commit = identities.ResolveCommitInfo(commit)
+		activeAuthors[commit.AuthorName] = true
+	}
+	return activeAuthors, nil
+}
+
+// BuildBusFactorTree calculates the bus factor for each node of the ownership tree.
+// If activeAuthors is nil, all authors are considered active
+func BuildBusFactorTree(node *OwnershipNode, activeAuthors map[string]bool) *BusFactorNode {
+	busFactor, mainOwners := CalcBusFactor(node.AuthorsLines, node.TotalLines)
+	bnode := &BusFactorNode{
+		Name:               node.Name,
+		Path:               node.Path,
+		IsFile:             node.IsFile,
+		TotalLines:         node.TotalLines,
+		BusFactor:          busFactor,
+		MainOwners:         mainOwners,
+		InactiveMainOwners: make([]string, 0),
+		Children:           make([]*BusFactorNode, 0),
+	}
+
+	if activeAuthors != nil {
+		for _, authorLines := range node.AuthorsLines {
+			if !activeAuthors[authorLines.AuthorName] {
+				bnode.InactiveLines += authorLines.OwnedLinesTotal
+			}
+		}
+		for _, owner := range mainOwners {
+			if !activeAuthors[owner] {
+				bnode.InactiveMainOwners = append(bnode.InactiveMainOwners, owner)
+			}
+		}
+		bnode.Orphaned = len(mainOwners) > 0 && len(bnode.InactiveMainOwners) == len(mainOwners)
+	}
+
+	for _, child := range node.Children {
+		bnode.Children = append(bnode.Children, BuildBusFactorTree(child, activeAuthors))
+	}
+	return bnode
+}
+
+// CalcBusFactor returns the min number of authors that together own more
+// than 50% of the lines and who they are. authorsLines must be sorted by owned lines
+func CalcBusFactor(authorsLines []AuthorLines, totalLines int) (int, []string) {
+	mainOwners := make([]string, 0)
+	ownedLines := 0
+	for _, authorLines := range authorsLines {
+		if 2*ownedLines > totalLines {
+			break
+		}
+		mainOwners = append(mainOwners, authorLines.AuthorName)
+		ownedLines += authorLines.OwnedLinesTotal
+	}
+	return len(mainOwners), mainOwners
+}
+
+// OrphanedNodes returns the topmost nodes that are orphaned. Children
+// of an orphaned node are not returned
+func (n *BusFactorNode) OrphanedNodes() []*BusFactorNode {
+	if n.Orphaned {
+		return []*BusFactorNode{n}
+	}
+	nodes := make([]*BusFactorNode, 0)
+	for _, child := range n.Children {
+		nodes = append(nodes, child.OrphanedNodes()...)
+	}
+	return nodes
+}
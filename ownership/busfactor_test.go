@@ -0,0 +1,116 @@
+package ownership
+
+import (
+	"testing"
+
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalcBusFactor(t *testing.T) {
+	bf, owners := CalcBusFactor([]AuthorLines{
+		{AuthorName: "a", OwnedLinesTotal: 4},
+		{AuthorName: "b", OwnedLinesTotal: 3},
+		{AuthorName: "c", OwnedLinesTotal: 3},
+	}, 10)
+	require.Equal(t, 2, bf)
+	require.Equal(t, []string{"a", "b"}, owners)
+
+	bf, owners = CalcBusFactor([]AuthorLines{
+		{AuthorName: "a", OwnedLinesTotal: 6},
+		{AuthorName: "b", OwnedLinesTotal: 4},
+	}, 10)
+	require.Equal(t, 1, bf)
+	require.Equal(t, []string{"a"}, owners)
+
+	// exactly 50% is not enough
+	bf, _ = CalcBusFactor([]AuthorLines{
+		{AuthorName: "a", OwnedLinesTotal: 5},
+		{AuthorName: "b", OwnedLinesTotal: 5},
+	}, 10)
+	require.Equal(t, 2, bf)
+
+	bf, owners = CalcBusFactor([]AuthorLines{}, 0)
+	require.Equal(t, 0, bf)
+	require.Empty(t, owners)
+}
+
+func TestAnalyseBusFactor(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	opts := BusFactorOptions{
+		OwnershipTreeOptions: OwnershipTreeOptions{
+			OwnershipOptions: OwnershipOptions{
+				BaseOptions: utils.BaseOptions{
+					RepoDir: repoDir,
+					Branch:  "main",
+				},
+				MinDuplicateLines: 2,
+				CommitId:          commit.CommitId,
+			},
+			IncludeFiles: true,
+		},
+		ActiveSince: "1 year ago",
+	}
+	result, err := AnalyseBusFactor(opts, nil)
+	require.Nil(t, err)
+	require.Equal(t, []string{"author1", "author2", "author3"}, result.ActiveAuthors)
+	require.Equal(t, 1, result.Root.BusFactor)
+	require.Equal(t, []string{"author3"}, result.Root.MainOwners)
+	require.Empty(t, result.Root.OrphanedNodes())
+
+	// file1 is owned by author1 and author2 in equal parts
+	file1 := result.Root.Children[1]
+	require.Equal(t, "file1", file1.Path)
+	require.Equal(t, 2, file1.BusFactor)
+
+	// active authors are the same with the go-git backend
+	opts.GitBackend = utils.GitBackendGoGit
+	goResult, err := AnalyseBusFactor(opts, nil)
+	require.Nil(t, err)
+	require.Equal(t, result.ActiveAuthors, goResult.ActiveAuthors)
+}
+
+func TestBuildBusFactorTreeOrphaned(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	tree, err := AnalyseOwnershipTree(OwnershipTreeOptions{
+		OwnershipOptions: OwnershipOptions{
+			BaseOptions: utils.BaseOptions{
+				RepoDir: repoDir,
+				Branch:  "main",
+			},
+			MinDuplicateLines: 2,
+			CommitId:          commit.CommitId,
+		},
+		IncludeFiles: true,
+	}, nil)
+	require.Nil(t, err)
+
+	// author3 is not active anymore
+	root := BuildBusFactorTree(tree, map[string]bool{"author1": true, "author2": true})
+	require.True(t, root.Orphaned)
+	require.Equal(t, 5, root.InactiveLines)
+	require.Equal(t, []string{"author3"}, root.InactiveMainOwners)
+	require.False(t, root.Children[1].Orphaned)
+
+	// only author1 is active
+	root = BuildBusFactorTree(tree, map[string]bool{"author1": true})
+	require.True(t, root.Orphaned)
+	require.True(t, root.Children[0].Orphaned)
+	require.False(t, root.Children[1].Orphaned)
+	require.Equal(t, 1, root.Children[1].InactiveLines)
+
+	// all authors active if not defined
+	root = BuildBusFactorTree(tree, nil)
+	require.False(t, root.Orphaned)
+	require.Equal(t, 0, root.InactiveLines)
+}
@@ -10,7 +10,9 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-var cacheTable = "GITWHO_OWNERSHIP_CACHE"
+// results are stored as json, so this table must be renamed
+// when the json attributes of OwnershipResult are changed
+var cacheTable = "GITWHO_OWNERSHIP_CACHE_V5"
 
 func GetFromCache(opts OwnershipOptions) (*OwnershipResult, error) {
 	// logrus.Debugf("Reusing results found in cache file")
@@ -67,7 +69,7 @@ func SaveToCache(opts OwnershipOptions, result OwnershipResult) error {
 }
 
 func getCacheKey(opts OwnershipOptions) string {
-	return fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s:%d",
+	return fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%t:%s:%s:%s:%d:%t",
 		opts.RepoDir,
 		opts.CommitId,
 		opts.Branch,
@@ -75,5 +77,15 @@ func getCacheKey(opts OwnershipOptions) string {
 		opts.AuthorsNotRegex,
 		opts.FilesRegex,
 		opts.FilesNotRegex,
-		opts.MinDuplicateLines)
+		opts.IdentitiesFile,
+		opts.IdentitiesKey(),
+		opts.TeamsFile,
+		opts.TeamsKey(),
+		opts.GitBackend,
+		opts.IgnoreWhitespace,
+		opts.IgnoreRevsFile,
+		opts.IgnoreRevsKey(),
+		opts.ExcludePresets,
+		opts.MinDuplicateLines,
+		opts.DetectMoves)
 }
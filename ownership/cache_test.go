@@ -128,3 +128,39 @@ func getSampleDate() time.Time {
 	}
 	return d
 }
+
+func TestFileCacheOwnership(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+	cacheFile := t.TempDir() + "/gitwho-cache"
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	opts := OwnershipOptions{
+		BaseOptions:       utils.BaseOptions{RepoDir: repoDir, Branch: "main", AuthorsRegex: "author1", CacheFile: cacheFile, CacheTTLSeconds: 60},
+		MinDuplicateLines: 2,
+		CommitId:          commit.CommitId,
+	}
+	_, err = AnalyseOwnership(opts, nil)
+	require.Nil(t, err)
+
+	cachedb, err := utils.NewCacheDB(cacheFile, cacheTable, 60)
+	require.Nil(t, err)
+	value, err := cachedb.GetFileValue(commit.CommitId, "dir1/dir1.1/file2", fileBlameCacheVersion+":"+utils.GitBackendExec)
+	cachedb.Close()
+	require.Nil(t, err)
+	require.NotNil(t, value)
+
+	// different filters reuse the blame of each file
+	opts.AuthorsRegex = "author3"
+	cachedResult, err := AnalyseOwnership(opts, nil)
+	require.Nil(t, err)
+	require.Equal(t, 5, cachedResult.TotalLines)
+
+	opts.CacheFile = ""
+	result, err := AnalyseOwnership(opts, nil)
+	require.Nil(t, err)
+	require.Equal(t, result.AuthorsLines, cachedResult.AuthorsLines)
+	require.Equal(t, result.LinesAgeDaysSum, cachedResult.LinesAgeDaysSum)
+}
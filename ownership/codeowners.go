@@ -0,0 +1,299 @@
+package ownership
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/flaviostutz/gitwho/utils"
+)
+
+type CodeownersOptions struct {
+	OwnershipTreeOptions
+	// MinShare min percentage of the lines of a path an author must own to be one of its owners.
+	// When checking an existing CODEOWNERS file, min percentage the declared owners must own together
+	MinShare float64 `json:"min_share"`
+	// MaxOwners max number of owners per path
+	MaxOwners int `json:"max_owners"`
+	// CheckFile existing CODEOWNERS file to be checked. If empty, rules are generated
+	CheckFile string `json:"check_file"`
+}
+
+type CodeownersResult struct {
+	Commit utils.CommitInfo `json:"commit"`
+	// Checked true if the rules came from an existing CODEOWNERS file
+	Checked bool             `json:"checked"`
+	Rules   []CodeownersRule `json:"rules"`
+}
+
+// CodeownersRule a line of a CODEOWNERS file
+type CodeownersRule struct {
+	Pattern string   `json:"pattern"`
+	Owners  []string `json:"owners"`
+	// TotalLines lines of the files in which this rule is the effective one
+	TotalLines int `json:"total_lines"`
+	// OwnedLines lines owned by the owners of the rule in the files in which this rule is the effective one
+	OwnedLines int `json:"owned_lines"`
+	matcher    *regexp.Regexp
+}
+
+// OwnersShare percentage of lines owned by the owners of the rule
+func (r CodeownersRule) OwnersShare() float64 {
+	if r.TotalLines == 0 {
+		return 0
+	}
+	return 100 * float64(r.OwnedLines) / float64(r.TotalLines)
+}
+
+// AnalyseCodeowners generates CODEOWNERS rules based on the ownership of each directory or,
+// if opts.CheckFile is defined, calculates how much of the code is owned by the declared owners of its rules
+func AnalyseCodeowners(opts CodeownersOptions, progressChan chan<- utils.ProgressInfo) (CodeownersResult, error) {
+	var rules []CodeownersRule
+	if opts.CheckFile != "" {
+		contents, err := os.ReadFile(opts.CheckFile)
+		if err != nil {
+			return CodeownersResult{}, fmt.Errorf("Couldn't read CODEOWNERS file. err=%s", err)
+		}
+		rules, err = ParseCodeowners(string(contents))
+		if err != nil {
+			return CodeownersResult{}, err
+		}
+	}
+	teams, err := utils.NewTeamResolver(opts.TeamsFile)
+	if err != nil {
+		return CodeownersResult{}, err
+	}
+
+	oresult, err := AnalyseOwnership(opts.OwnershipOptions, progressChan)
+	if err != nil {
+		return CodeownersResult{}, err
+	}
+
+	if opts.CheckFile != "" {
+		return CodeownersResult{
+			Commit:  oresult.Commit,
+			Checked: true,
+			Rules:   CheckCodeowners(rules, oresult.FilesOwnership, teams),
+		}, nil
+	}
+
+	tree := BuildOwnershipTree(oresult, opts.MaxDepth, opts.IncludeFiles)
+	rules, err = GenerateCodeowners(tree, opts.MinShare, opts.MaxOwners)
+	if err != nil {
+		return CodeownersResult{}, err
+	}
+	return CodeownersResult{
+		Commit: oresult.Commit,
+		Rules:  CheckCodeowners(rules, oresult.FilesOwnership, teams),
+	}, nil
+}
+
+// GenerateCodeowners creates CODEOWNERS rules for the nodes of the ownership tree.
+// A rule is created only when the owners of a path are different from the owners of its parent.
+// Use CheckCodeowners to calculate the lines owned by the owners of each rule
+func GenerateCodeowners(tree *OwnershipNode, minShare float64, maxOwners int) ([]CodeownersRule, error) {
+	return generateCodeownersRules(tree, minShare, maxOwners, nil)
+}
+
+func generateCodeownersRules(node *OwnershipNode, minShare float64, maxOwners int, parentOwners []string) ([]CodeownersRule, error) {
+	rules := make([]CodeownersRule, 0)
+	if node.TotalLines == 0 {
+		return rules, nil
+	}
+
+	owners := make([]string, 0)
+	for _, authorLines := range node.AuthorsLines {
+		if len(owners) >= maxOwners {
+			break
+		}
+		if 100*float64(authorLines.OwnedLinesTotal)/float64(node.TotalLines) < minShare {
+			break
+		}
+		owners = append(owners, strings.Trim(authorLines.AuthorMail, "<>"))
+	}
+
+	if len(owners) > 0 && strings.Join(owners, " ") != strings.Join(parentOwners, " ") {
+		pattern := codeownersPattern(node)
+		matcher, err := codeownersPatternToRegex(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't create matcher for generated pattern %s. err=%s", pattern, err)
+		}
+		rules = append(rules, CodeownersRule{
+			Pattern: pattern,
+			Owners:  owners,
+			matcher: matcher,
+		})
+		parentOwners = owners
+	}
+
+	for _, child := range node.Children {
+		childRules, err := generateCodeownersRules(child, minShare, maxOwners, parentOwners)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, childRules...)
+	}
+	return rules, nil
+}
+
+// codeownersPattern pattern that matches the path of the node. Spaces are escaped
+// with a backslash so that they aren't taken as the separator between pattern and owners
+func codeownersPattern(node *OwnershipNode) string {
+	if node.Path == "." {
+		return "*"
+	}
+	path := strings.ReplaceAll(node.Path, " ", `\ `)
+	if node.IsFile {
+		return "/" + path
+	}
+	return "/" + path + "/"
+}
+
+// ParseCodeowners reads the rules of a CODEOWNERS file
+func ParseCodeowners(contents string) ([]CodeownersRule, error) {
+	rules := make([]CodeownersRule, 0)
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		// comments and gitlab sections
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i != -1 {
+			line = line[:i]
+		}
+		fields := codeownersFields(line)
+		matcher, err := codeownersPatternToRegex(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid CODEOWNERS pattern %s. err=%s", fields[0], err)
+		}
+		rules = append(rules, CodeownersRule{
+			Pattern: fields[0],
+			Owners:  fields[1:],
+			matcher: matcher,
+		})
+	}
+	return rules, nil
+}
+
+// codeownersFields splits a CODEOWNERS line by whitespace not escaped with a backslash
+func codeownersFields(line string) []string {
+	fields := make([]string, 0)
+	field := ""
+	escaped := false
+	for _, c := range line {
+		switch {
+		case escaped:
+			field += string(c)
+			escaped = false
+		case c == '\\':
+			field += string(c)
+			escaped = true
+		case c == ' ' || c == '\t':
+			if field != "" {
+				fields = append(fields, field)
+			}
+			field = ""
+		default:
+			field += string(c)
+		}
+	}
+	if field != "" {
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// FailedRules rules whose owners own less than minShare percent of the lines of their files
+func (r CodeownersResult) FailedRules(minShare float64) []CodeownersRule {
+	failed := make([]CodeownersRule, 0)
+	for _, rule := range r.Rules {
+		if rule.TotalLines > 0 && rule.OwnersShare() < minShare {
+			failed = append(failed, rule)
+		}
+	}
+	return failed
+}
+
+// CheckCodeowners calculates the lines owned by the declared owners of each rule. As in
+// GitHub/GitLab, the last rule that matches a file is the one that is applied to it.
+// Owners are matched by author mail, author name or mail user. If teams are defined, owners
+// such as "@org/team" are matched against the authors of the team
+func CheckCodeowners(rules []CodeownersRule, filesOwnership []FileOwnership, teams *utils.TeamResolver) []CodeownersRule {
+	checked := make([]CodeownersRule, len(rules))
+	copy(checked, rules)
+	for _, fileOwnership := range filesOwnership {
+		ruleIndex := -1
+		for i := len(checked) - 1; i >= 0; i-- {
+			if checked[i].matcher != nil && checked[i].matcher.MatchString(fileOwnership.FilePath) {
+				ruleIndex = i
+				break
+			}
+		}
+		if ruleIndex == -1 {
+			continue
+		}
+		rule := &checked[ruleIndex]
+		rule.TotalLines += fileOwnership.TotalLines
+		for _, authorLines := range fileOwnership.AuthorsLines {
+			if isCodeowner(rule.Owners, authorLines, teams) {
+				rule.OwnedLines += authorLines.OwnedLinesTotal
+			}
+		}
+	}
+	return checked
+}
+
+func isCodeowner(owners []string, authorLines AuthorLines, teams *utils.TeamResolver) bool {
+	mail := strings.ToLower(strings.Trim(authorLines.AuthorMail, "<>"))
+	mailUser := strings.Split(mail, "@")[0]
+	name := strings.ToLower(authorLines.AuthorName)
+	team := strings.ToLower(teams.TeamOf(authorLines.AuthorName, authorLines.AuthorMail))
+	for _, owner := range owners {
+		owner = strings.ToLower(strings.TrimPrefix(owner, "@"))
+		if owner == mail || owner == name || owner == mailUser {
+			return true
+		}
+		if teams != nil && (owner == team || strings.HasSuffix(owner, "/"+team)) {
+			return true
+		}
+	}
+	return false
+}
+
+// codeownersPatternToRegex converts a CODEOWNERS pattern (gitignore like) to a regex
+// that matches the paths of the files affected by it
+func codeownersPatternToRegex(pattern string) (*regexp.Regexp, error) {
+	if pattern == "*" {
+		return regexp.Compile(".*")
+	}
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	// patterns with a slash are relative to the repo root
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	// escaped spaces
+	pattern = strings.ReplaceAll(pattern, `\ `, " ")
+
+	reStr := regexp.QuoteMeta(pattern)
+	reStr = strings.ReplaceAll(reStr, `\*\*/`, "(.*/)?")
+	reStr = strings.ReplaceAll(reStr, `/\*\*`, "(/.*)?")
+	reStr = strings.ReplaceAll(reStr, `\*\*`, ".*")
+	reStr = strings.ReplaceAll(reStr, `\*`, "[^/]*")
+	reStr = strings.ReplaceAll(reStr, `\?`, "[^/]")
+
+	prefix := "^(.*/)?"
+	if anchored {
+		prefix = "^"
+	}
+	// a pattern that matches a directory affects all files inside it
+	suffix := "(/.*)?$"
+	if dirOnly {
+		suffix = "/.*$"
+	}
+	// "docs/*" matches files directly inside docs, but not in its subdirectories
+	if strings.HasSuffix(pattern, "/*") {
+		suffix = "$"
+	}
+	return regexp.Compile(prefix + reStr + suffix)
+}
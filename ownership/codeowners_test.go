@@ -0,0 +1,160 @@
+package ownership
+
+import (
+	"os"
+	"testing"
+
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeownersPatternToRegex(t *testing.T) {
+	cases := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"*", "file1", true},
+		{"*", "dir1/dir1.1/file2", true},
+		{"*.go", "utils/git.go", true},
+		{"*.go", "utils/git.gox", false},
+		{"/file1", "file1", true},
+		{"/file1", "dir1/file1", false},
+		{"file1", "dir1/file1", true},
+		{"/dir1/", "dir1/dir1.1/file2", true},
+		{"/dir1/", "dir1", false},
+		{"dir1/dir1.1", "dir1/dir1.1/file2", true},
+		{"dir1/dir1.1", "other/dir1/dir1.1/file2", false},
+		{"docs/*", "docs/getting-started.md", true},
+		{"docs/*", "docs/build-app/troubleshooting.md", false},
+		{"**/logs", "build/logs/a.log", true},
+		{"/build/**/logs", "build/x/y/logs/a.log", true},
+		{`/my\ dir/`, "my dir/file1", true},
+		{`/my\ dir/`, "my/file1", false},
+	}
+	for _, c := range cases {
+		re, err := codeownersPatternToRegex(c.pattern)
+		require.Nil(t, err)
+		require.Equal(t, c.expected, re.MatchString(c.path), "pattern=%s path=%s", c.pattern, c.path)
+	}
+}
+
+func TestAnalyseCodeownersGenerate(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	opts := CodeownersOptions{
+		OwnershipTreeOptions: OwnershipTreeOptions{
+			OwnershipOptions: OwnershipOptions{
+				BaseOptions: utils.BaseOptions{
+					RepoDir: repoDir,
+					Branch:  "main",
+				},
+				MinDuplicateLines: 2,
+				CommitId:          commit.CommitId,
+			},
+			IncludeFiles: true,
+		},
+		MinShare:  40,
+		MaxOwners: 2,
+	}
+	result, err := AnalyseCodeowners(opts, nil)
+	require.Nil(t, err)
+	require.False(t, result.Checked)
+
+	// dir1 has the same owners as the root, so no rule is needed for it
+	require.Equal(t, 2, len(result.Rules))
+	require.Equal(t, "*", result.Rules[0].Pattern)
+	require.Equal(t, []string{"author3@mail.com"}, result.Rules[0].Owners)
+	require.Equal(t, 5, result.Rules[0].TotalLines)
+	require.Equal(t, 5, result.Rules[0].OwnedLines)
+	require.Equal(t, "/file1", result.Rules[1].Pattern)
+	require.Equal(t, []string{"author1@mail.com", "author2@mail.com"}, result.Rules[1].Owners)
+	require.Equal(t, float64(100), result.Rules[1].OwnersShare())
+}
+
+func TestAnalyseCodeownersCheck(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	codeownersFile := t.TempDir() + "/CODEOWNERS"
+	err = os.WriteFile(codeownersFile, []byte(`# test
+*       @author3
+/file1  @org/squad-a author9@mail.com # trailing comment
+`), 0644)
+	require.Nil(t, err)
+
+	teamsFile := t.TempDir() + "/teams.yml"
+	err = os.WriteFile(teamsFile, []byte("squad-a:\n  - author1\n"), 0644)
+	require.Nil(t, err)
+
+	opts := CodeownersOptions{
+		OwnershipTreeOptions: OwnershipTreeOptions{
+			OwnershipOptions: OwnershipOptions{
+				BaseOptions: utils.BaseOptions{
+					RepoDir:   repoDir,
+					Branch:    "main",
+					TeamsFile: teamsFile,
+				},
+				MinDuplicateLines: 2,
+				CommitId:          commit.CommitId,
+			},
+		},
+		CheckFile: codeownersFile,
+	}
+	result, err := AnalyseCodeowners(opts, nil)
+	require.Nil(t, err)
+	require.True(t, result.Checked)
+	require.Equal(t, 2, len(result.Rules))
+
+	require.Equal(t, "*", result.Rules[0].Pattern)
+	require.Equal(t, 5, result.Rules[0].TotalLines)
+	require.Equal(t, 5, result.Rules[0].OwnedLines)
+
+	require.Equal(t, "/file1", result.Rules[1].Pattern)
+	require.Equal(t, []string{"@org/squad-a", "author9@mail.com"}, result.Rules[1].Owners)
+	require.Equal(t, 2, result.Rules[1].TotalLines)
+	require.Equal(t, 1, result.Rules[1].OwnedLines)
+}
+
+func TestGenerateCodeownersEscapesSpaces(t *testing.T) {
+	tree := &OwnershipNode{
+		Path:         ".",
+		TotalLines:   2,
+		AuthorsLines: []AuthorLines{{AuthorName: "author1", AuthorMail: "<author1@mail.com>", OwnedLinesTotal: 2}},
+		Children: []*OwnershipNode{{
+			Path:         "my dir",
+			TotalLines:   1,
+			AuthorsLines: []AuthorLines{{AuthorName: "author2", AuthorMail: "<author2@mail.com>", OwnedLinesTotal: 1}},
+		}},
+	}
+	rules, err := GenerateCodeowners(tree, 50, 1)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(rules))
+	require.Equal(t, `/my\ dir/`, rules[1].Pattern)
+	require.True(t, rules[1].matcher.MatchString("my dir/file1"))
+
+	// generated rules can be parsed back
+	parsed, err := ParseCodeowners(rules[1].Pattern + " author2@mail.com\n")
+	require.Nil(t, err)
+	require.Equal(t, 1, len(parsed))
+	require.Equal(t, `/my\ dir/`, parsed[0].Pattern)
+	require.Equal(t, []string{"author2@mail.com"}, parsed[0].Owners)
+}
+
+func TestCodeownersFailedRules(t *testing.T) {
+	result := CodeownersResult{Rules: []CodeownersRule{
+		{Pattern: "*", TotalLines: 10, OwnedLines: 8},
+		{Pattern: "/dir1/", TotalLines: 10, OwnedLines: 2},
+		{Pattern: "/empty/", TotalLines: 0},
+	}}
+	failed := result.FailedRules(50)
+	require.Equal(t, 1, len(failed))
+	require.Equal(t, "/dir1/", failed[0].Pattern)
+}
@@ -0,0 +1,334 @@
+package ownership
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/flaviostutz/gitwho/utils"
+)
+
+type LeaveOptions struct {
+	OwnershipTreeOptions
+	// LeavingAuthors regex matching the name or mail of the authors that would leave
+	LeavingAuthors string `json:"leaving_authors"`
+	// MinActiveShare min percentage of the lines of an area that should be owned by active authors.
+	// Areas that drop below it when the leaving authors are gone are reported
+	MinActiveShare float64 `json:"min_active_share"`
+	// ActiveSince authors without commits since this date are considered inactive and the commits
+	// since this date are used to find successors. If empty, all authors are considered active
+	ActiveSince string `json:"active_since"`
+	// MaxSuccessors max number of successors suggested for each area
+	MaxSuccessors int `json:"max_successors"`
+}
+
+// LeaveSuccessor active author that could take over an area from the leaving authors
+type LeaveSuccessor struct {
+	AuthorName string `json:"author_name"`
+	AuthorMail string `json:"author_mail"`
+	// OwnedLines lines of the area owned by the author
+	OwnedLines int `json:"owned_lines"`
+	// RecentCommits commits of the author that changed files of the area since ActiveSince
+	RecentCommits int `json:"recent_commits"`
+	// Score from 0 to 100. Half for the owned lines and half for the recent commits, relative to the other candidates
+	Score float64 `json:"score"`
+}
+
+// LeaveArea directory (or file) that would drop below the min share of lines owned by active authors
+type LeaveArea struct {
+	Path       string `json:"path"`
+	IsFile     bool   `json:"is_file"`
+	TotalLines int    `json:"total_lines"`
+	// OrphanedLines lines owned by the leaving authors
+	OrphanedLines int `json:"orphaned_lines"`
+	// ActiveShareBefore percentage of the lines owned by active authors
+	ActiveShareBefore float64 `json:"active_share_before"`
+	// ActiveShareAfter percentage of the lines owned by active authors after the leaving authors are gone
+	ActiveShareAfter float64          `json:"active_share_after"`
+	Successors       []LeaveSuccessor `json:"successors"`
+}
+
+type LeaveResult struct {
+	Commit utils.CommitInfo `json:"commit"`
+	// LeavingAuthors authors matching LeavingAuthors that own lines in the repo
+	LeavingAuthors []string `json:"leaving_authors"`
+	// ActiveAuthors authors with commits since ActiveSince, including the leaving ones
+	ActiveAuthors []string `json:"active_authors"`
+	TotalLines    int      `json:"total_lines"`
+	// OrphanedLines lines owned by the leaving authors
+	OrphanedLines     int     `json:"orphaned_lines"`
+	ActiveShareBefore float64 `json:"active_share_before"`
+	ActiveShareAfter  float64 `json:"active_share_after"`
+	// Areas that drop below MinActiveShare, in tree order (biggest directories first)
+	Areas []LeaveArea `json:"areas"`
+}
+
+// authorCommitFiles files changed by a commit, along with its resolved author
+type authorCommitFiles struct {
+	authorName string
+	authorMail string
+	files      []string
+}
+
+// AnalyseLeave simulates what happens with the ownership of each directory if the
+// authors matching opts.LeavingAuthors leave, and suggests who could take over
+func AnalyseLeave(opts LeaveOptions, progressChan chan<- utils.ProgressInfo) (LeaveResult, error) {
+	if opts.LeavingAuthors == "" {
+		return LeaveResult{}, fmt.Errorf("Leaving authors regex is required")
+	}
+	leavingRe, err := regexp.Compile(opts.LeavingAuthors)
+	if err != nil {
+		return LeaveResult{}, fmt.Errorf("Invalid leaving authors regex. err=%s", err)
+	}
+	if opts.MinActiveShare < 0 || opts.MinActiveShare > 100 {
+		return LeaveResult{}, fmt.Errorf("Min active share should be between 0 and 100")
+	}
+
+	oresult, err := AnalyseOwnership(opts.OwnershipOptions, progressChan)
+	if err != nil {
+		return LeaveResult{}, err
+	}
+	tree := BuildOwnershipTree(oresult, opts.MaxDepth, opts.IncludeFiles)
+
+	var activeAuthors map[string]bool
+	recentCommits := make([]authorCommitFiles, 0)
+	if opts.ActiveSince != "" {
+		recentCommits, err = recentAuthorCommits(opts.OwnershipOptions, opts.ActiveSince, true)
+		if err != nil {
+			return LeaveResult{}, err
+		}
+		activeAuthors = make(map[string]bool, 0)
+		for _, commit := range recentCommits {
+			activeAuthors[commit.authorName] = true
+		}
+	}
+
+	leaving := make(map[string]bool, 0)
+	for _, authorLines := range tree.AuthorsLines {
+		if leavingRe.MatchString(authorLines.AuthorName) || leavingRe.MatchString(authorLines.AuthorMail) {
+			leaving[authorLines.AuthorName] = true
+		}
+	}
+
+	result := calcLeave(tree, leaving, activeAuthors, recentCommits, opts.MinActiveShare, opts.MaxSuccessors)
+	result.Commit = oresult.Commit
+	return result, nil
+}
+
+// calcLeave calculates the areas of the ownership tree that drop below minActiveShare percent of
+// lines owned by active authors if the leaving authors are gone. If activeAuthors is nil, all authors
+// are considered active. Successors are ranked by the lines they own in the area and by their recent commits
+func calcLeave(tree *OwnershipNode, leaving map[string]bool, activeAuthors map[string]bool, recentCommits []authorCommitFiles, minActiveShare float64, maxSuccessors int) LeaveResult {
+	result := LeaveResult{
+		LeavingAuthors: make([]string, 0),
+		ActiveAuthors:  make([]string, 0),
+		TotalLines:     tree.TotalLines,
+		Areas:          make([]LeaveArea, 0),
+	}
+	for authorName := range leaving {
+		result.LeavingAuthors = append(result.LeavingAuthors, authorName)
+	}
+	sort.Strings(result.LeavingAuthors)
+	for authorName := range activeAuthors {
+		result.ActiveAuthors = append(result.ActiveAuthors, authorName)
+	}
+	sort.Strings(result.ActiveAuthors)
+
+	isActive := func(authorName string) bool {
+		return activeAuthors == nil || activeAuthors[authorName]
+	}
+
+	var visit func(node *OwnershipNode)
+	visit = func(node *OwnershipNode) {
+		area := LeaveArea{
+			Path:       node.Path,
+			IsFile:     node.IsFile,
+			TotalLines: node.TotalLines,
+			Successors: make([]LeaveSuccessor, 0),
+		}
+		activeLines, remainingLines := 0, 0
+		for _, authorLines := range node.AuthorsLines {
+			if leaving[authorLines.AuthorName] {
+				area.OrphanedLines += authorLines.OwnedLinesTotal
+			}
+			if isActive(authorLines.AuthorName) {
+				activeLines += authorLines.OwnedLinesTotal
+				if !leaving[authorLines.AuthorName] {
+					remainingLines += authorLines.OwnedLinesTotal
+				}
+			}
+		}
+		area.ActiveShareBefore = roundPerc(activeLines, node.TotalLines)
+		area.ActiveShareAfter = roundPerc(remainingLines, node.TotalLines)
+
+		if node.Path == "." {
+			result.OrphanedLines = area.OrphanedLines
+			result.ActiveShareBefore = area.ActiveShareBefore
+			result.ActiveShareAfter = area.ActiveShareAfter
+		}
+
+		// only areas that were fine before and that are affected by the leaving authors
+		if area.OrphanedLines > 0 && area.ActiveShareBefore >= minActiveShare && area.ActiveShareAfter < minActiveShare {
+			area.Successors = leaveSuccessors(node, leaving, isActive, recentCommits, maxSuccessors)
+			result.Areas = append(result.Areas, area)
+		}
+
+		for _, child := range node.Children {
+			visit(child)
+		}
+	}
+	visit(tree)
+	return result
+}
+
+// leaveSuccessors active authors that stay, ranked by the lines they own in
+// the area and by the number of recent commits that changed files in the area
+func leaveSuccessors(node *OwnershipNode, leaving map[string]bool, isActive func(string) bool, recentCommits []authorCommitFiles, maxSuccessors int) []LeaveSuccessor {
+	candidates := make(map[string]LeaveSuccessor, 0)
+	for _, authorLines := range node.AuthorsLines {
+		if leaving[authorLines.AuthorName] || !isActive(authorLines.AuthorName) {
+			continue
+		}
+		candidates[authorLines.AuthorName] = LeaveSuccessor{
+			AuthorName: authorLines.AuthorName,
+			AuthorMail: authorLines.AuthorMail,
+			OwnedLines: authorLines.OwnedLinesTotal,
+		}
+	}
+	for _, commit := range recentCommits {
+		if leaving[commit.authorName] || !commitChangedArea(commit.files, node.Path) {
+			continue
+		}
+		candidate, ok := candidates[commit.authorName]
+		if !ok {
+			candidate = LeaveSuccessor{AuthorName: commit.authorName, AuthorMail: commit.authorMail}
+		}
+		candidate.RecentCommits++
+		candidates[commit.authorName] = candidate
+	}
+
+	maxOwned, maxCommits := 0, 0
+	for _, candidate := range candidates {
+		if candidate.OwnedLines > maxOwned {
+			maxOwned = candidate.OwnedLines
+		}
+		if candidate.RecentCommits > maxCommits {
+			maxCommits = candidate.RecentCommits
+		}
+	}
+
+	successors := make([]LeaveSuccessor, 0)
+	for _, candidate := range candidates {
+		score := 0.0
+		if maxOwned > 0 {
+			score += 50 * float64(candidate.OwnedLines) / float64(maxOwned)
+		}
+		if maxCommits > 0 {
+			score += 50 * float64(candidate.RecentCommits) / float64(maxCommits)
+		}
+		candidate.Score = math.Round(score*10) / 10
+		successors = append(successors, candidate)
+	}
+	sort.Slice(successors, func(i, j int) bool {
+		if successors[i].Score == successors[j].Score {
+			return successors[i].AuthorName < successors[j].AuthorName
+		}
+		return successors[i].Score > successors[j].Score
+	})
+	if maxSuccessors > 0 && len(successors) > maxSuccessors {
+		successors = successors[:maxSuccessors]
+	}
+	return successors
+}
+
+// recentAuthorCommits commits since a date with their authors resolved by the identities.
+// Ignored revisions are skipped. If withFiles, the files changed by each commit are listed too
+func recentAuthorCommits(opts OwnershipOptions, since string, withFiles bool) ([]authorCommitFiles, error) {
+	identities, err := utils.NewIdentityResolver(opts.RepoDir, opts.IdentitiesFile)
+	if err != nil {
+		return nil, err
+	}
+	identities.AddIdentities(opts.Identities)
+
+	gitOpts, err := utils.NewGitOptions(opts.BaseOptions)
+	if err != nil {
+		return nil, err
+	}
+	git, err := utils.NewGitBackend(opts.GitBackend, opts.RepoDir, gitOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer git.Close()
+
+	commits, err := git.CommitAuthorsInDateRange(opts.CommitId, since, "")
+	if err != nil {
+		return nil, err
+	}
+	results := make([]authorCommitFiles, 0, len(commits))
+	for _, commit := range commits {
+		if gitOpts.IsIgnoredRev(commit.CommitId) {
+			continue
+		}
+		commit = identities.ResolveCommitInfo(commit)
+		commitFiles := authorCommitFiles{
+			authorName: commit.AuthorName,
+			authorMail: commit.AuthorMail,
+		}
+		if withFiles {
+			commitFiles.files, err = commitChangedFiles(git, gitOpts, commit.CommitId)
+			if err != nil {
+				return nil, fmt.Errorf("Couldn't get files of commit %s. err=%s", commit.CommitId, err)
+			}
+		}
+		results = append(results, commitFiles)
+	}
+	return results, nil
+}
+
+// commitChangedFiles files changed by a commit. When whitespace is
+// ignored, files in which only whitespace was changed are not returned
+func commitChangedFiles(git utils.GitBackend, gitOpts utils.GitOptions, commitId string) ([]string, error) {
+	files, err := git.DiffTree(commitId)
+	if err != nil || !gitOpts.IgnoreWhitespace {
+		return files, err
+	}
+	changedFiles := make([]string, 0, len(files))
+	for _, file := range files {
+		prevCommitId, err := git.PreviousCommitIdForFile(commitId, file)
+		if err != nil {
+			return nil, err
+		}
+		// added or deleted files
+		_, err = git.TreeFileSize(commitId, file)
+		if prevCommitId == "" || err != nil {
+			changedFiles = append(changedFiles, file)
+			continue
+		}
+		diffs, err := git.DiffFileRevisions(file, prevCommitId, commitId)
+		if err != nil {
+			return nil, err
+		}
+		if len(diffs) > 0 {
+			changedFiles = append(changedFiles, file)
+		}
+	}
+	return changedFiles, nil
+}
+
+func commitChangedArea(files []string, path string) bool {
+	for _, file := range files {
+		if path == "." || file == path || strings.HasPrefix(file, path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func roundPerc(value int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(value)*1000/float64(total)) / 10
+}
@@ -0,0 +1,193 @@
+package ownership
+
+import (
+	"os"
+	"testing"
+
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyseLeave(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	opts := LeaveOptions{
+		OwnershipTreeOptions: OwnershipTreeOptions{
+			OwnershipOptions: OwnershipOptions{
+				BaseOptions: utils.BaseOptions{
+					RepoDir: repoDir,
+					Branch:  "main",
+				},
+				MinDuplicateLines: 2,
+				CommitId:          commit.CommitId,
+			},
+			IncludeFiles: true,
+		},
+		LeavingAuthors: "author3",
+		MinActiveShare: 50,
+		ActiveSince:    "1 year ago",
+		MaxSuccessors:  3,
+	}
+	result, err := AnalyseLeave(opts, nil)
+	require.Nil(t, err)
+	require.Equal(t, []string{"author3"}, result.LeavingAuthors)
+	require.Equal(t, []string{"author1", "author2", "author3"}, result.ActiveAuthors)
+	require.Equal(t, 7, result.TotalLines)
+	require.Equal(t, 5, result.OrphanedLines)
+	require.Equal(t, 100.0, result.ActiveShareBefore)
+	require.Equal(t, 28.6, result.ActiveShareAfter)
+
+	// file1 is owned by author1 and author2, so it is not affected
+	require.Len(t, result.Areas, 4)
+	require.Equal(t, ".", result.Areas[0].Path)
+	require.Equal(t, "dir1", result.Areas[1].Path)
+	require.Equal(t, "dir1/dir1.1/file2", result.Areas[3].Path)
+	require.True(t, result.Areas[3].IsFile)
+	require.Equal(t, 0.0, result.Areas[3].ActiveShareAfter)
+
+	// author1 owns as many lines as author2 in the repo, but has more recent commits
+	successors := result.Areas[0].Successors
+	require.Len(t, successors, 2)
+	require.Equal(t, "author1", successors[0].AuthorName)
+	require.Equal(t, 100.0, successors[0].Score)
+	require.Equal(t, "author2", successors[1].AuthorName)
+
+	// nobody else ever touched dir1
+	require.Empty(t, result.Areas[1].Successors)
+
+	opts.LeavingAuthors = "author1"
+	result, err = AnalyseLeave(opts, nil)
+	require.Nil(t, err)
+	require.Equal(t, 1, result.OrphanedLines)
+	require.Empty(t, result.Areas)
+
+	opts.LeavingAuthors = "("
+	_, err = AnalyseLeave(opts, nil)
+	require.NotNil(t, err)
+}
+
+func TestRecentAuthorCommits(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	opts := OwnershipOptions{
+		BaseOptions: utils.BaseOptions{RepoDir: repoDir, Branch: "main"},
+		CommitId:    commit.CommitId,
+	}
+	execCommits, err := recentAuthorCommits(opts, "1 year ago", true)
+	require.Nil(t, err)
+	require.Equal(t, 5, len(execCommits))
+
+	opts.GitBackend = utils.GitBackendGoGit
+	goCommits, err := recentAuthorCommits(opts, "1 year ago", true)
+	require.Nil(t, err)
+	require.Equal(t, execCommits, goCommits)
+
+	// commits of ignored revisions don't make their authors active
+	commits, err := utils.ExecGetCommitAuthorsInDateRange(repoDir, commit.CommitId, "", "")
+	require.Nil(t, err)
+	ignoreRevs := ""
+	for _, c := range commits {
+		if c.AuthorName == "author3" {
+			ignoreRevs += c.CommitId + "\n"
+		}
+	}
+	opts.GitBackend = ""
+	opts.IgnoreRevsFile = t.TempDir() + "/ignore-revs"
+	err = os.WriteFile(opts.IgnoreRevsFile, []byte(ignoreRevs), 0644)
+	require.Nil(t, err)
+	execCommits, err = recentAuthorCommits(opts, "1 year ago", false)
+	require.Nil(t, err)
+	require.NotEmpty(t, execCommits)
+	for _, c := range execCommits {
+		require.NotEqual(t, "author3", c.authorName)
+		require.Nil(t, c.files)
+	}
+}
+
+func TestCommitChangedFilesIgnoreWhitespace(t *testing.T) {
+	repoDir, err := utils.ResolveTestWhitespaceRepo()
+	require.Nil(t, err)
+
+	commits, err := utils.ExecGetCommitsInDateRange(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	git, err := utils.NewGitBackend(utils.GitBackendExec, repoDir, utils.GitOptions{IgnoreWhitespace: true})
+	require.Nil(t, err)
+	defer git.Close()
+
+	// commit 2 only reformats file1
+	files, err := commitChangedFiles(git, utils.GitOptions{}, commits[1].CommitId)
+	require.Nil(t, err)
+	require.Equal(t, []string{"file1"}, files)
+	files, err = commitChangedFiles(git, utils.GitOptions{IgnoreWhitespace: true}, commits[1].CommitId)
+	require.Nil(t, err)
+	require.Empty(t, files)
+	files, err = commitChangedFiles(git, utils.GitOptions{IgnoreWhitespace: true}, commits[0].CommitId)
+	require.Nil(t, err)
+	require.Equal(t, []string{"file1"}, files)
+}
+
+func TestCalcLeave(t *testing.T) {
+	tree := BuildOwnershipTree(OwnershipResult{
+		FilesOwnership: []FileOwnership{
+			{FilePath: "api/handler.go", TotalLines: 10, AuthorsLines: []AuthorLines{
+				{AuthorName: "alice", OwnedLinesTotal: 8},
+				{AuthorName: "bob", OwnedLinesTotal: 2},
+			}},
+			{FilePath: "api/routes.go", TotalLines: 10, AuthorsLines: []AuthorLines{
+				{AuthorName: "carol", OwnedLinesTotal: 6},
+				{AuthorName: "alice", OwnedLinesTotal: 4},
+			}},
+			{FilePath: "web/index.js", TotalLines: 10, AuthorsLines: []AuthorLines{
+				{AuthorName: "dave", OwnedLinesTotal: 10},
+			}},
+		},
+	}, 0, true)
+
+	recentCommits := []authorCommitFiles{
+		{authorName: "carol", files: []string{"api/handler.go"}},
+		{authorName: "erin", files: []string{"api/handler.go", "web/index.js"}},
+		{authorName: "erin", files: []string{"api/routes.go"}},
+	}
+
+	// dave is inactive, so web was already below the threshold and is not reported
+	active := map[string]bool{"alice": true, "bob": true, "carol": true, "erin": true}
+	result := calcLeave(tree, map[string]bool{"alice": true}, active, recentCommits, 50, 2)
+	require.Equal(t, 12, result.OrphanedLines)
+	require.Equal(t, 66.7, result.ActiveShareBefore)
+	require.Equal(t, 26.7, result.ActiveShareAfter)
+
+	require.Len(t, result.Areas, 3)
+	require.Equal(t, ".", result.Areas[0].Path)
+	require.Equal(t, "api", result.Areas[1].Path)
+	require.Equal(t, 40.0, result.Areas[1].ActiveShareAfter)
+	require.Equal(t, "api/handler.go", result.Areas[2].Path)
+	require.Equal(t, 8, result.Areas[2].OrphanedLines)
+
+	// carol owns most of the remaining lines in api while erin changed it the most recently
+	successors := result.Areas[1].Successors
+	require.Len(t, successors, 2)
+	require.Equal(t, "carol", successors[0].AuthorName)
+	require.Equal(t, 6, successors[0].OwnedLines)
+	require.Equal(t, 1, successors[0].RecentCommits)
+	require.Equal(t, 75.0, successors[0].Score)
+	require.Equal(t, "erin", successors[1].AuthorName)
+	require.Equal(t, 2, successors[1].RecentCommits)
+	require.Equal(t, 50.0, successors[1].Score)
+
+	// all authors are active if not defined
+	result = calcLeave(tree, map[string]bool{"dave": true}, nil, nil, 50, 3)
+	require.Equal(t, 66.7, result.ActiveShareAfter)
+	require.Len(t, result.Areas, 2)
+	require.Equal(t, "web", result.Areas[0].Path)
+	require.Equal(t, "web/index.js", result.Areas[1].Path)
+	require.Empty(t, result.Areas[0].Successors)
+}
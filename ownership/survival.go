@@ -0,0 +1,178 @@
+package ownership
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/flaviostutz/gitwho/utils"
+)
+
+// SurvivalCohort lines written in a period and how many of them still exist in each later snapshot
+type SurvivalCohort struct {
+	// Since the cohort has the lines written after this date (previous snapshot)
+	Since time.Time `json:"since"`
+	// Until the cohort has the lines written until this date (snapshot that ends the period)
+	Until time.Time `json:"until"`
+	// Lines lines written in the period that still existed at its end.
+	// Lines written and removed in the same period are not seen
+	Lines int `json:"lines"`
+	// Surviving lines of the cohort that still exist in each snapshot from the end of the period. Surviving[0] is Lines
+	Surviving []int `json:"surviving"`
+}
+
+// SurvivalCurve survival of the lines written by an author (or by all authors)
+type SurvivalCurve struct {
+	// AuthorName empty for the curve of all authors
+	AuthorName string `json:"author_name"`
+	AuthorMail string `json:"author_mail"`
+	// Lines lines written in all the cohorts
+	Lines int `json:"lines"`
+	// Survival Kaplan-Meier estimate of the share (0-1) of the lines that survive after each number of periods.
+	// Survival[0] is always 1. Cohorts that were not observed for that long are not considered (censored)
+	Survival []float64 `json:"survival"`
+	// HalfLife number of periods until half of the lines were changed or removed. Zero if not reached
+	HalfLife int              `json:"half_life"`
+	Cohorts  []SurvivalCohort `json:"cohorts"`
+}
+
+type SurvivalResult struct {
+	Period string `json:"period"`
+	// Snapshots commits analysed at each period, oldest first
+	Snapshots []utils.CommitInfo `json:"snapshots"`
+	Total     SurvivalCurve      `json:"total"`
+	// Authors survival curve of each author, the ones that wrote more lines first
+	Authors []SurvivalCurve `json:"authors"`
+}
+
+// AnalyseSurvival tracks how many lines written by each author in each period still
+// exist in the later periods, based on the blame of the ownership timeseries
+func AnalyseSurvival(opts OwnershipTimeseriesOptions, progressChan chan<- utils.ProgressInfo) (SurvivalResult, error) {
+	ownershipResults, err := analyseTimeseriesOwnership(opts, true, progressChan)
+	if err != nil {
+		return SurvivalResult{}, err
+	}
+	result := CalcSurvival(ownershipResults)
+	result.Period = opts.Period
+	return result, nil
+}
+
+// CalcSurvival groups the lines of each snapshot in cohorts by the period in which they were written
+// (between two consecutive snapshots). ownershipResults must be sorted by date, oldest first
+func CalcSurvival(ownershipResults []OwnershipResult) SurvivalResult {
+	result := SurvivalResult{
+		Snapshots: make([]utils.CommitInfo, 0),
+		Authors:   make([]SurvivalCurve, 0),
+	}
+	authorMails := make(map[string]string, 0)
+	for _, oresult := range ownershipResults {
+		result.Snapshots = append(result.Snapshots, oresult.Commit)
+		for _, authorLines := range oresult.AuthorsLines {
+			authorMails[authorLines.AuthorName] = authorLines.AuthorMail
+		}
+	}
+
+	// lines that survived at each snapshot per author and per cohort.
+	// Cohort k has the lines written after snapshot k-1 until snapshot k
+	nrCohorts := len(result.Snapshots)
+	authorsSurviving := make(map[string][][]int, 0)
+	totalSurviving := newCohortsSurviving(nrCohorts)
+	for j, oresult := range ownershipResults {
+		for authorName, linesCreated := range oresult.linesCreatedMap {
+			surviving, ok := authorsSurviving[authorName]
+			if !ok {
+				surviving = newCohortsSurviving(nrCohorts)
+				authorsSurviving[authorName] = surviving
+			}
+			for created, lines := range linesCreated {
+				k := cohortIndex(result.Snapshots, created)
+				// lines written before the first snapshot or dated after the snapshot (rebased commits)
+				if k < 1 || k > j {
+					continue
+				}
+				surviving[k][j-k] += lines
+				totalSurviving[k][j-k] += lines
+			}
+		}
+	}
+
+	result.Total = survivalCurve("", "", result.Snapshots, totalSurviving)
+	for authorName, surviving := range authorsSurviving {
+		curve := survivalCurve(authorName, authorMails[authorName], result.Snapshots, surviving)
+		if curve.Lines == 0 {
+			continue
+		}
+		result.Authors = append(result.Authors, curve)
+	}
+	sort.Slice(result.Authors, func(i, j int) bool {
+		if result.Authors[i].Lines == result.Authors[j].Lines {
+			return result.Authors[i].AuthorName < result.Authors[j].AuthorName
+		}
+		return result.Authors[i].Lines > result.Authors[j].Lines
+	})
+	return result
+}
+
+func newCohortsSurviving(nrCohorts int) [][]int {
+	surviving := make([][]int, nrCohorts)
+	for k := 1; k < nrCohorts; k++ {
+		surviving[k] = make([]int, nrCohorts-k)
+	}
+	return surviving
+}
+
+// cohortIndex index of the first snapshot at or after the time in which a line was written
+func cohortIndex(snapshots []utils.CommitInfo, created int64) int {
+	return sort.Search(len(snapshots), func(i int) bool {
+		return snapshots[i].Date.Unix() >= created
+	})
+}
+
+// survivalCurve builds the cohorts with lines and their Kaplan-Meier survival curve
+func survivalCurve(authorName string, authorMail string, snapshots []utils.CommitInfo, surviving [][]int) SurvivalCurve {
+	curve := SurvivalCurve{
+		AuthorName: authorName,
+		AuthorMail: authorMail,
+		Survival:   make([]float64, 0),
+		Cohorts:    make([]SurvivalCohort, 0),
+	}
+	for k := 1; k < len(surviving); k++ {
+		if surviving[k][0] == 0 {
+			continue
+		}
+		curve.Lines += surviving[k][0]
+		curve.Cohorts = append(curve.Cohorts, SurvivalCohort{
+			Since:     snapshots[k-1].Date,
+			Until:     snapshots[k].Date,
+			Lines:     surviving[k][0],
+			Surviving: surviving[k],
+		})
+	}
+	if curve.Lines == 0 {
+		return curve
+	}
+
+	// the share of lines that survive from one period to the next is calculated only
+	// with the cohorts that were observed for that long, as in Kaplan-Meier estimates
+	survival := 1.0
+	curve.Survival = append(curve.Survival, survival)
+	for t := 1; ; t++ {
+		atRisk, alive := 0, 0
+		for _, cohort := range curve.Cohorts {
+			if len(cohort.Surviving) > t {
+				atRisk += cohort.Surviving[t-1]
+				alive += cohort.Surviving[t]
+			}
+		}
+		if atRisk == 0 {
+			break
+		}
+		// lines might reappear in a cohort (eg: reverts), but survival never grows
+		survival *= math.Min(1, float64(alive)/float64(atRisk))
+		curve.Survival = append(curve.Survival, math.Round(survival*1000)/1000)
+		if curve.HalfLife == 0 && survival <= 0.5 {
+			curve.HalfLife = t
+		}
+	}
+	return curve
+}
@@ -0,0 +1,98 @@
+package ownership
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalcSurvival(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := func(days int) time.Time {
+		return start.AddDate(0, 0, days)
+	}
+	snapshot := func(days int, linesCreated map[string]map[int64]int) OwnershipResult {
+		return OwnershipResult{
+			Commit: utils.CommitInfo{Date: day(days)},
+			AuthorsLines: []AuthorLines{
+				{AuthorName: "a", AuthorMail: "<a@mail.com>"},
+				{AuthorName: "b", AuthorMail: "<b@mail.com>"},
+			},
+			linesCreatedMap: linesCreated,
+		}
+	}
+
+	result := CalcSurvival([]OwnershipResult{
+		snapshot(0, map[string]map[int64]int{
+			"b": {day(-5).Unix(): 7},
+		}),
+		snapshot(10, map[string]map[int64]int{
+			"a": {day(5).Unix(): 10, day(12).Unix(): 2},
+			"b": {day(-5).Unix(): 7},
+		}),
+		snapshot(20, map[string]map[int64]int{
+			"a": {day(5).Unix(): 6, day(15).Unix(): 4},
+			"b": {day(-5).Unix(): 7},
+		}),
+		snapshot(30, map[string]map[int64]int{
+			"a": {day(5).Unix(): 3, day(15).Unix(): 4},
+			"b": {day(-5).Unix(): 7, day(25).Unix(): 5},
+		}),
+	})
+	require.Len(t, result.Snapshots, 4)
+
+	// lines written before the first snapshot and lines dated after the snapshot are ignored
+	require.Equal(t, 19, result.Total.Lines)
+	require.Len(t, result.Total.Cohorts, 3)
+	require.Equal(t, day(0), result.Total.Cohorts[0].Since)
+	require.Equal(t, day(10), result.Total.Cohorts[0].Until)
+	require.Equal(t, []int{10, 6, 3}, result.Total.Cohorts[0].Surviving)
+	require.Equal(t, []int{4, 4}, result.Total.Cohorts[1].Surviving)
+	require.Equal(t, []int{5}, result.Total.Cohorts[2].Surviving)
+
+	// 10 of 14 lines survived one period and 3 of the 6 lines of the only cohort observed for two periods survived
+	require.Equal(t, []float64{1, 0.714, 0.357}, result.Total.Survival)
+	require.Equal(t, 2, result.Total.HalfLife)
+
+	require.Len(t, result.Authors, 2)
+	require.Equal(t, "a", result.Authors[0].AuthorName)
+	require.Equal(t, "<a@mail.com>", result.Authors[0].AuthorMail)
+	require.Equal(t, 14, result.Authors[0].Lines)
+	require.Equal(t, []float64{1, 0.714, 0.357}, result.Authors[0].Survival)
+
+	require.Equal(t, "b", result.Authors[1].AuthorName)
+	require.Equal(t, 5, result.Authors[1].Lines)
+	require.Equal(t, []float64{1}, result.Authors[1].Survival)
+	require.Equal(t, 0, result.Authors[1].HalfLife)
+
+	result = CalcSurvival([]OwnershipResult{})
+	require.Equal(t, 0, result.Total.Lines)
+	require.Empty(t, result.Authors)
+}
+
+func TestAnalyseSurvival(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	result, err := AnalyseSurvival(OwnershipTimeseriesOptions{
+		BaseOptions: utils.BaseOptions{
+			RepoDir: repoDir,
+			Branch:  "main",
+		},
+		MinDuplicateLines: 2,
+		Until:             "now",
+		Period:            "1 second",
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, "1 second", result.Period)
+	require.Len(t, result.Snapshots, 2)
+
+	authorsLines := 0
+	for _, curve := range result.Authors {
+		authorsLines += curve.Lines
+		require.Equal(t, 1.0, curve.Survival[0])
+	}
+	require.Equal(t, result.Total.Lines, authorsLines)
+}
@@ -170,6 +170,7 @@ func ClusterizeAuthors(changesResults []OwnershipResult, numberOfClusters int) (
 				authorLines.OwnedLinesDuplicate += al.OwnedLinesDuplicate
 				authorLines.OwnedLinesDuplicateOriginal += al.OwnedLinesDuplicateOriginal
 				authorLines.OwnedLinesDuplicateOriginalOthers += al.OwnedLinesDuplicateOriginalOthers
+				authorLines.OwnedLinesMoved += al.OwnedLinesMoved
 				authorLines.OwnedLinesTotal += al.OwnedLinesTotal
 			}
 			allAuthorLines = append(allAuthorLines, authorLines)
@@ -0,0 +1,119 @@
+package ownership
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/flaviostutz/gitwho/utils"
+)
+
+type OwnershipTreeOptions struct {
+	OwnershipOptions
+	// MaxDepth max number of directory levels in tree. Deeper files are accounted in the node at MaxDepth. 0 means unlimited
+	MaxDepth int `json:"max_depth"`
+	// IncludeFiles add files as leaves of the tree
+	IncludeFiles bool `json:"include_files"`
+}
+
+// OwnershipNode ownership stats of a directory (or file) considering all the files inside it
+type OwnershipNode struct {
+	Name            string           `json:"name"`
+	Path            string           `json:"path"`
+	IsFile          bool             `json:"is_file"`
+	TotalFiles      int              `json:"total_files"`
+	TotalLines      int              `json:"total_lines"`
+	LinesAgeDaysSum float64          `json:"lines_age_days_sum"`
+	AuthorsLines    []AuthorLines    `json:"authors_lines"`
+	Children        []*OwnershipNode `json:"children"`
+	authorLinesMap  map[string]AuthorLines
+	childrenMap     map[string]*OwnershipNode
+}
+
+// AnalyseOwnershipTree analyses the ownership of a commit and groups the results per directory
+func AnalyseOwnershipTree(opts OwnershipTreeOptions, progressChan chan<- utils.ProgressInfo) (*OwnershipNode, error) {
+	oresult, err := AnalyseOwnership(opts.OwnershipOptions, progressChan)
+	if err != nil {
+		return nil, err
+	}
+	return BuildOwnershipTree(oresult, opts.MaxDepth, opts.IncludeFiles), nil
+}
+
+// BuildOwnershipTree groups the ownership of each file in a tree of directories.
+// The root node has path "." and contains the totals for the whole repo
+func BuildOwnershipTree(oresult OwnershipResult, maxDepth int, includeFiles bool) *OwnershipNode {
+	root := newOwnershipNode(".", ".", false)
+	for _, fileOwnership := range oresult.FilesOwnership {
+		root.add(fileOwnership)
+		parts := strings.Split(fileOwnership.FilePath, "/")
+		node := root
+		for i, name := range parts {
+			isFile := i == len(parts)-1
+			if (isFile && !includeFiles) || (maxDepth > 0 && i >= maxDepth) {
+				break
+			}
+			child, ok := node.childrenMap[name]
+			if !ok {
+				child = newOwnershipNode(name, strings.Join(parts[:i+1], "/"), isFile)
+				node.childrenMap[name] = child
+			}
+			child.add(fileOwnership)
+			node = child
+		}
+	}
+	root.summarise()
+	return root
+}
+
+// AvgLineAgeDays average age of the lines in this node
+func (n *OwnershipNode) AvgLineAgeDays() float64 {
+	if n.TotalLines == 0 {
+		return 0
+	}
+	return n.LinesAgeDaysSum / float64(n.TotalLines)
+}
+
+func newOwnershipNode(name string, path string, isFile bool) *OwnershipNode {
+	return &OwnershipNode{
+		Name:           name,
+		Path:           path,
+		IsFile:         isFile,
+		AuthorsLines:   make([]AuthorLines, 0),
+		Children:       make([]*OwnershipNode, 0),
+		authorLinesMap: make(map[string]AuthorLines, 0),
+		childrenMap:    make(map[string]*OwnershipNode, 0),
+	}
+}
+
+func (n *OwnershipNode) add(fileOwnership FileOwnership) {
+	n.TotalFiles++
+	n.TotalLines += fileOwnership.TotalLines
+	n.LinesAgeDaysSum += fileOwnership.LinesAgeDaysSum
+	for _, fileAuthorLines := range fileOwnership.AuthorsLines {
+		authorLines := n.authorLinesMap[fileAuthorLines.AuthorName]
+		authorLines.AuthorName = fileAuthorLines.AuthorName
+		authorLines.AuthorMail = fileAuthorLines.AuthorMail
+		authorLines.OwnedLinesTotal += fileAuthorLines.OwnedLinesTotal
+		authorLines.OwnedLinesAgeDaysSum += fileAuthorLines.OwnedLinesAgeDaysSum
+		authorLines.OwnedLinesDuplicate += fileAuthorLines.OwnedLinesDuplicate
+		authorLines.OwnedLinesDuplicateOriginal += fileAuthorLines.OwnedLinesDuplicateOriginal
+		authorLines.OwnedLinesDuplicateOriginalOthers += fileAuthorLines.OwnedLinesDuplicateOriginalOthers
+		authorLines.OwnedLinesMoved += fileAuthorLines.OwnedLinesMoved
+		n.authorLinesMap[fileAuthorLines.AuthorName] = authorLines
+	}
+}
+
+// summarise prepares the sorted lists of authors and children recursively
+func (n *OwnershipNode) summarise() {
+	n.AuthorsLines = sortedAuthorsLines(n.authorLinesMap)
+	for _, child := range n.childrenMap {
+		child.summarise()
+		n.Children = append(n.Children, child)
+	}
+	// biggest directories first
+	sort.Slice(n.Children, func(i, j int) bool {
+		if n.Children[i].TotalLines == n.Children[j].TotalLines {
+			return n.Children[i].Name < n.Children[j].Name
+		}
+		return n.Children[i].TotalLines > n.Children[j].TotalLines
+	})
+}
@@ -0,0 +1,61 @@
+package ownership
+
+import (
+	"testing"
+
+	"github.com/flaviostutz/gitwho/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyseOwnershipTree(t *testing.T) {
+	repoDir, err := utils.ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	commit, err := utils.ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	opts := OwnershipTreeOptions{
+		OwnershipOptions: OwnershipOptions{
+			BaseOptions: utils.BaseOptions{
+				RepoDir: repoDir,
+				Branch:  "main",
+			},
+			MinDuplicateLines: 2,
+			CommitId:          commit.CommitId,
+		},
+		IncludeFiles: true,
+	}
+	tree, err := AnalyseOwnershipTree(opts, nil)
+	require.Nil(t, err)
+
+	require.Equal(t, ".", tree.Path)
+	require.Equal(t, 2, tree.TotalFiles)
+	require.Equal(t, 7, tree.TotalLines)
+	require.Equal(t, 3, len(tree.AuthorsLines))
+	require.Equal(t, "author3", tree.AuthorsLines[0].AuthorName)
+	require.Equal(t, 2, len(tree.Children))
+
+	dir1 := tree.Children[0]
+	require.Equal(t, "dir1", dir1.Path)
+	require.False(t, dir1.IsFile)
+	require.Equal(t, 5, dir1.TotalLines)
+	require.Equal(t, 1, len(dir1.AuthorsLines))
+	require.Equal(t, "dir1/dir1.1/file2", dir1.Children[0].Children[0].Path)
+	require.True(t, dir1.Children[0].Children[0].IsFile)
+
+	file1 := tree.Children[1]
+	require.Equal(t, "file1", file1.Path)
+	require.True(t, file1.IsFile)
+	require.Equal(t, 2, file1.TotalLines)
+	require.Equal(t, 2, len(file1.AuthorsLines))
+
+	// limit depth and hide files
+	opts.MaxDepth = 1
+	opts.IncludeFiles = false
+	tree, err = AnalyseOwnershipTree(opts, nil)
+	require.Nil(t, err)
+	require.Equal(t, 7, tree.TotalLines)
+	require.Equal(t, 1, len(tree.Children))
+	require.Equal(t, "dir1", tree.Children[0].Path)
+	require.Equal(t, 0, len(tree.Children[0].Children))
+}
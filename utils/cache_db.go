@@ -8,6 +8,10 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// filesCacheTable stores results of the analysis of individual files in a commit so
+// they can be reused by analyses with different options over the same history
+var filesCacheTable = "GITWHO_FILES_CACHE"
+
 type CacheDB struct {
 	db         *sql.DB
 	ttlSeconds int
@@ -23,6 +27,9 @@ func NewCacheDB(dbFile string, tableName string, ttlSeconds int) (*CacheDB, erro
 	if err != nil {
 		return nil, err
 	}
+	// the same cache is used by multiple analysis workers and
+	// concurrent writes to sqlite fail with "database is locked"
+	db.SetMaxOpenConns(1)
 
 	sql := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
 		"CACHE_KEY" TEXT NOT NULL PRIMARY KEY,
@@ -35,13 +42,29 @@ func NewCacheDB(dbFile string, tableName string, ttlSeconds int) (*CacheDB, erro
 		return nil, err
 	}
 
-	logrus.Debugf("Cleaning up old cache entries")
-	sql = fmt.Sprintf(`DELETE FROM %s WHERE LAST_ACCESS <= DATETIME(CURRENT_TIMESTAMP, '-%d second');`, tableName, ttlSeconds)
+	sql = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		"COMMIT_ID" TEXT NOT NULL,
+		"FILE_PATH" TEXT NOT NULL,
+		"VERSION" TEXT NOT NULL,
+		"CACHE_VALUE" TEXT NOT NULL,
+		"LAST_ACCESS" TIMESTAMP,
+		PRIMARY KEY (COMMIT_ID, FILE_PATH, VERSION)
+		);`, filesCacheTable)
+
 	_, err = db.Exec(sql)
 	if err != nil {
 		return nil, err
 	}
 
+	logrus.Debugf("Cleaning up old cache entries")
+	for _, table := range []string{tableName, filesCacheTable} {
+		sql = fmt.Sprintf(`DELETE FROM %s WHERE LAST_ACCESS <= DATETIME(CURRENT_TIMESTAMP, '-%d second');`, table, ttlSeconds)
+		_, err = db.Exec(sql)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &CacheDB{
 			db:         db,
 			ttlSeconds: ttlSeconds,
@@ -114,6 +137,43 @@ func (c *CacheDB) GetValue(cacheKey string) (*string, error) {
 	return result, nil
 }
 
+// PutFileValue stores the results of the analysis of a file in a specific commit.
+// version identifies the kind of analysis and the format of its contents, so
+// it must be changed whenever the contents stored by the caller change
+func (c *CacheDB) PutFileValue(commitId string, filePath string, version string, cacheContents string) error {
+	if c == nil {
+		return nil
+	}
+	query := fmt.Sprintf(`INSERT OR REPLACE INTO %s (COMMIT_ID, FILE_PATH, VERSION, CACHE_VALUE, LAST_ACCESS) VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP);`, filesCacheTable)
+	_, err := c.db.Exec(query, commitId, filePath, version, cacheContents)
+	return err
+}
+
+// GetFileValue returns the results of the analysis of a file in a specific
+// commit stored with PutFileValue or nil if it's not in cache
+func (c *CacheDB) GetFileValue(commitId string, filePath string, version string) (*string, error) {
+	if c == nil {
+		return nil, nil
+	}
+	query := fmt.Sprintf(`SELECT CACHE_VALUE FROM %s WHERE COMMIT_ID = ? AND FILE_PATH = ? AND VERSION = ? AND LAST_ACCESS > DATETIME(CURRENT_TIMESTAMP, '-%d second');`, filesCacheTable, c.ttlSeconds)
+	resultStr := ""
+	err := c.db.QueryRow(query, commitId, filePath, version).Scan(&resultStr)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// mark last accessed time
+	query = fmt.Sprintf(`UPDATE %s SET LAST_ACCESS = CURRENT_TIMESTAMP WHERE COMMIT_ID = ? AND FILE_PATH = ? AND VERSION = ?`, filesCacheTable)
+	_, err = c.db.Exec(query, commitId, filePath, version)
+	if err != nil {
+		return nil, err
+	}
+	return &resultStr, nil
+}
+
 func (c *CacheDB) Close() {
 	c.db.Close()
 }
@@ -31,3 +31,40 @@ func TestSaveGetCache(t *testing.T) {
 	require.Nil(t, value)
 
 }
+
+func TestSaveGetFileCache(t *testing.T) {
+	os.Remove("gitwho-cache")
+
+	cachedb, err := NewCacheDB("gitwho-cache", "TEST_CACHE", 1)
+	require.Nil(t, err)
+	defer cachedb.Close()
+
+	value, err := cachedb.GetFileValue("commit1", "dir/file1", "v1")
+	require.Nil(t, err)
+	require.Nil(t, value)
+
+	err = cachedb.PutFileValue("commit1", "dir/file1", "v1", "value1")
+	require.Nil(t, err)
+	err = cachedb.PutFileValue("commit1", "dir/file1", "v1", "value2")
+	require.Nil(t, err)
+
+	value, err = cachedb.GetFileValue("commit1", "dir/file1", "v1")
+	require.Nil(t, err)
+	require.NotNil(t, value)
+	require.Equal(t, "value2", *value)
+
+	// other versions and commits are not shared
+	value, err = cachedb.GetFileValue("commit1", "dir/file1", "v2")
+	require.Nil(t, err)
+	require.Nil(t, value)
+	value, err = cachedb.GetFileValue("commit2", "dir/file1", "v1")
+	require.Nil(t, err)
+	require.Nil(t, value)
+
+	time.Sleep(1100 * time.Millisecond)
+
+	// contents expired
+	value, err = cachedb.GetFileValue("commit1", "dir/file1", "v1")
+	require.Nil(t, err)
+	require.Nil(t, value)
+}
@@ -0,0 +1,347 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CatFilePool pool of long lived "git cat-file" and "git check-attr" processes used to read
+// objects of a repo without spawning a new process for each request.
+// It can be used concurrently. Each request takes a process from the pool
+type CatFilePool struct {
+	repoDir string
+	procs   chan *catFileProcs
+}
+
+// catFileProcs "git cat-file --batch-check" for reading object info,
+// "git cat-file --batch" for reading object contents and
+// "git check-attr --stdin" for reading the diff attribute of files
+type catFileProcs struct {
+	check *catFileProc
+	batch *catFileProc
+	attr  *catFileProc
+}
+
+type catFileProc struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// NewCatFilePool creates a pool that keeps up to size idle processes running
+func NewCatFilePool(repoDir string, size int) *CatFilePool {
+	if size < 1 {
+		size = 1
+	}
+	return &CatFilePool{
+		repoDir: repoDir,
+		procs:   make(chan *catFileProcs, size),
+	}
+}
+
+// TreeFileSize size of a file in a commit. Returns an error if the file doesn't exist
+func (p *CatFilePool) TreeFileSize(commitId string, filePath string) (int, error) {
+	size := -1
+	err := p.withProcs(func(procs *catFileProcs) error {
+		objType, objSize, err := procs.check.info(fmt.Sprintf("%s:%s", commitId, filePath))
+		if err != nil {
+			return err
+		}
+		if objType != "blob" {
+			return fmt.Errorf("File doesn't exist. commitId=%s; filePath=%s", commitId, filePath)
+		}
+		size = objSize
+		return nil
+	})
+	return size, err
+}
+
+// IsBinary true if the file is marked as binary in .gitattributes ("binary" or "-diff").
+// Files without the diff attribute are binary if they have a NUL byte in their first 8000 bytes,
+// as in git diff. As in git, attributes are read from the working tree, not from the commit
+func (p *CatFilePool) IsBinary(commitId string, filePath string) (bool, error) {
+	isBinary := false
+	err := p.withProcs(func(procs *catFileProcs) error {
+		diffAttr, err := procs.attr.attribute(filePath)
+		if err != nil {
+			return err
+		}
+		if diffAttr != "unspecified" {
+			objType, _, err := procs.check.info(fmt.Sprintf("%s:%s", commitId, filePath))
+			if err != nil {
+				return err
+			}
+			if objType != "blob" {
+				return fmt.Errorf("File doesn't exist. commitId=%s; filePath=%s", commitId, filePath)
+			}
+			// "set" or a diff driver forces the file to be shown as text
+			isBinary = diffAttr == "unset"
+			return nil
+		}
+
+		objType, contents, err := procs.batch.contents(fmt.Sprintf("%s:%s", commitId, filePath))
+		if err != nil {
+			return err
+		}
+		if objType != "blob" {
+			return fmt.Errorf("File doesn't exist. commitId=%s; filePath=%s", commitId, filePath)
+		}
+		if len(contents) > 8000 {
+			contents = contents[:8000]
+		}
+		isBinary = bytes.IndexByte(contents, 0) != -1
+		return nil
+	})
+	return isBinary, err
+}
+
+// FileContents contents of a file in a commit. Returns an error if the file doesn't exist
+func (p *CatFilePool) FileContents(commitId string, filePath string) (string, error) {
+	result := ""
+	err := p.withProcs(func(procs *catFileProcs) error {
+		objType, contents, err := procs.batch.contents(fmt.Sprintf("%s:%s", commitId, filePath))
+		if err != nil {
+			return err
+		}
+		if objType != "blob" {
+			return fmt.Errorf("File doesn't exist. commitId=%s; filePath=%s", commitId, filePath)
+		}
+		result = string(contents)
+		return nil
+	})
+	return result, err
+}
+
+// CommitInfo author and author date of a commit. The author is mapped using
+// the repo .mailmap, as in "git log --format=%aN"
+func (p *CatFilePool) CommitInfo(commitId string) (CommitInfo, error) {
+	result := CommitInfo{}
+	err := p.withProcs(func(procs *catFileProcs) error {
+		objType, contents, err := procs.batch.contents(commitId)
+		if err != nil {
+			return err
+		}
+		if objType != "commit" {
+			return fmt.Errorf("Commit not found. commitId=%s", commitId)
+		}
+		result, err = parseCommitObject(commitId, string(contents))
+		return err
+	})
+	return result, err
+}
+
+// Close stops all idle processes
+func (p *CatFilePool) Close() {
+	for {
+		select {
+		case procs := <-p.procs:
+			procs.close()
+		default:
+			return
+		}
+	}
+}
+
+func (p *CatFilePool) withProcs(fn func(procs *catFileProcs) error) error {
+	var procs *catFileProcs
+	select {
+	case procs = <-p.procs:
+	default:
+		var err error
+		procs, err = newCatFileProcs(p.repoDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := fn(procs)
+	if _, ok := err.(catFileProtocolError); ok {
+		// the process output can't be trusted anymore
+		procs.close()
+		return err
+	}
+
+	select {
+	case p.procs <- procs:
+	default:
+		procs.close()
+	}
+	return err
+}
+
+func newCatFileProcs(repoDir string) (*catFileProcs, error) {
+	check, err := newCatFileProc(repoDir, "cat-file", "--use-mailmap", "--batch-check")
+	if err != nil {
+		return nil, err
+	}
+	batch, err := newCatFileProc(repoDir, "cat-file", "--use-mailmap", "--batch")
+	if err != nil {
+		check.close()
+		return nil, err
+	}
+	attr, err := newCatFileProc(repoDir, "check-attr", "--stdin", "-z", "diff")
+	if err != nil {
+		check.close()
+		batch.close()
+		return nil, err
+	}
+	return &catFileProcs{check: check, batch: batch, attr: attr}, nil
+}
+
+func (p *catFileProcs) close() {
+	p.check.close()
+	p.batch.close()
+	p.attr.close()
+}
+
+func newCatFileProc(repoDir string, args ...string) (*catFileProc, error) {
+	cmd := exec.Command("/usr/bin/git", args...)
+	cmd.Dir = repoDir
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	err = cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't start git %s. err=%s", args[0], err)
+	}
+	return &catFileProc{cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout)}, nil
+}
+
+// catFileProtocolError unexpected output from cat-file. The process must be discarded
+type catFileProtocolError struct {
+	msg string
+}
+
+func (e catFileProtocolError) Error() string {
+	return e.msg
+}
+
+// info returns type and size of an object. Type is "missing" if the object doesn't exist
+func (c *catFileProc) info(object string) (string, int, error) {
+	if strings.Contains(object, "\n") {
+		return "", 0, fmt.Errorf("Invalid object name. object=%s", object)
+	}
+	_, err := fmt.Fprintf(c.stdin, "%s\n", object)
+	if err != nil {
+		return "", 0, catFileProtocolError{fmt.Sprintf("Couldn't write to git cat-file. err=%s", err)}
+	}
+	line, err := c.stdout.ReadString('\n')
+	if err != nil {
+		return "", 0, catFileProtocolError{fmt.Sprintf("Couldn't read from git cat-file. err=%s", err)}
+	}
+
+	// "<sha> <type> <size>" or "<object> missing"
+	line = strings.TrimSuffix(line, "\n")
+	if strings.HasSuffix(line, " missing") || strings.HasSuffix(line, " ambiguous") {
+		return "missing", 0, nil
+	}
+	parts := strings.Split(line, " ")
+	if len(parts) != 3 {
+		return "", 0, catFileProtocolError{fmt.Sprintf("Unexpected git cat-file output. line=%s", line)}
+	}
+	size, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return "", 0, catFileProtocolError{fmt.Sprintf("Unexpected git cat-file output. line=%s", line)}
+	}
+	return parts[1], size, nil
+}
+
+// contents returns type and contents of an object. Only for "--batch" processes
+func (c *catFileProc) contents(object string) (string, []byte, error) {
+	objType, size, err := c.info(object)
+	if err != nil || objType == "missing" {
+		return objType, nil, err
+	}
+	// contents are followed by a new line
+	contents := make([]byte, size+1)
+	_, err = io.ReadFull(c.stdout, contents)
+	if err != nil {
+		return "", nil, catFileProtocolError{fmt.Sprintf("Couldn't read from git cat-file. err=%s", err)}
+	}
+	return objType, contents[:size], nil
+}
+
+// attribute returns the value of the attribute of a file ("set", "unset", "unspecified" or
+// the attribute value). Only for "check-attr" processes, which output "<path>NUL<attribute>NUL<info>NUL"
+func (c *catFileProc) attribute(filePath string) (string, error) {
+	if strings.Contains(filePath, "\x00") {
+		return "", fmt.Errorf("Invalid file path. filePath=%s", filePath)
+	}
+	_, err := fmt.Fprintf(c.stdin, "%s\x00", filePath)
+	if err != nil {
+		return "", catFileProtocolError{fmt.Sprintf("Couldn't write to git check-attr. err=%s", err)}
+	}
+	fields := make([]string, 3)
+	for i := range fields {
+		field, err := c.stdout.ReadString(0)
+		if err != nil {
+			return "", catFileProtocolError{fmt.Sprintf("Couldn't read from git check-attr. err=%s", err)}
+		}
+		fields[i] = strings.TrimSuffix(field, "\x00")
+	}
+	if fields[0] != filePath {
+		return "", catFileProtocolError{fmt.Sprintf("Unexpected git check-attr output. path=%s", fields[0])}
+	}
+	return fields[2], nil
+}
+
+func (c *catFileProc) close() {
+	c.stdin.Close()
+	c.cmd.Wait()
+}
+
+// parseCommitObject reads the author of a raw commit object. Commits read with
+// "cat-file --use-mailmap" have the author already mapped by .mailmap
+func parseCommitObject(commitId string, contents string) (CommitInfo, error) {
+	for _, line := range strings.Split(contents, "\n") {
+		// headers end at the first empty line
+		if line == "" {
+			break
+		}
+		// author Name <mail> 1692130352 -0300
+		if !strings.HasPrefix(line, "author ") {
+			continue
+		}
+		mailStart := strings.LastIndex(line, " <")
+		mailEnd := strings.LastIndex(line, "> ")
+		if mailStart == -1 || mailEnd < mailStart {
+			break
+		}
+		dateParts := strings.Split(line[mailEnd+2:], " ")
+		if len(dateParts) != 2 {
+			break
+		}
+		epoch, err := strconv.ParseInt(dateParts[0], 10, 64)
+		if err != nil {
+			return CommitInfo{}, err
+		}
+		tz, err := time.Parse("-0700", dateParts[1])
+		if err != nil {
+			return CommitInfo{}, err
+		}
+		message := ""
+		headersEnd := strings.Index(contents, "\n\n")
+		if headersEnd != -1 {
+			message = contents[headersEnd+2:]
+		}
+		return CommitInfo{
+			AuthorName: line[7:mailStart],
+			AuthorMail: line[mailStart+1 : mailEnd+1],
+			Date:       time.Unix(epoch, 0).In(tz.Location()),
+			CommitId:   commitId,
+			CoAuthors:  ParseCoAuthors(message),
+		}, nil
+	}
+	return CommitInfo{}, fmt.Errorf("Couldn't find author in commit. commitId=%s", commitId)
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatFilePool(t *testing.T) {
+	repoDir, err := ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	pool := NewCatFilePool(repoDir, 2)
+	defer pool.Close()
+
+	commits, err := ExecGetCommitsInDateRange(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	// used concurrently by multiple workers
+	var wg sync.WaitGroup
+	for _, commit := range commits {
+		wg.Add(1)
+		go func(commitId string) {
+			defer wg.Done()
+			execInfo, err := ExecGitCommitInfo(repoDir, commitId)
+			require.Nil(t, err)
+			info, err := pool.CommitInfo(commitId)
+			require.Nil(t, err)
+			require.Equal(t, execInfo.AuthorName, info.AuthorName)
+			require.Equal(t, execInfo.AuthorMail, info.AuthorMail)
+			require.Equal(t, execInfo.Date.Format(time.RFC3339), info.Date.Format(time.RFC3339))
+
+			files, err := ExecListTree(repoDir, commitId)
+			require.Nil(t, err)
+			for _, file := range files {
+				execSize, err := ExecTreeFileSize(repoDir, commitId, file)
+				require.Nil(t, err)
+				size, err := pool.TreeFileSize(commitId, file)
+				require.Nil(t, err)
+				require.Equal(t, execSize, size)
+
+				isBin, err := pool.IsBinary(commitId, file)
+				require.Nil(t, err)
+				require.False(t, isBin)
+			}
+		}(commit.CommitId)
+	}
+	wg.Wait()
+
+	_, err = pool.TreeFileSize(commits[0].CommitId, "nonexisting")
+	require.NotNil(t, err)
+	_, err = pool.IsBinary(commits[0].CommitId, "nonexisting")
+	require.NotNil(t, err)
+	_, err = pool.CommitInfo("0000000000000000000000000000000000000000")
+	require.NotNil(t, err)
+
+	// process is still usable after errors
+	_, err = pool.CommitInfo(commits[0].CommitId)
+	require.Nil(t, err)
+}
+
+func TestCatFilePoolAttributesMailmap(t *testing.T) {
+	repoDir, err := ResolveTestAttributesRepo()
+	require.Nil(t, err)
+
+	pool := NewCatFilePool(repoDir, 1)
+	defer pool.Close()
+
+	commit, err := ExecGetLastestCommit(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	// same results as git diff, which uses the binary and diff attributes
+	expected := map[string]bool{".gitattributes": false, ".mailmap": false, "file1": false, "file2.dat": true, "file3.bin": true, "forced.txt": false}
+	for file, expectedBin := range expected {
+		execBin, err := ExecDiffIsBinary(repoDir, commit.CommitId, file)
+		require.Nil(t, err)
+		require.Equal(t, expectedBin, execBin, file)
+		isBin, err := pool.IsBinary(commit.CommitId, file)
+		require.Nil(t, err)
+		require.Equal(t, expectedBin, isBin, file)
+	}
+	_, err = pool.IsBinary(commit.CommitId, "nonexisting.dat")
+	require.NotNil(t, err)
+
+	// author mapped by .mailmap, as in git log %aN/%aE
+	execInfo, err := ExecGitCommitInfo(repoDir, commit.CommitId)
+	require.Nil(t, err)
+	require.Equal(t, "Real Author", execInfo.AuthorName)
+	info, err := pool.CommitInfo(commit.CommitId)
+	require.Nil(t, err)
+	require.Equal(t, execInfo.AuthorName, info.AuthorName)
+	require.Equal(t, execInfo.AuthorMail, info.AuthorMail)
+}
+
+func TestParseCommitObject(t *testing.T) {
+	info, err := parseCommitObject("abc", `tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904
+parent 92a77046dd4c181cc6810854c4dbe3a56e8ac6e9
+author Flavio de Oliveira Stutz <flavio@mail.com> 1692130352 -0300
+committer Other <other@mail.com> 1692130999 +0000
+
+message author Someone <x@y.com> 1 +0000
+`)
+	require.Nil(t, err)
+	require.Equal(t, "Flavio de Oliveira Stutz", info.AuthorName)
+	require.Equal(t, "<flavio@mail.com>", info.AuthorMail)
+	require.Equal(t, "2023-08-15T17:12:32-03:00", info.Date.Format(time.RFC3339))
+	require.Equal(t, "abc", info.CommitId)
+
+	_, err = parseCommitObject("abc", "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n\nmessage")
+	require.NotNil(t, err)
+}
@@ -69,6 +69,11 @@ func ParseNormalDiffOutput(contents string) ([]DiffEntry, error) {
 		// < Nineth line
 
 		line := lines[li]
+		if line == "" {
+			// empty output when the files are equal (e.g. when ignoring whitespace)
+			li++
+			continue
+		}
 
 		// diff op
 		newOpMatches := newOpRe.FindStringSubmatch(line)
@@ -177,7 +182,62 @@ func extractLines(input string) ([]LineText, error) {
 	return lines, nil
 }
 
-// FIXME remove later if not used
+// DiffLines calculates the differences between the lines of two contents in process.
+// Results are in the same format as ExecDiffFiles, although the lines grouped in
+// each entry may be different from the ones reported by the diff tool
+func DiffLines(src string, dst string) []DiffEntry {
+	result := make([]DiffEntry, 0)
+	// next line number in each content
+	srcLine := 1
+	dstLine := 1
+	entry := DiffEntry{}
+
+	flush := func() {
+		if len(entry.SrcLines) == 0 && len(entry.DstLines) == 0 {
+			return
+		}
+		if len(entry.SrcLines) == 0 {
+			// added after line srcLine-1 in src
+			entry.Operation = OperationAdd
+			entry.SrcLines = []LineText{{Number: srcLine - 1}}
+		} else if len(entry.DstLines) == 0 {
+			// deleted lines would be after line dstLine-1 in dst
+			entry.Operation = OperationDelete
+			entry.DstLines = []LineText{{Number: dstLine - 1}}
+		} else {
+			entry.Operation = OperationChange
+		}
+		result = append(result, entry)
+		entry = DiffEntry{}
+	}
+
+	for _, diff := range DiffContents(src, dst) {
+		lines := strings.SplitAfter(diff.Text, "\n")
+		if lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
+		}
+		switch diff.Type {
+		case diffmatchpatch.DiffEqual:
+			flush()
+			srcLine += len(lines)
+			dstLine += len(lines)
+		case diffmatchpatch.DiffDelete:
+			for _, line := range lines {
+				entry.SrcLines = append(entry.SrcLines, LineText{Number: srcLine, Text: strings.TrimSuffix(line, "\n")})
+				srcLine++
+			}
+		case diffmatchpatch.DiffInsert:
+			for _, line := range lines {
+				entry.DstLines = append(entry.DstLines, LineText{Number: dstLine, Text: strings.TrimSuffix(line, "\n")})
+				dstLine++
+			}
+		}
+	}
+	flush()
+	return result
+}
+
+// DiffContents line based diff between two contents
 func DiffContents(src string, dst string) []diffmatchpatch.Diff {
 	dmp := diffmatchpatch.New()
 	wSrc, wDst, warray := dmp.DiffLinesToRunes(src, dst)
@@ -49,3 +49,29 @@ Seventh line
 	// }
 	require.Equal(t, 11, len(diffs))
 }
+
+func TestDiffLines(t *testing.T) {
+	diffEntries := DiffLines("First line\nSecond line\nThird line\nFourth line\nFifth line\n",
+		"First line CHANGED\nFourth line\nAdditional line\nFifth line\n")
+	require.Equal(t, 2, len(diffEntries))
+
+	de := diffEntries[0]
+	require.Equal(t, OperationChange, de.Operation)
+	require.Equal(t, 3, len(de.SrcLines))
+	require.Equal(t, 1, de.SrcLines[0].Number)
+	require.Equal(t, "Third line", de.SrcLines[2].Text)
+	require.Equal(t, 1, len(de.DstLines))
+	require.Equal(t, "First line CHANGED", de.DstLines[0].Text)
+
+	de = diffEntries[1]
+	require.Equal(t, OperationAdd, de.Operation)
+	require.Equal(t, 4, de.SrcLines[0].Number)
+	require.Equal(t, 3, de.DstLines[0].Number)
+	require.Equal(t, "Additional line", de.DstLines[0].Text)
+
+	diffEntries = DiffLines("First line\nSecond line\n", "First line\n")
+	require.Equal(t, 1, len(diffEntries))
+	require.Equal(t, OperationDelete, diffEntries[0].Operation)
+	require.Equal(t, 2, diffEntries[0].SrcLines[0].Number)
+	require.Equal(t, 1, diffEntries[0].DstLines[0].Number)
+}
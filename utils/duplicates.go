@@ -30,15 +30,15 @@ type LineSource struct {
 
 type LineGroup struct {
 	Lines
-	RelatedLinesGroup []LineGroup
-	RelatedLinesCount int
+	RelatedLinesGroup []LineGroup `json:"related_lines_group"`
+	RelatedLinesCount int         `json:"related_lines_count"`
 	lineHashes        []uint64
 }
 
 type Lines struct {
-	FilePath   string
-	LineNumber int
-	LineCount  int
+	FilePath   string `json:"file_path"`
+	LineNumber int    `json:"line_number"`
+	LineCount  int    `json:"line_count"`
 }
 
 var (
@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitattributes"
+)
+
+const (
+	// ExcludePresetBots commits from dependency update bots and CI automations
+	ExcludePresetBots = "bots"
+	// ExcludePresetLockfiles lock files of package managers
+	ExcludePresetLockfiles = "lockfiles"
+	// ExcludePresetVendor vendored dependencies and files marked with linguist-vendored
+	ExcludePresetVendor = "vendor"
+	// ExcludePresetGenerated generated code and files marked with linguist-generated
+	ExcludePresetGenerated = "generated"
+)
+
+type excludePreset struct {
+	authorsNotRegex string
+	filesNotRegex   string
+	// linguistAttribute attribute of .gitattributes that marks the files of the preset.
+	// It takes precedence over filesNotRegex, so "linguist-generated=false" keeps a file
+	linguistAttribute string
+}
+
+var excludePresets = map[string]excludePreset{
+	ExcludePresetBots: {
+		authorsNotRegex: `\[bot\]|^dependabot|^renovate|^greenkeeper|^snyk-bot|^github-actions`,
+	},
+	ExcludePresetLockfiles: {
+		filesNotRegex: `(^|/)(package-lock\.json|npm-shrinkwrap\.json|yarn\.lock|pnpm-lock\.yaml|go\.sum|Cargo\.lock|Gemfile\.lock|composer\.lock|poetry\.lock|Pipfile\.lock|packages\.lock\.json)$`,
+	},
+	ExcludePresetVendor: {
+		filesNotRegex:     `(^|/)(vendor|node_modules|third_party|bower_components)/`,
+		linguistAttribute: "linguist-vendored",
+	},
+	ExcludePresetGenerated: {
+		filesNotRegex:     `\.pb\.go$|\.pb\.(cc|h)$|_pb2(_grpc)?\.py$|\.pb\.gw\.go$|_gen\.go$|\.generated\.[^/]*$|\.min\.(js|css)$|(^|/)generated/`,
+		linguistAttribute: "linguist-generated",
+	},
+}
+
+// ExcludeFilter excludes authors and files matched by exclude presets
+type ExcludeFilter struct {
+	presets         []excludePreset
+	filesNotRes     []*regexp.Regexp
+	authorsNotRegex string
+	attributes      gitattributes.Matcher
+}
+
+// NewExcludeFilter creates a filter for a comma separated list of exclude presets
+// (see ExcludePreset*). The .gitattributes files found in revision are used to
+// find vendored and generated files. Returns nil if no presets are used
+func NewExcludeFilter(git GitBackend, presets string, revision string) (*ExcludeFilter, error) {
+	if strings.TrimSpace(presets) == "" {
+		return nil, nil
+	}
+	filter := &ExcludeFilter{}
+	authorsNot := make([]string, 0)
+	useAttributes := false
+	for _, name := range strings.Split(presets, ",") {
+		name = strings.TrimSpace(name)
+		preset, ok := excludePresets[name]
+		if !ok {
+			return nil, fmt.Errorf("Invalid exclude preset %s. Use one of %s", name, strings.Join(ExcludePresetNames(), ", "))
+		}
+		filter.presets = append(filter.presets, preset)
+		filter.filesNotRes = append(filter.filesNotRes, regexp.MustCompile(preset.filesNotRegex))
+		if preset.authorsNotRegex != "" {
+			authorsNot = append(authorsNot, preset.authorsNotRegex)
+		}
+		useAttributes = useAttributes || preset.linguistAttribute != ""
+	}
+	filter.authorsNotRegex = strings.Join(authorsNot, "|")
+
+	if useAttributes {
+		attributes, err := ReadGitAttributes(git, revision)
+		if err != nil {
+			return nil, err
+		}
+		filter.attributes = gitattributes.NewMatcher(attributes)
+	}
+	return filter, nil
+}
+
+// ExcludePresetNames names of all exclude presets
+func ExcludePresetNames() []string {
+	names := make([]string, 0, len(excludePresets))
+	for name := range excludePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FileExcluded checks if a file is excluded by any of the presets
+func (f *ExcludeFilter) FileExcluded(filePath string) bool {
+	if f == nil {
+		return false
+	}
+	var attrs map[string]gitattributes.Attribute
+	if f.attributes != nil {
+		attrs, _ = f.attributes.Match(strings.Split(filePath, "/"), nil)
+	}
+	for i, preset := range f.presets {
+		if preset.linguistAttribute != "" {
+			if marked, ok := linguistAttributeValue(attrs[preset.linguistAttribute]); ok {
+				if marked {
+					return true
+				}
+				continue
+			}
+		}
+		if preset.filesNotRegex != "" && f.filesNotRes[i].MatchString(filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthorsNotRegex authorsNotRegex with the authors excluded by the presets added
+func (f *ExcludeFilter) AuthorsNotRegex(authorsNotRegex string) string {
+	if f == nil || f.authorsNotRegex == "" {
+		return authorsNotRegex
+	}
+	if authorsNotRegex == "" {
+		return f.authorsNotRegex
+	}
+	return fmt.Sprintf("(%s)|%s", authorsNotRegex, f.authorsNotRegex)
+}
+
+// linguistAttributeValue value of a boolean linguist attribute. False if not specified
+func linguistAttributeValue(attr gitattributes.Attribute) (bool, bool) {
+	if attr == nil || attr.IsUnspecified() {
+		return false, false
+	}
+	if attr.IsUnset() {
+		return false, true
+	}
+	if attr.IsValueSet() {
+		value := strings.ToLower(attr.Value())
+		return value != "false" && value != "0", true
+	}
+	return true, true
+}
+
+// ReadGitAttributes reads the patterns of all .gitattributes files in a revision,
+// in ascending order of priority as expected by gitattributes.NewMatcher
+func ReadGitAttributes(git GitBackend, revision string) ([]gitattributes.MatchAttribute, error) {
+	files, err := git.ListTree(revision)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't list files to find .gitattributes. err=%s", err)
+	}
+	attributesFiles := make([]string, 0)
+	for _, file := range files {
+		if file == ".gitattributes" || strings.HasSuffix(file, "/.gitattributes") {
+			attributesFiles = append(attributesFiles, file)
+		}
+	}
+	// files in deeper dirs have higher priority
+	sort.SliceStable(attributesFiles, func(i, j int) bool {
+		return strings.Count(attributesFiles[i], "/") < strings.Count(attributesFiles[j], "/")
+	})
+
+	results := make([]gitattributes.MatchAttribute, 0)
+	for _, file := range attributesFiles {
+		contents, err := git.FileContents(revision, file)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't read .gitattributes. file=%s; err=%s", file, err)
+		}
+		var domain []string
+		if file != ".gitattributes" {
+			domain = strings.Split(file, "/")
+			domain = domain[:len(domain)-1]
+		}
+		attributes, err := gitattributes.ReadAttributes(strings.NewReader(contents), domain, domain == nil)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't parse .gitattributes. file=%s; err=%s", file, err)
+		}
+		results = append(results, attributes...)
+	}
+	return results, nil
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExcludeFilter(t *testing.T) {
+	repoDir, err := ResolveTestExcludePresetsRepo()
+	require.Nil(t, err)
+
+	git, err := NewGitBackend(GitBackendExec, repoDir, GitOptions{})
+	require.Nil(t, err)
+	defer git.Close()
+
+	filter, err := NewExcludeFilter(git, "", "main")
+	require.Nil(t, err)
+	require.Nil(t, filter)
+	require.False(t, filter.FileExcluded("package-lock.json"))
+	require.Equal(t, "author1", filter.AuthorsNotRegex("author1"))
+
+	filter, err = NewExcludeFilter(git, "bots, lockfiles,vendor,generated", "main")
+	require.Nil(t, err)
+	require.True(t, filter.FileExcluded("package-lock.json"))
+	require.True(t, filter.FileExcluded("web/yarn.lock"))
+	require.True(t, filter.FileExcluded("vendor/lib.go"))
+	require.True(t, filter.FileExcluded("api.pb.go"))
+	// linguist-generated in .gitattributes
+	require.True(t, filter.FileExcluded("gen/schema.go"))
+	// -linguist-generated in .gitattributes overrides the preset
+	require.False(t, filter.FileExcluded("keep.pb.go"))
+	require.False(t, filter.FileExcluded("file1"))
+
+	require.Regexp(t, filter.AuthorsNotRegex(""), "dependabot[bot]")
+	require.Regexp(t, filter.AuthorsNotRegex("author2"), "renovate-bot")
+	require.Regexp(t, filter.AuthorsNotRegex("author2"), "author2")
+	require.NotRegexp(t, filter.AuthorsNotRegex("author2"), "author1")
+
+	_, err = NewExcludeFilter(git, "bots,tests", "main")
+	require.NotNil(t, err)
+}
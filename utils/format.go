@@ -44,6 +44,13 @@ func BaseOptsStr(baseOpts BaseOptions) string {
 	str += AttrStr("files-not", baseOpts.FilesNotRegex)
 	str += AttrStr("authors", baseOpts.AuthorsRegex)
 	str += AttrStr("authors-not", baseOpts.AuthorsNotRegex)
+	str += AttrStr("identities", baseOpts.IdentitiesFile)
+	str += AttrStr("teams", baseOpts.TeamsFile)
+	if baseOpts.IgnoreWhitespace {
+		str += AttrStr("ignore-whitespace", "true")
+	}
+	str += AttrStr("ignore-revs", baseOpts.IgnoreRevsFile)
+	str += AttrStr("exclude-preset", baseOpts.ExcludePresets)
 	return str
 }
 
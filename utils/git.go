@@ -2,12 +2,19 @@ package utils
 
 import (
 	"fmt"
+	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
+var commitIdRe = regexp.MustCompile("^[0-9a-f]{7,40}$")
+
+// Co-authored-by: Name <mail>
+var coAuthorRe = regexp.MustCompile(`(?im)^co-authored-by:[ \t]*(.*?)[ \t]*<([^>]*)>[ \t]*$`)
+
 type BlameLine struct {
 	// AuthorName is the name of the last author that modified the line
 	AuthorName string
@@ -18,6 +25,18 @@ type BlameLine struct {
 	// Hash is the commit hash that introduced the original line
 	CommitId     string
 	LineContents string
+	// FilePath path of the file in which the line was originally written.
+	// Differs from the blamed file when the line was moved or copied from another file
+	// or when the whole file was renamed
+	FilePath string
+	// MovedCommitId commit that moved or copied the line from another file to the blamed file.
+	// Empty if the line wasn't moved or if it only followed a rename of the whole file.
+	// Only filled in when GitOptions.DetectMoves is used
+	MovedCommitId string `json:",omitempty"`
+	// MovedByName is the name of the author of MovedCommitId
+	MovedByName string `json:",omitempty"`
+	// MovedByMail is the mail of the author of MovedCommitId
+	MovedByMail string `json:",omitempty"`
 }
 
 type CommitInfo struct {
@@ -25,10 +44,55 @@ type CommitInfo struct {
 	AuthorMail string    `json:"author_mail"`
 	Date       time.Time `json:"date"`
 	CommitId   string    `json:"commit_id"`
+	// CoAuthors authors credited with "Co-authored-by" trailers in the commit message.
+	// Only filled in by GitBackend.CommitInfo
+	CoAuthors []CoAuthor `json:"co_authors,omitempty"`
 }
 
-func ExecGitBlame(repoPath string, filePath string, revision string) ([]BlameLine, error) {
-	cmdResult, err := ExecShellf(repoPath, "/usr/bin/git blame --line-porcelain %s \"%s\"", revision, filePath)
+type CoAuthor struct {
+	Name string `json:"name"`
+	Mail string `json:"mail"`
+}
+
+func ExecGitBlame(repoPath string, filePath string, revision string, opts GitOptions) ([]BlameLine, error) {
+	result, err := execGitBlame(repoPath, filePath, revision, opts)
+	if err != nil || !opts.DetectMoves {
+		return result, err
+	}
+
+	// blame without -M -C still follows renames of the whole file, so the lines that are attributed
+	// to another commit and file without it were moved or copied by the commit found without it
+	plainOpts := opts
+	plainOpts.DetectMoves = false
+	plainResult, err := execGitBlame(repoPath, filePath, revision, plainOpts)
+	if err != nil {
+		return nil, err
+	}
+	if len(plainResult) != len(result) {
+		return nil, fmt.Errorf("Blame with and without moves detection returned different lines. file=%s; revision=%s", filePath, revision)
+	}
+	for i, plainLine := range plainResult {
+		if plainLine.CommitId != result[i].CommitId && plainLine.FilePath != result[i].FilePath {
+			result[i].MovedCommitId = plainLine.CommitId
+			result[i].MovedByName = plainLine.AuthorName
+			result[i].MovedByMail = plainLine.AuthorMail
+		}
+	}
+	return result, nil
+}
+
+func execGitBlame(repoPath string, filePath string, revision string, opts GitOptions) ([]BlameLine, error) {
+	args := ""
+	if opts.IgnoreWhitespace {
+		args += " -w"
+	}
+	if opts.DetectMoves {
+		args += " -M -C"
+	}
+	for _, rev := range opts.IgnoreRevs {
+		args += fmt.Sprintf(" --ignore-rev %s", rev)
+	}
+	cmdResult, err := ExecShellf(repoPath, "/usr/bin/git blame --line-porcelain%s %s \"%s\"", args, revision, filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -72,6 +136,9 @@ func ExecGitBlame(repoPath string, filePath string, revision string) ([]BlameLin
 		if strings.HasPrefix(line, "author-mail ") {
 			blameLine.AuthorMail = line[12:]
 		}
+		if strings.HasPrefix(line, "filename ") {
+			blameLine.FilePath = line[9:]
+		}
 		if strings.HasPrefix(line, "author-time ") {
 			epoch, err := strconv.ParseInt(line[12:], 10, 64)
 			if err != nil {
@@ -103,7 +170,7 @@ func ExecListTree(repoDir string, commitId string) ([]string, error) {
 }
 
 func ExecPreviousCommitIdForFile(repoDir string, commitId string, filePath string) (string, error) {
-	cmdResult, err := ExecShellTimeout(repoDir, fmt.Sprintf("/usr/bin/git rev-list --boundary --parents -n 1 %s %s", commitId, filePath), 0, []int{0, 128})
+	cmdResult, err := ExecShellTimeout(repoDir, fmt.Sprintf("/usr/bin/git rev-list --boundary --parents -n 1 %s -- %s", commitId, filePath), 0, []int{0, 128})
 	if err != nil {
 		return "", err
 	}
@@ -189,6 +256,98 @@ func ExecDiffTree(repoDir string, commitId1 string) ([]string, error) {
 	return lines, nil
 }
 
+// ExecDiffTreeMerge returns the files of a merge commit that are different from all its parents,
+// which are the files changed while resolving the merge
+func ExecDiffTreeMerge(repoDir string, commitId string) ([]string, error) {
+	cmdResult, err := ExecShellf(repoDir, "/usr/bin/git diff-tree --no-commit-id -c --name-only -r %s", commitId)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(cmdResult) == "" {
+		return []string{}, nil
+	}
+	return linesToArray(cmdResult)
+}
+
+// FileRename a file that was renamed or copied by a commit
+type FileRename struct {
+	SrcPath string
+	DstPath string
+	// Copied the file in SrcPath was kept in the commit
+	Copied bool
+}
+
+// ExecFileRenames returns the files renamed or copied by a commit using git rename (-M) and copy (-C) detection
+func ExecFileRenames(repoDir string, commitId string) ([]FileRename, error) {
+	cmdResult, err := ExecShellf(repoDir, "/usr/bin/git diff-tree --no-commit-id --root -r -M -C --name-status %s", commitId)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := linesToArray(cmdResult)
+	if err != nil {
+		return nil, err
+	}
+	renames := make([]FileRename, 0)
+	for _, line := range lines {
+		// R100	file1	file2
+		parts := strings.Split(line, "\t")
+		if len(parts) != 3 {
+			continue
+		}
+		if strings.HasPrefix(parts[0], "R") || strings.HasPrefix(parts[0], "C") {
+			renames = append(renames, FileRename{
+				SrcPath: parts[1],
+				DstPath: parts[2],
+				Copied:  strings.HasPrefix(parts[0], "C"),
+			})
+		}
+	}
+	return renames, nil
+}
+
+// ExecDiffTreeCommits returns the files that are different between two commits
+func ExecDiffTreeCommits(repoDir string, commitId1 string, commitId2 string) ([]string, error) {
+	cmdResult, err := ExecShellf(repoDir, "/usr/bin/git diff-tree --no-commit-id --name-only -r %s %s", commitId1, commitId2)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := linesToArray(cmdResult)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0)
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// ExecFilesChangedInRange returns the files changed by any commit reachable from untilCommit that is not
+// reachable from sinceCommit, including files that were changed and then reverted in the range
+func ExecFilesChangedInRange(repoDir string, sinceCommit string, untilCommit string) ([]string, error) {
+	cmdResult, err := ExecShellf(repoDir, "/usr/bin/git log --format= --name-only --no-renames -m %s..%s", sinceCommit, untilCommit)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := linesToArray(cmdResult)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool, 0)
+	files := make([]string, 0)
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" || found[line] {
+			continue
+		}
+		found[line] = true
+		files = append(files, line)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
 func ExecCommitIdInDateRange(repoDir string, branch string, sinceDate string, untilDate string) ([]string, error) {
 	commits, err := ExecGetCommitsInDateRange(repoDir, branch, sinceDate, untilDate)
 	if err != nil {
@@ -197,8 +356,8 @@ func ExecCommitIdInDateRange(repoDir string, branch string, sinceDate string, un
 	return CommitInfoToCommitIds(commits), nil
 }
 
-func ExecDiffFileRevisions(repoDir string, filePath string, srcCommitId string, dstCommitId string) ([]DiffEntry, error) {
-	cmdResult, err := ExecShellf(repoDir, "/usr/bin/git difftool -x \"diff\" --no-prompt %s %s -- \"%s\"", srcCommitId, dstCommitId, filePath)
+func ExecDiffFileRevisions(repoDir string, filePath string, srcCommitId string, dstCommitId string, opts GitOptions) ([]DiffEntry, error) {
+	cmdResult, err := ExecShellf(repoDir, "/usr/bin/git difftool -x \"%s\" --no-prompt %s %s -- \"%s\"", diffCmd(opts), srcCommitId, dstCommitId, filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -206,6 +365,51 @@ func ExecDiffFileRevisions(repoDir string, filePath string, srcCommitId string,
 	return ParseNormalDiffOutput(cmdResult)
 }
 
+// ExecDiffMovedFileRevisions diffs a file that was in srcFilePath in srcCommitId and in dstFilePath in dstCommitId
+func ExecDiffMovedFileRevisions(repoDir string, srcFilePath string, srcCommitId string, dstFilePath string, dstCommitId string, opts GitOptions) ([]DiffEntry, error) {
+	cmdResult, err := ExecShellf(repoDir, "/usr/bin/git difftool -x \"%s\" --no-prompt \"%s:%s\" \"%s:%s\"", diffCmd(opts), srcCommitId, srcFilePath, dstCommitId, dstFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseNormalDiffOutput(cmdResult)
+}
+
+// diffCmd diff command used by difftool
+func diffCmd(opts GitOptions) string {
+	if opts.IgnoreWhitespace {
+		return "diff -w -B"
+	}
+	return "diff"
+}
+
+// ReadIgnoreRevsFile reads commit ids from a file in the format of .git-blame-ignore-revs.
+// Empty lines and comments (#) are ignored. Returns an empty list if file is empty
+func ReadIgnoreRevsFile(file string) ([]string, error) {
+	revs := make([]string, 0)
+	if file == "" {
+		return revs, nil
+	}
+	contents, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read ignore revs file. err=%s", err)
+	}
+	for _, line := range strings.Split(string(contents), "\n") {
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !commitIdRe.MatchString(line) {
+			return nil, fmt.Errorf("Invalid commit id in ignore revs file. line=%s", line)
+		}
+		revs = append(revs, line)
+	}
+	return revs, nil
+}
+
 func ExecGetCommitsInDateRange(repoDir string, branch string, since string, until string) ([]CommitInfo, error) {
 	sinceStr := ""
 	if since != "" {
@@ -223,6 +427,33 @@ func ExecGetCommitsInDateRange(repoDir string, branch string, since string, unti
 	return results, nil
 }
 
+// ExecFirstParentCommitIds commit ids in the first parent history of untilCommit
+// that are not reachable from sinceCommit. sinceCommit is optional
+func ExecFirstParentCommitIds(repoDir string, sinceCommit string, untilCommit string) ([]string, error) {
+	return execRevListIds(repoDir, "--first-parent", sinceCommit, untilCommit)
+}
+
+// ExecMergeCommitIds ids of the merge commits reachable from untilCommit
+// that are not reachable from sinceCommit. sinceCommit is optional
+func ExecMergeCommitIds(repoDir string, sinceCommit string, untilCommit string) ([]string, error) {
+	return execRevListIds(repoDir, "--merges", sinceCommit, untilCommit)
+}
+
+func execRevListIds(repoDir string, args string, sinceCommit string, untilCommit string) ([]string, error) {
+	revs := untilCommit
+	if sinceCommit != "" {
+		revs = fmt.Sprintf("%s ^%s", untilCommit, sinceCommit)
+	}
+	cmdResult, err := ExecShellf(repoDir, "/usr/bin/git rev-list %s %s", args, revs)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(cmdResult) == "" {
+		return []string{}, nil
+	}
+	return linesToArray(cmdResult)
+}
+
 func ExecGetCommitsInCommitRange(repoDir string, branch string, sinceCommit string, untilCommit string) ([]CommitInfo, error) {
 	// edge case where rev-list doesn't work well (it doesn't return the single commit when it is the HEAD)
 	if sinceCommit != "" && sinceCommit == untilCommit {
@@ -280,6 +511,45 @@ func ExecGetCommitsInCommitRange(repoDir string, branch string, sinceCommit stri
 	return results, nil
 }
 
+// ExecGetCommitAuthorsInDateRange returns the commits reachable from revision in the date range along with their authors
+func ExecGetCommitAuthorsInDateRange(repoDir string, revision string, since string, until string) ([]CommitInfo, error) {
+	sinceStr := ""
+	if since != "" {
+		sinceStr = fmt.Sprintf("--since=\"%s\"", since)
+	}
+	untilStr := ""
+	if until != "" {
+		untilStr = fmt.Sprintf("--until=\"%s\"", until)
+	}
+	cmdResult, err := ExecShellf(repoDir, "/usr/bin/git log %s %s --format=\"%%H---%%aI---%%aN---<%%aE>\" %s", sinceStr, untilStr, revision)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := linesToArray(cmdResult)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]CommitInfo, 0)
+	for _, line := range lines {
+		parts := strings.Split(line, "---")
+		if len(parts) != 4 {
+			continue
+		}
+		date, err := time.Parse(time.RFC3339, parts[1])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, CommitInfo{
+			CommitId:   parts[0],
+			Date:       date,
+			AuthorName: parts[2],
+			AuthorMail: parts[3],
+		})
+	}
+	return results, nil
+}
+
 func ExecGetLastestCommit(repoDir string, branch string, sinceDate string, untilDate string) (*CommitInfo, error) {
 	commits, err := ExecGetCommitsInDateRange(repoDir, branch, sinceDate, untilDate)
 	if err != nil {
@@ -339,3 +609,20 @@ func revListToCommitInfo(cmdResult string) ([]CommitInfo, error) {
 	}
 	return results, nil
 }
+
+// ParseCoAuthors returns the authors in "Co-authored-by" trailers of a commit message.
+// Mails are returned in the "<mail>" form used elsewhere and duplicates are ignored
+func ParseCoAuthors(message string) []CoAuthor {
+	var results []CoAuthor
+	seen := make(map[string]bool)
+	for _, match := range coAuthorRe.FindAllStringSubmatch(message, -1) {
+		mail := fmt.Sprintf("<%s>", strings.TrimSpace(match[2]))
+		key := strings.ToLower(mail)
+		if match[1] == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		results = append(results, CoAuthor{Name: match[1], Mail: mail})
+	}
+	return results
+}
@@ -0,0 +1,239 @@
+package utils
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/segmentio/fasthash/fnv1a"
+)
+
+const (
+	// GitBackendExec runs the git cli for each operation
+	GitBackendExec = "exec"
+	// GitBackendGoGit reads the repository in process using go-git, so git is not required
+	GitBackendGoGit = "go-git"
+)
+
+// GitBackend git operations used during the analysis of the files of a repo
+type GitBackend interface {
+	// Name name of the backend
+	Name() string
+	// CacheVersion identifies the backend and the options that change its results.
+	// Used to avoid mixing cached results from different backends
+	CacheVersion() string
+	// Blame author information of each line of a file in a revision
+	Blame(filePath string, revision string) ([]BlameLine, error)
+	// ListTree all file paths in a commit
+	ListTree(commitId string) ([]string, error)
+	// DiffTree file paths changed by a commit
+	DiffTree(commitId string) ([]string, error)
+	// FileRenames files renamed or copied by a commit
+	FileRenames(commitId string) ([]FileRename, error)
+	// DiffTreeMerge file paths of a merge commit that are different from all its parents (changed while resolving the merge)
+	DiffTreeMerge(commitId string) ([]string, error)
+	// DiffTreeCommits file paths that are different between two commits
+	DiffTreeCommits(commitId1 string, commitId2 string) ([]string, error)
+	// FilesChangedInRange file paths changed by any commit reachable from untilCommit that is not
+	// reachable from sinceCommit. Merge commits are compared to each of their parents
+	FilesChangedInRange(sinceCommit string, untilCommit string) ([]string, error)
+	// PreviousCommitIdForFile the commit in which the file was changed before commitId.
+	// Empty if the file was created in commitId
+	PreviousCommitIdForFile(commitId string, filePath string) (string, error)
+	// TreeFileSize size of a file in a commit. Returns an error if the file doesn't exist
+	TreeFileSize(commitId string, filePath string) (int, error)
+	// IsBinary true if the contents of the file in a commit are binary
+	IsBinary(commitId string, filePath string) (bool, error)
+	// FileContents contents of a file in a commit. Returns an error if the file doesn't exist
+	FileContents(commitId string, filePath string) (string, error)
+	// CommitInfo author and date of a commit
+	CommitInfo(commitId string) (CommitInfo, error)
+	// DiffFileRevisions line differences of a file between two commits
+	DiffFileRevisions(filePath string, srcCommitId string, dstCommitId string) ([]DiffEntry, error)
+	// DiffMovedFileRevisions line differences of a file that was moved from srcFilePath to dstFilePath
+	DiffMovedFileRevisions(srcFilePath string, srcCommitId string, dstFilePath string, dstCommitId string) ([]DiffEntry, error)
+	// CommitsInDateRange commits reachable from branch with commit date in the range, newest first,
+	// followed by the commits right before since (boundary). Dates are in the formats accepted by git (eg: '1 month ago')
+	CommitsInDateRange(branch string, since string, until string) ([]CommitInfo, error)
+	// LatestCommit newest commit reachable from branch in the date range. nil if there are no commits in the range
+	LatestCommit(branch string, since string, until string) (*CommitInfo, error)
+	// CommitsInCommitRange commits reachable from untilCommit that are not reachable from sinceCommit,
+	// newest first, followed by sinceCommit. All commits of branch if both commits are empty
+	CommitsInCommitRange(branch string, sinceCommit string, untilCommit string) ([]CommitInfo, error)
+	// CommitAuthorsInDateRange commits reachable from revision with commit date in the range, newest first,
+	// with their authors and author dates. since and until are optional
+	CommitAuthorsInDateRange(revision string, since string, until string) ([]CommitInfo, error)
+	// FirstParentCommitIds ids of the commits in the first parent history of untilCommit
+	// that are not reachable from sinceCommit. sinceCommit is optional
+	FirstParentCommitIds(sinceCommit string, untilCommit string) ([]string, error)
+	// MergeCommitIds ids of the merge commits reachable from untilCommit that are
+	// not reachable from sinceCommit. sinceCommit is optional
+	MergeCommitIds(sinceCommit string, untilCommit string) ([]string, error)
+	// Close releases resources (processes, files etc) used by the backend
+	Close()
+}
+
+// GitOptions options that change how lines are compared by blame and diffs
+type GitOptions struct {
+	// IgnoreWhitespace changes only in whitespace or blank lines keep the previous author of
+	// the lines and aren't counted as changes
+	IgnoreWhitespace bool
+	// IgnoreRevs commits that are ignored. Lines changed by them keep their previous author
+	IgnoreRevs []string
+	// DetectMoves lines moved or copied within or between files keep their original author in blame
+	DetectMoves bool
+}
+
+// NewGitOptions creates git options from the analysis options. The ignore revs file is read if defined
+func NewGitOptions(baseOpts BaseOptions) (GitOptions, error) {
+	ignoreRevs, err := ReadIgnoreRevsFile(baseOpts.IgnoreRevsFile)
+	if err != nil {
+		return GitOptions{}, err
+	}
+	return GitOptions{
+		IgnoreWhitespace: baseOpts.IgnoreWhitespace,
+		IgnoreRevs:       ignoreRevs,
+	}, nil
+}
+
+// IsIgnoredRev true if the commit is in IgnoreRevs. Abbreviated commit ids are accepted in IgnoreRevs
+func (o GitOptions) IsIgnoredRev(commitId string) bool {
+	for _, rev := range o.IgnoreRevs {
+		if strings.HasPrefix(commitId, rev) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o GitOptions) cacheVersion() string {
+	version := ""
+	if o.IgnoreWhitespace {
+		version += ":w"
+	}
+	if o.DetectMoves {
+		version += ":m"
+	}
+	if len(o.IgnoreRevs) > 0 {
+		version += fmt.Sprintf(":revs-%x", fnv1a.HashString64(strings.Join(o.IgnoreRevs, ",")))
+	}
+	return version
+}
+
+// NewGitBackend creates the git backend for a repo. If backend is empty, GitBackendExec is used
+func NewGitBackend(backend string, repoDir string, opts GitOptions) (GitBackend, error) {
+	switch backend {
+	case "", GitBackendExec:
+		return &execGitBackend{
+			repoDir: repoDir,
+			opts:    opts,
+			catFile: NewCatFilePool(repoDir, runtime.NumCPU()),
+		}, nil
+	case GitBackendGoGit:
+		if opts.IgnoreWhitespace || len(opts.IgnoreRevs) > 0 || opts.DetectMoves {
+			return nil, fmt.Errorf("Ignoring whitespace or revisions and detecting moves are not supported by the %s git backend. Use '%s'", GitBackendGoGit, GitBackendExec)
+		}
+		return newGoGitBackend(repoDir)
+	}
+	return nil, fmt.Errorf("Invalid git backend %s. Use '%s' or '%s'", backend, GitBackendExec, GitBackendGoGit)
+}
+
+// execGitBackend runs the git cli using the Exec* functions. Objects are read
+// from long lived "git cat-file" processes, as they are read for each file
+type execGitBackend struct {
+	repoDir string
+	opts    GitOptions
+	catFile *CatFilePool
+}
+
+func (b *execGitBackend) Name() string {
+	return GitBackendExec
+}
+
+func (b *execGitBackend) CacheVersion() string {
+	return GitBackendExec + b.opts.cacheVersion()
+}
+
+func (b *execGitBackend) Blame(filePath string, revision string) ([]BlameLine, error) {
+	return ExecGitBlame(b.repoDir, filePath, revision, b.opts)
+}
+
+func (b *execGitBackend) ListTree(commitId string) ([]string, error) {
+	return ExecListTree(b.repoDir, commitId)
+}
+
+func (b *execGitBackend) DiffTree(commitId string) ([]string, error) {
+	return ExecDiffTree(b.repoDir, commitId)
+}
+
+func (b *execGitBackend) FileRenames(commitId string) ([]FileRename, error) {
+	return ExecFileRenames(b.repoDir, commitId)
+}
+
+func (b *execGitBackend) DiffTreeMerge(commitId string) ([]string, error) {
+	return ExecDiffTreeMerge(b.repoDir, commitId)
+}
+
+func (b *execGitBackend) DiffTreeCommits(commitId1 string, commitId2 string) ([]string, error) {
+	return ExecDiffTreeCommits(b.repoDir, commitId1, commitId2)
+}
+
+func (b *execGitBackend) FilesChangedInRange(sinceCommit string, untilCommit string) ([]string, error) {
+	return ExecFilesChangedInRange(b.repoDir, sinceCommit, untilCommit)
+}
+
+func (b *execGitBackend) PreviousCommitIdForFile(commitId string, filePath string) (string, error) {
+	return ExecPreviousCommitIdForFile(b.repoDir, commitId, filePath)
+}
+
+func (b *execGitBackend) TreeFileSize(commitId string, filePath string) (int, error) {
+	return b.catFile.TreeFileSize(commitId, filePath)
+}
+
+func (b *execGitBackend) IsBinary(commitId string, filePath string) (bool, error) {
+	return b.catFile.IsBinary(commitId, filePath)
+}
+
+func (b *execGitBackend) FileContents(commitId string, filePath string) (string, error) {
+	return b.catFile.FileContents(commitId, filePath)
+}
+
+func (b *execGitBackend) CommitInfo(commitId string) (CommitInfo, error) {
+	return b.catFile.CommitInfo(commitId)
+}
+
+func (b *execGitBackend) DiffFileRevisions(filePath string, srcCommitId string, dstCommitId string) ([]DiffEntry, error) {
+	return ExecDiffFileRevisions(b.repoDir, filePath, srcCommitId, dstCommitId, b.opts)
+}
+
+func (b *execGitBackend) DiffMovedFileRevisions(srcFilePath string, srcCommitId string, dstFilePath string, dstCommitId string) ([]DiffEntry, error) {
+	return ExecDiffMovedFileRevisions(b.repoDir, srcFilePath, srcCommitId, dstFilePath, dstCommitId, b.opts)
+}
+
+func (b *execGitBackend) CommitsInDateRange(branch string, since string, until string) ([]CommitInfo, error) {
+	return ExecGetCommitsInDateRange(b.repoDir, branch, since, until)
+}
+
+func (b *execGitBackend) LatestCommit(branch string, since string, until string) (*CommitInfo, error) {
+	return ExecGetLastestCommit(b.repoDir, branch, since, until)
+}
+
+func (b *execGitBackend) CommitsInCommitRange(branch string, sinceCommit string, untilCommit string) ([]CommitInfo, error) {
+	return ExecGetCommitsInCommitRange(b.repoDir, branch, sinceCommit, untilCommit)
+}
+
+func (b *execGitBackend) CommitAuthorsInDateRange(revision string, since string, until string) ([]CommitInfo, error) {
+	return ExecGetCommitAuthorsInDateRange(b.repoDir, revision, since, until)
+}
+
+func (b *execGitBackend) FirstParentCommitIds(sinceCommit string, untilCommit string) ([]string, error) {
+	return ExecFirstParentCommitIds(b.repoDir, sinceCommit, untilCommit)
+}
+
+func (b *execGitBackend) MergeCommitIds(sinceCommit string, untilCommit string) ([]string, error) {
+	return ExecMergeCommitIds(b.repoDir, sinceCommit, untilCommit)
+}
+
+func (b *execGitBackend) Close() {
+	b.catFile.Close()
+}
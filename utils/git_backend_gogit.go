@@ -0,0 +1,875 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// goGitBackend reads the repository in process with go-git
+type goGitBackend struct {
+	repoDir string
+	// go-git repositories are not safe for concurrent use, so
+	// each operation takes a repository from this pool
+	repos chan *git.Repository
+}
+
+func newGoGitBackend(repoDir string) (*goGitBackend, error) {
+	b := &goGitBackend{repoDir: repoDir, repos: make(chan *git.Repository, 32)}
+	repo, err := b.openRepo()
+	if err != nil {
+		return nil, err
+	}
+	b.repos <- repo
+	return b, nil
+}
+
+func (b *goGitBackend) openRepo() (*git.Repository, error) {
+	repo, err := git.PlainOpenWithOptions(b.repoDir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't open git repo. repoDir=%s; err=%s", b.repoDir, err)
+	}
+	return repo, nil
+}
+
+func (b *goGitBackend) withRepo(fn func(repo *git.Repository) error) error {
+	var repo *git.Repository
+	select {
+	case repo = <-b.repos:
+	default:
+		var err error
+		repo, err = b.openRepo()
+		if err != nil {
+			return err
+		}
+	}
+	defer func() {
+		select {
+		case b.repos <- repo:
+		default:
+		}
+	}()
+	return fn(repo)
+}
+
+func (b *goGitBackend) Name() string {
+	return GitBackendGoGit
+}
+
+func (b *goGitBackend) CacheVersion() string {
+	return GitBackendGoGit
+}
+
+func (b *goGitBackend) Blame(filePath string, revision string) ([]BlameLine, error) {
+	result := make([]BlameLine, 0)
+	err := b.withRepo(func(repo *git.Repository) error {
+		commit, err := goGitCommit(repo, revision)
+		if err != nil {
+			return err
+		}
+		blame, err := git.Blame(commit, filePath)
+		if err != nil {
+			return err
+		}
+		for _, line := range blame.Lines {
+			result = append(result, BlameLine{
+				AuthorName:   line.AuthorName,
+				AuthorMail:   fmt.Sprintf("<%s>", line.Author),
+				AuthorDate:   line.Date,
+				CommitId:     line.Hash.String(),
+				LineContents: line.Text,
+				FilePath:     filePath,
+			})
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (b *goGitBackend) ListTree(commitId string) ([]string, error) {
+	files := make([]string, 0)
+	err := b.withRepo(func(repo *git.Repository) error {
+		tree, err := goGitTree(repo, commitId)
+		if err != nil {
+			return err
+		}
+		return tree.Files().ForEach(func(f *object.File) error {
+			files = append(files, f.Name)
+			return nil
+		})
+	})
+	return files, err
+}
+
+func (b *goGitBackend) DiffTree(commitId string) ([]string, error) {
+	files := make([]string, 0)
+	err := b.withRepo(func(repo *git.Repository) error {
+		commit, err := goGitCommit(repo, commitId)
+		if err != nil {
+			return err
+		}
+		tree, err := commit.Tree()
+		if err != nil {
+			return err
+		}
+		switch commit.NumParents() {
+		case 0:
+			// as in "diff-tree --root", all files of the first commit were changed
+			return tree.Files().ForEach(func(f *object.File) error {
+				files = append(files, f.Name)
+				return nil
+			})
+		case 1:
+			parent, err := commit.Parent(0)
+			if err != nil {
+				return err
+			}
+			parentTree, err := parent.Tree()
+			if err != nil {
+				return err
+			}
+			files, err = goGitDiffTree(parentTree, tree)
+			return err
+		}
+		// as in "diff-tree" without "-m", merge commits show no changes
+		return nil
+	})
+	return files, err
+}
+
+func (b *goGitBackend) FileRenames(commitId string) ([]FileRename, error) {
+	renames := make([]FileRename, 0)
+	err := b.withRepo(func(repo *git.Repository) error {
+		commit, err := goGitCommit(repo, commitId)
+		if err != nil {
+			return err
+		}
+		// as in DiffTree, first commits only add files and merge commits show no changes
+		if commit.NumParents() != 1 {
+			return nil
+		}
+		tree, err := commit.Tree()
+		if err != nil {
+			return err
+		}
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return err
+		}
+		parentTree, err := parent.Tree()
+		if err != nil {
+			return err
+		}
+		// same similarity threshold as git "-M"
+		changes, err := object.DiffTreeWithOptions(context.Background(), parentTree, tree, &object.DiffTreeOptions{
+			DetectRenames: true,
+			RenameScore:   50,
+		})
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			if change.From.Name != "" && change.To.Name != "" && change.From.Name != change.To.Name {
+				renames = append(renames, FileRename{SrcPath: change.From.Name, DstPath: change.To.Name})
+			}
+		}
+		copies, err := goGitCopies(parentTree, tree, changes)
+		if err != nil {
+			return err
+		}
+		renames = append(renames, copies...)
+		sort.SliceStable(renames, func(i, j int) bool {
+			return renames[i].DstPath < renames[j].DstPath
+		})
+		return nil
+	})
+	return renames, err
+}
+
+// goGitCopies added files that are copies of files modified in the same commit, as in git "-C".
+// The similarity is the share of the bytes of the larger file that are in lines found in both files
+func goGitCopies(parentTree *object.Tree, tree *object.Tree, changes object.Changes) ([]FileRename, error) {
+	sources := make([]string, 0)
+	for _, change := range changes {
+		if change.From.Name != "" && change.From.Name == change.To.Name {
+			sources = append(sources, change.From.Name)
+		}
+	}
+	copies := make([]FileRename, 0)
+	if len(sources) == 0 {
+		return copies, nil
+	}
+	for _, change := range changes {
+		if change.From.Name != "" {
+			continue
+		}
+		dstFile, err := tree.File(change.To.Name)
+		if err != nil {
+			return nil, err
+		}
+		dstContents, err := dstFile.Contents()
+		if err != nil {
+			return nil, err
+		}
+		bestSrc := ""
+		bestScore := 0
+		for _, src := range sources {
+			srcFile, err := parentTree.File(src)
+			if err != nil {
+				return nil, err
+			}
+			srcContents, err := srcFile.Contents()
+			if err != nil {
+				return nil, err
+			}
+			score := similarityScore(srcContents, dstContents)
+			if score > bestScore {
+				bestSrc = src
+				bestScore = score
+			}
+		}
+		// same similarity threshold as git "-C"
+		if bestScore >= 50 {
+			copies = append(copies, FileRename{SrcPath: bestSrc, DstPath: change.To.Name, Copied: true})
+		}
+	}
+	return copies, nil
+}
+
+// similarityScore percentage of the bytes of the larger contents that are in lines found in both contents
+func similarityScore(contents1 string, contents2 string) int {
+	size := len(contents1)
+	if len(contents2) > size {
+		size = len(contents2)
+	}
+	if size == 0 {
+		return 100
+	}
+	lines := make(map[string]int, 0)
+	for _, line := range strings.SplitAfter(contents1, "\n") {
+		lines[line]++
+	}
+	common := 0
+	for _, line := range strings.SplitAfter(contents2, "\n") {
+		if lines[line] > 0 {
+			lines[line]--
+			common += len(line)
+		}
+	}
+	return 100 * common / size
+}
+
+func (b *goGitBackend) DiffTreeMerge(commitId string) ([]string, error) {
+	files := make([]string, 0)
+	err := b.withRepo(func(repo *git.Repository) error {
+		commit, err := goGitCommit(repo, commitId)
+		if err != nil {
+			return err
+		}
+		// as in "diff-tree" without "--root", first commits show no changes
+		if commit.NumParents() == 0 {
+			return nil
+		}
+		tree, err := commit.Tree()
+		if err != nil {
+			return err
+		}
+		// as in "diff-tree -c", only files that are different from all parents
+		changedCount := make(map[string]int, 0)
+		err = commit.Parents().ForEach(func(parent *object.Commit) error {
+			parentTree, err := parent.Tree()
+			if err != nil {
+				return err
+			}
+			parentFiles, err := goGitDiffTree(parentTree, tree)
+			if err != nil {
+				return err
+			}
+			for _, file := range parentFiles {
+				changedCount[file]++
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for file, count := range changedCount {
+			if count == commit.NumParents() {
+				files = append(files, file)
+			}
+		}
+		sort.Strings(files)
+		return nil
+	})
+	return files, err
+}
+
+func (b *goGitBackend) DiffTreeCommits(commitId1 string, commitId2 string) ([]string, error) {
+	var files []string
+	err := b.withRepo(func(repo *git.Repository) error {
+		tree1, err := goGitTree(repo, commitId1)
+		if err != nil {
+			return err
+		}
+		tree2, err := goGitTree(repo, commitId2)
+		if err != nil {
+			return err
+		}
+		files, err = goGitDiffTree(tree1, tree2)
+		return err
+	})
+	return files, err
+}
+
+func (b *goGitBackend) FilesChangedInRange(sinceCommit string, untilCommit string) ([]string, error) {
+	files := make([]string, 0)
+	err := b.withRepo(func(repo *git.Repository) error {
+		commits, err := goGitRevList(repo, untilCommit, sinceCommit)
+		if err != nil {
+			return err
+		}
+		found := make(map[string]bool, 0)
+		for _, commit := range commits {
+			tree, err := commit.Tree()
+			if err != nil {
+				return err
+			}
+			commitFiles := make([]string, 0)
+			if commit.NumParents() == 0 {
+				err = tree.Files().ForEach(func(f *object.File) error {
+					commitFiles = append(commitFiles, f.Name)
+					return nil
+				})
+			} else {
+				err = commit.Parents().ForEach(func(parent *object.Commit) error {
+					parentTree, err := parent.Tree()
+					if err != nil {
+						return err
+					}
+					parentFiles, err := goGitDiffTree(parentTree, tree)
+					commitFiles = append(commitFiles, parentFiles...)
+					return err
+				})
+			}
+			if err != nil {
+				return err
+			}
+			for _, file := range commitFiles {
+				if !found[file] {
+					found[file] = true
+					files = append(files, file)
+				}
+			}
+		}
+		sort.Strings(files)
+		return nil
+	})
+	return files, err
+}
+
+func (b *goGitBackend) PreviousCommitIdForFile(commitId string, filePath string) (string, error) {
+	prevCommitId := ""
+	err := b.withRepo(func(repo *git.Repository) error {
+		hash, err := repo.ResolveRevision(plumbing.Revision(commitId))
+		if err != nil {
+			return err
+		}
+		commits, err := repo.Log(&git.LogOptions{
+			From:       *hash,
+			PathFilter: func(path string) bool { return path == filePath },
+		})
+		if err != nil {
+			return err
+		}
+		defer commits.Close()
+
+		commit, err := commits.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// current commit doesn't touch file
+		if commit.Hash != *hash {
+			prevCommitId = commit.Hash.String()
+			return nil
+		}
+		// current commit also touches file
+		commit, err = commits.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		prevCommitId = commit.Hash.String()
+		return nil
+	})
+	return prevCommitId, err
+}
+
+func (b *goGitBackend) TreeFileSize(commitId string, filePath string) (int, error) {
+	size := -1
+	err := b.withRepo(func(repo *git.Repository) error {
+		file, err := goGitFile(repo, commitId, filePath)
+		if err != nil {
+			return err
+		}
+		size = int(file.Size)
+		return nil
+	})
+	return size, err
+}
+
+func (b *goGitBackend) IsBinary(commitId string, filePath string) (bool, error) {
+	isBinary := false
+	err := b.withRepo(func(repo *git.Repository) error {
+		file, err := goGitFile(repo, commitId, filePath)
+		if err != nil {
+			return err
+		}
+		isBinary, err = file.IsBinary()
+		return err
+	})
+	return isBinary, err
+}
+
+func (b *goGitBackend) FileContents(commitId string, filePath string) (string, error) {
+	contents := ""
+	err := b.withRepo(func(repo *git.Repository) error {
+		file, err := goGitFile(repo, commitId, filePath)
+		if err != nil {
+			return err
+		}
+		contents, err = file.Contents()
+		return err
+	})
+	return contents, err
+}
+
+func (b *goGitBackend) CommitInfo(commitId string) (CommitInfo, error) {
+	result := CommitInfo{}
+	err := b.withRepo(func(repo *git.Repository) error {
+		commit, err := goGitCommit(repo, commitId)
+		if err != nil {
+			return err
+		}
+		result = CommitInfo{
+			Date:       commit.Author.When,
+			AuthorName: commit.Author.Name,
+			AuthorMail: fmt.Sprintf("<%s>", commit.Author.Email),
+			CommitId:   commitId,
+			CoAuthors:  ParseCoAuthors(commit.Message),
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (b *goGitBackend) DiffFileRevisions(filePath string, srcCommitId string, dstCommitId string) ([]DiffEntry, error) {
+	return b.DiffMovedFileRevisions(filePath, srcCommitId, filePath, dstCommitId)
+}
+
+func (b *goGitBackend) DiffMovedFileRevisions(srcFilePath string, srcCommitId string, dstFilePath string, dstCommitId string) ([]DiffEntry, error) {
+	var result []DiffEntry
+	err := b.withRepo(func(repo *git.Repository) error {
+		srcFile, err := goGitFile(repo, srcCommitId, srcFilePath)
+		if err != nil {
+			return err
+		}
+		srcContents, err := srcFile.Contents()
+		if err != nil {
+			return err
+		}
+		dstFile, err := goGitFile(repo, dstCommitId, dstFilePath)
+		if err != nil {
+			return err
+		}
+		dstContents, err := dstFile.Contents()
+		if err != nil {
+			return err
+		}
+		result = DiffLines(srcContents, dstContents)
+		return nil
+	})
+	return result, err
+}
+
+func (b *goGitBackend) CommitsInDateRange(branch string, since string, until string) ([]CommitInfo, error) {
+	now := time.Now()
+	sinceDate, err := parseGitDate(since, now)
+	if err != nil {
+		return nil, err
+	}
+	// as in git, an empty until means now
+	untilDate, err := parseGitDate(until, now)
+	if err != nil {
+		return nil, err
+	}
+	if until == "" {
+		untilDate = now
+	}
+
+	results := make([]CommitInfo, 0)
+	err = b.withRepo(func(repo *git.Repository) error {
+		commits, err := goGitRevList(repo, branch, "")
+		if err != nil {
+			return err
+		}
+		included := make(map[plumbing.Hash]bool, 0)
+		for _, commit := range commits {
+			date := commit.Committer.When
+			if (sinceDate.IsZero() || !date.Before(sinceDate)) && !date.After(untilDate) {
+				included[commit.Hash] = true
+				results = append(results, goGitRevListCommitInfo(commit))
+			}
+		}
+		boundary, err := goGitBoundary(commits, included)
+		if err != nil {
+			return err
+		}
+		results = append(results, boundary...)
+		return nil
+	})
+	return results, err
+}
+
+func (b *goGitBackend) LatestCommit(branch string, since string, until string) (*CommitInfo, error) {
+	commits, err := b.CommitsInDateRange(branch, since, until)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, nil
+	}
+	return &commits[0], nil
+}
+
+func (b *goGitBackend) CommitsInCommitRange(branch string, sinceCommit string, untilCommit string) ([]CommitInfo, error) {
+	if sinceCommit == "" && untilCommit == "" && branch == "" {
+		return nil, fmt.Errorf("branch is required when sinceCommit and untilCommit are empty")
+	}
+	if untilCommit == "" {
+		untilCommit = branch
+	}
+
+	results := make([]CommitInfo, 0)
+	err := b.withRepo(func(repo *git.Repository) error {
+		if sinceCommit != "" && sinceCommit == untilCommit {
+			commit, err := goGitCommit(repo, untilCommit)
+			if err != nil {
+				return err
+			}
+			results = append(results, goGitRevListCommitInfo(commit))
+			return nil
+		}
+		commits, err := goGitRevList(repo, untilCommit, sinceCommit)
+		if err != nil {
+			return err
+		}
+		included := make(map[plumbing.Hash]bool, len(commits))
+		for _, commit := range commits {
+			included[commit.Hash] = true
+			results = append(results, goGitRevListCommitInfo(commit))
+		}
+		boundary, err := goGitBoundary(commits, included)
+		if err != nil {
+			return err
+		}
+		results = append(results, boundary...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return results, nil
+	}
+	if results[len(results)-1].Date.After(results[0].Date) {
+		return nil, fmt.Errorf("Since commit date is after until commit date. since=%s until=%s",
+			results[len(results)-1].Date.Format(time.RFC3339), results[0].Date.Format(time.RFC3339))
+	}
+	return results, nil
+}
+
+func (b *goGitBackend) CommitAuthorsInDateRange(revision string, since string, until string) ([]CommitInfo, error) {
+	now := time.Now()
+	sinceDate, err := parseGitDate(since, now)
+	if err != nil {
+		return nil, err
+	}
+	untilDate, err := parseGitDate(until, now)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]CommitInfo, 0)
+	err = b.withRepo(func(repo *git.Repository) error {
+		commits, err := goGitRevList(repo, revision, "")
+		if err != nil {
+			return err
+		}
+		for _, commit := range commits {
+			date := commit.Committer.When
+			if (sinceDate.IsZero() || !date.Before(sinceDate)) && (untilDate.IsZero() || !date.After(untilDate)) {
+				results = append(results, CommitInfo{
+					CommitId:   commit.Hash.String(),
+					Date:       commit.Author.When,
+					AuthorName: commit.Author.Name,
+					AuthorMail: fmt.Sprintf("<%s>", commit.Author.Email),
+				})
+			}
+		}
+		return nil
+	})
+	return results, err
+}
+
+func (b *goGitBackend) FirstParentCommitIds(sinceCommit string, untilCommit string) ([]string, error) {
+	commitIds := make([]string, 0)
+	err := b.withRepo(func(repo *git.Repository) error {
+		excluded, err := goGitReachable(repo, sinceCommit)
+		if err != nil {
+			return err
+		}
+		commit, err := goGitCommit(repo, untilCommit)
+		if err != nil {
+			return err
+		}
+		for !excluded[commit.Hash] {
+			commitIds = append(commitIds, commit.Hash.String())
+			if commit.NumParents() == 0 {
+				break
+			}
+			commit, err = commit.Parent(0)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return commitIds, err
+}
+
+func (b *goGitBackend) MergeCommitIds(sinceCommit string, untilCommit string) ([]string, error) {
+	commitIds := make([]string, 0)
+	err := b.withRepo(func(repo *git.Repository) error {
+		commits, err := goGitRevList(repo, untilCommit, sinceCommit)
+		if err != nil {
+			return err
+		}
+		for _, commit := range commits {
+			if commit.NumParents() > 1 {
+				commitIds = append(commitIds, commit.Hash.String())
+			}
+		}
+		return nil
+	})
+	return commitIds, err
+}
+
+func (b *goGitBackend) Close() {
+	// repositories only hold memory caches, so there is nothing to release
+}
+
+func goGitCommit(repo *git.Repository, revision string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("Commit not found. commitId=%s; err=%s", revision, err)
+	}
+	return repo.CommitObject(*hash)
+}
+
+func goGitTree(repo *git.Repository, commitId string) (*object.Tree, error) {
+	commit, err := goGitCommit(repo, commitId)
+	if err != nil {
+		return nil, err
+	}
+	return commit.Tree()
+}
+
+func goGitFile(repo *git.Repository, commitId string, filePath string) (*object.File, error) {
+	tree, err := goGitTree(repo, commitId)
+	if err != nil {
+		return nil, err
+	}
+	file, err := tree.File(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("File doesn't exist. commitId=%s; filePath=%s", commitId, filePath)
+	}
+	return file, nil
+}
+
+func goGitDiffTree(tree1 *object.Tree, tree2 *object.Tree) ([]string, error) {
+	changes, err := object.DiffTree(tree1, tree2)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0)
+	for _, change := range changes {
+		name := change.To.Name
+		if name == "" {
+			name = change.From.Name
+		}
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// goGitRevList commits reachable from revision that are not reachable from excludeRevision,
+// newest first, as in "git rev-list [revision] ^[excludeRevision]". excludeRevision is optional
+func goGitRevList(repo *git.Repository, revision string, excludeRevision string) ([]*object.Commit, error) {
+	excluded, err := goGitReachable(repo, excludeRevision)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := goGitCommit(repo, revision)
+	if err != nil {
+		return nil, err
+	}
+	// as in git, the newest commit is walked first and commits with the same date are walked in the order they were found
+	commits := make([]*object.Commit, 0)
+	queue := []*object.Commit{commit}
+	seen := map[plumbing.Hash]bool{commit.Hash: true}
+	for len(queue) > 0 {
+		commit = queue[0]
+		queue = queue[1:]
+		if excluded[commit.Hash] {
+			continue
+		}
+		commits = append(commits, commit)
+		err = commit.Parents().ForEach(func(parent *object.Commit) error {
+			if seen[parent.Hash] {
+				return nil
+			}
+			seen[parent.Hash] = true
+			i := sort.Search(len(queue), func(i int) bool {
+				return queue[i].Committer.When.Before(parent.Committer.When)
+			})
+			queue = append(queue[:i], append([]*object.Commit{parent}, queue[i:]...)...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return commits, nil
+}
+
+// goGitReachable ids of all commits reachable from revision. Empty if revision is empty
+func goGitReachable(repo *git.Repository, revision string) (map[plumbing.Hash]bool, error) {
+	reachable := make(map[plumbing.Hash]bool, 0)
+	if revision == "" {
+		return reachable, nil
+	}
+	commit, err := goGitCommit(repo, revision)
+	if err != nil {
+		return nil, err
+	}
+	err = object.NewCommitPreorderIter(commit, nil, nil).ForEach(func(c *object.Commit) error {
+		reachable[c.Hash] = true
+		return nil
+	})
+	return reachable, err
+}
+
+// goGitBoundary parents of the included commits that were not included, as in "git rev-list --boundary"
+func goGitBoundary(commits []*object.Commit, included map[plumbing.Hash]bool) ([]CommitInfo, error) {
+	results := make([]CommitInfo, 0)
+	seen := make(map[plumbing.Hash]bool, 0)
+	for _, commit := range commits {
+		if !included[commit.Hash] {
+			continue
+		}
+		err := commit.Parents().ForEach(func(parent *object.Commit) error {
+			if !included[parent.Hash] && !seen[parent.Hash] {
+				seen[parent.Hash] = true
+				results = append(results, goGitRevListCommitInfo(parent))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// goGitRevListCommitInfo commit info with the committer and commit date, as returned by ExecGetCommitsInDateRange
+func goGitRevListCommitInfo(commit *object.Commit) CommitInfo {
+	return CommitInfo{
+		CommitId:   commit.Hash.String(),
+		Date:       commit.Committer.When,
+		AuthorName: commit.Committer.Name,
+		AuthorMail: commit.Committer.Email,
+	}
+}
+
+// parseGitDate parses the absolute and relative dates used in the options the same way as git.
+// Relative dates are a sequence of amounts of time subtracted from now or from a day
+// (eg: '1 month ago', 'now - 2 weeks - 1 day', '2023-02-01 - 1 month').
+// Returns a zero time for an empty date
+func parseGitDate(date string, now time.Time) (time.Time, error) {
+	date = strings.TrimSpace(date)
+	if date == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		parsed, err := time.ParseInLocation(layout, date, now.Location())
+		if err == nil {
+			return parsed, nil
+		}
+	}
+	result := now
+	tokens := strings.Fields(date)
+	// as in git, the time of the day is kept when only the day is defined
+	parsed, err := time.ParseInLocation("2006-01-02", tokens[0], now.Location())
+	if err == nil {
+		result = time.Date(parsed.Year(), parsed.Month(), parsed.Day(), now.Hour(), now.Minute(), now.Second(), 0, now.Location())
+		tokens = tokens[1:]
+	}
+	for i := 0; i < len(tokens); i++ {
+		token := strings.ToLower(tokens[i])
+		switch token {
+		case "now", "ago", "-":
+			continue
+		case "yesterday":
+			result = result.AddDate(0, 0, -1)
+			continue
+		}
+		amount, err := strconv.Atoi(token)
+		if err != nil || i+1 >= len(tokens) {
+			return time.Time{}, fmt.Errorf("Unsupported date. Use a date as '2006-01-02' or relative to now as '2 weeks ago'. date=%s", date)
+		}
+		i++
+		switch strings.TrimSuffix(strings.ToLower(tokens[i]), "s") {
+		case "second":
+			result = result.Add(-time.Duration(amount) * time.Second)
+		case "minute":
+			result = result.Add(-time.Duration(amount) * time.Minute)
+		case "hour":
+			result = result.Add(-time.Duration(amount) * time.Hour)
+		case "day":
+			result = result.AddDate(0, 0, -amount)
+		case "week":
+			result = result.AddDate(0, 0, -7*amount)
+		case "month":
+			result = result.AddDate(0, -amount, 0)
+		case "year":
+			result = result.AddDate(-amount, 0, 0)
+		default:
+			return time.Time{}, fmt.Errorf("Unsupported date unit. date=%s; unit=%s", date, tokens[i])
+		}
+	}
+	return result, nil
+}
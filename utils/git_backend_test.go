@@ -0,0 +1,334 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGitBackendInvalid(t *testing.T) {
+	_, err := NewGitBackend("svn", ".", GitOptions{})
+	require.NotNil(t, err)
+
+	// go-git doesn't support these options
+	_, err = NewGitBackend(GitBackendGoGit, ".", GitOptions{IgnoreWhitespace: true})
+	require.NotNil(t, err)
+}
+
+func TestGitOptions(t *testing.T) {
+	opts := GitOptions{IgnoreRevs: []string{"abcdef1"}}
+	require.True(t, opts.IsIgnoredRev("abcdef1234567890"))
+	require.False(t, opts.IsIgnoredRev("1234567890abcdef"))
+
+	execGit, err := NewGitBackend(GitBackendExec, ".", GitOptions{})
+	require.Nil(t, err)
+	defer execGit.Close()
+	wsGit, err := NewGitBackend(GitBackendExec, ".", GitOptions{IgnoreWhitespace: true})
+	require.Nil(t, err)
+	defer wsGit.Close()
+	revsGit, err := NewGitBackend(GitBackendExec, ".", opts)
+	require.Nil(t, err)
+	defer revsGit.Close()
+	require.NotEqual(t, execGit.CacheVersion(), wsGit.CacheVersion())
+	require.NotEqual(t, execGit.CacheVersion(), revsGit.CacheVersion())
+	require.Equal(t, execGit.Name(), wsGit.Name())
+}
+
+// go-git backend must return the same results as the git cli
+func TestGoGitBackend(t *testing.T) {
+	repoDir, err := ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+
+	execGit, err := NewGitBackend(GitBackendExec, repoDir, GitOptions{})
+	require.Nil(t, err)
+	goGit, err := NewGitBackend(GitBackendGoGit, repoDir, GitOptions{})
+	require.Nil(t, err)
+	require.Equal(t, GitBackendGoGit, goGit.Name())
+
+	commits, err := ExecGetCommitsInDateRange(repoDir, "main", "", "now")
+	require.Nil(t, err)
+	require.Equal(t, 5, len(commits))
+
+	for _, commit := range commits {
+		execFiles, err := execGit.ListTree(commit.CommitId)
+		require.Nil(t, err)
+		goFiles, err := goGit.ListTree(commit.CommitId)
+		require.Nil(t, err)
+		require.Equal(t, execFiles, goFiles)
+
+		changedFiles, err := execGit.DiffTree(commit.CommitId)
+		require.Nil(t, err)
+		goFiles, err = goGit.DiffTree(commit.CommitId)
+		require.Nil(t, err)
+		require.Equal(t, changedFiles, goFiles)
+
+		execFiles, err = execGit.DiffTreeCommits(commits[len(commits)-1].CommitId, commit.CommitId)
+		require.Nil(t, err)
+		goFiles, err = goGit.DiffTreeCommits(commits[len(commits)-1].CommitId, commit.CommitId)
+		require.Nil(t, err)
+		require.Equal(t, execFiles, goFiles)
+
+		execInfo, err := execGit.CommitInfo(commit.CommitId)
+		require.Nil(t, err)
+		goInfo, err := goGit.CommitInfo(commit.CommitId)
+		require.Nil(t, err)
+		require.Equal(t, execInfo.AuthorName, goInfo.AuthorName)
+		require.Equal(t, execInfo.AuthorMail, goInfo.AuthorMail)
+		require.True(t, execInfo.Date.Equal(goInfo.Date))
+
+		for _, file := range changedFiles {
+			execSize, execErr := execGit.TreeFileSize(commit.CommitId, file)
+			goSize, goErr := goGit.TreeFileSize(commit.CommitId, file)
+			require.Equal(t, execErr == nil, goErr == nil)
+			if execErr != nil {
+				// deleted file
+				continue
+			}
+			require.Equal(t, execSize, goSize)
+
+			goBin, err := goGit.IsBinary(commit.CommitId, file)
+			require.Nil(t, err)
+			require.False(t, goBin)
+
+			execPrev, err := execGit.PreviousCommitIdForFile(commit.CommitId, file)
+			require.Nil(t, err)
+			goPrev, err := goGit.PreviousCommitIdForFile(commit.CommitId, file)
+			require.Nil(t, err)
+			require.Equal(t, execPrev, goPrev)
+
+			execBlame, err := execGit.Blame(file, commit.CommitId)
+			require.Nil(t, err)
+			goBlame, err := goGit.Blame(file, commit.CommitId)
+			require.Nil(t, err)
+			require.Equal(t, len(execBlame), len(goBlame))
+			for i := range execBlame {
+				require.Equal(t, execBlame[i].AuthorName, goBlame[i].AuthorName)
+				require.Equal(t, execBlame[i].AuthorMail, goBlame[i].AuthorMail)
+				require.Equal(t, execBlame[i].CommitId, goBlame[i].CommitId)
+				require.Equal(t, execBlame[i].LineContents, goBlame[i].LineContents)
+				require.True(t, execBlame[i].AuthorDate.Equal(goBlame[i].AuthorDate))
+			}
+
+			if execPrev != "" {
+				execDiffs, err := execGit.DiffFileRevisions(file, execPrev, commit.CommitId)
+				require.Nil(t, err)
+				goDiffs, err := goGit.DiffFileRevisions(file, execPrev, commit.CommitId)
+				require.Nil(t, err)
+				require.Equal(t, len(execDiffs), len(goDiffs))
+			}
+		}
+	}
+}
+
+func TestGoGitBackendRenames(t *testing.T) {
+	repoDir, err := ResolveTestRenamedFilesRepo()
+	require.Nil(t, err)
+
+	execGit, err := NewGitBackend(GitBackendExec, repoDir, GitOptions{})
+	require.Nil(t, err)
+	goGit, err := NewGitBackend(GitBackendGoGit, repoDir, GitOptions{})
+	require.Nil(t, err)
+
+	commits, err := ExecGetCommitsInDateRange(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	for _, commit := range commits {
+		execRenames, err := execGit.FileRenames(commit.CommitId)
+		require.Nil(t, err)
+		goRenames, err := goGit.FileRenames(commit.CommitId)
+		require.Nil(t, err)
+		require.Equal(t, execRenames, goRenames)
+	}
+
+	execDiffs, err := execGit.DiffMovedFileRevisions("file1", commits[1].CommitId, "dir1/file3", commits[0].CommitId)
+	require.Nil(t, err)
+	goDiffs, err := goGit.DiffMovedFileRevisions("file1", commits[1].CommitId, "dir1/file3", commits[0].CommitId)
+	require.Nil(t, err)
+	require.Equal(t, execDiffs, goDiffs)
+}
+
+func TestGoGitBackendCopies(t *testing.T) {
+	repoDir, err := ResolveTestCopiedFilesRepo()
+	require.Nil(t, err)
+
+	execGit, err := NewGitBackend(GitBackendExec, repoDir, GitOptions{})
+	require.Nil(t, err)
+	defer execGit.Close()
+	goGit, err := NewGitBackend(GitBackendGoGit, repoDir, GitOptions{})
+	require.Nil(t, err)
+
+	execRenames, err := execGit.FileRenames("main")
+	require.Nil(t, err)
+	require.Equal(t, []FileRename{{SrcPath: "file1", DstPath: "dir1/file2", Copied: true}}, execRenames)
+	goRenames, err := goGit.FileRenames("main")
+	require.Nil(t, err)
+	require.Equal(t, execRenames, goRenames)
+}
+
+func TestGoGitBackendCommitRanges(t *testing.T) {
+	repoDir, err := ResolveTestMergesRepo()
+	require.Nil(t, err)
+
+	execGit, err := NewGitBackend(GitBackendExec, repoDir, GitOptions{})
+	require.Nil(t, err)
+	defer execGit.Close()
+	goGit, err := NewGitBackend(GitBackendGoGit, repoDir, GitOptions{})
+	require.Nil(t, err)
+
+	for _, dates := range [][]string{{"", "now"}, {"", ""}, {"1 month ago", "now"}, {"now - 1 day", ""}, {"", "1 year ago"}} {
+		execCommits, err := execGit.CommitsInDateRange("main", dates[0], dates[1])
+		require.Nil(t, err)
+		goCommits, err := goGit.CommitsInDateRange("main", dates[0], dates[1])
+		require.Nil(t, err)
+		require.Equal(t, CommitInfoToCommitIds(execCommits), CommitInfoToCommitIds(goCommits))
+
+		execLatest, err := execGit.LatestCommit("main", dates[0], dates[1])
+		require.Nil(t, err)
+		goLatest, err := goGit.LatestCommit("main", dates[0], dates[1])
+		require.Nil(t, err)
+		require.Equal(t, execLatest == nil, goLatest == nil)
+		if execLatest != nil {
+			require.Equal(t, execLatest.CommitId, goLatest.CommitId)
+			require.True(t, execLatest.Date.Equal(goLatest.Date))
+		}
+	}
+
+	commits, err := execGit.CommitsInDateRange("main", "", "now")
+	require.Nil(t, err)
+	first := commits[len(commits)-1].CommitId
+	last := commits[0].CommitId
+	for _, commitRange := range [][]string{{"", ""}, {first, last}, {last, last}} {
+		execCommits, err := execGit.CommitsInCommitRange("main", commitRange[0], commitRange[1])
+		require.Nil(t, err)
+		goCommits, err := goGit.CommitsInCommitRange("main", commitRange[0], commitRange[1])
+		require.Nil(t, err)
+		require.ElementsMatch(t, CommitInfoToCommitIds(execCommits), CommitInfoToCommitIds(goCommits))
+		require.Equal(t, execCommits[len(execCommits)-1].CommitId, goCommits[len(goCommits)-1].CommitId)
+	}
+
+	for _, sinceCommit := range []string{"", first} {
+		execIds, err := execGit.FirstParentCommitIds(sinceCommit, last)
+		require.Nil(t, err)
+		goIds, err := goGit.FirstParentCommitIds(sinceCommit, last)
+		require.Nil(t, err)
+		require.Equal(t, execIds, goIds)
+
+		execIds, err = execGit.MergeCommitIds(sinceCommit, last)
+		require.Nil(t, err)
+		require.Equal(t, 1, len(execIds))
+		goIds, err = goGit.MergeCommitIds(sinceCommit, last)
+		require.Nil(t, err)
+		require.Equal(t, execIds, goIds)
+	}
+
+	for _, commitRange := range [][]string{{first, last}, {last, first}, {last + "^2", last}} {
+		execFiles, err := execGit.FilesChangedInRange(commitRange[0], commitRange[1])
+		require.Nil(t, err)
+		goFiles, err := goGit.FilesChangedInRange(commitRange[0], commitRange[1])
+		require.Nil(t, err)
+		require.Equal(t, execFiles, goFiles)
+	}
+
+	execContents, err := execGit.FileContents(last, "file1")
+	require.Nil(t, err)
+	goContents, err := goGit.FileContents(last, "file1")
+	require.Nil(t, err)
+	require.Equal(t, execContents, goContents)
+	_, err = goGit.FileContents(last, "missing")
+	require.NotNil(t, err)
+}
+
+func TestParseGitDate(t *testing.T) {
+	now := time.Date(2023, 3, 31, 10, 20, 30, 0, time.UTC)
+
+	date, err := parseGitDate("", now)
+	require.Nil(t, err)
+	require.True(t, date.IsZero())
+
+	date, err = parseGitDate("now", now)
+	require.Nil(t, err)
+	require.Equal(t, now, date)
+
+	date, err = parseGitDate("2 weeks ago", now)
+	require.Nil(t, err)
+	require.Equal(t, now.AddDate(0, 0, -14), date)
+
+	date, err = parseGitDate("now - 1 year - 3 days - 1 hour", now)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2022, 3, 28, 9, 20, 30, 0, time.UTC), date)
+
+	date, err = parseGitDate("2023-01-15 - 1 month", now)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2022, 12, 15, 10, 20, 30, 0, time.UTC), date)
+
+	date, err = parseGitDate("2023-01-15T08:00:00+02:00", now)
+	require.Nil(t, err)
+	require.True(t, time.Date(2023, 1, 15, 6, 0, 0, 0, time.UTC).Equal(date))
+
+	_, err = parseGitDate("last tuesday", now)
+	require.NotNil(t, err)
+	_, err = parseGitDate("3 fortnights ago", now)
+	require.NotNil(t, err)
+}
+
+func TestGoGitBackendMerges(t *testing.T) {
+	repoDir, err := ResolveTestMergesRepo()
+	require.Nil(t, err)
+
+	execGit, err := NewGitBackend(GitBackendExec, repoDir, GitOptions{})
+	require.Nil(t, err)
+	goGit, err := NewGitBackend(GitBackendGoGit, repoDir, GitOptions{})
+	require.Nil(t, err)
+
+	commits, err := ExecGetCommitsInDateRange(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	for _, commit := range commits {
+		execFiles, err := execGit.DiffTreeMerge(commit.CommitId)
+		require.Nil(t, err)
+		goFiles, err := goGit.DiffTreeMerge(commit.CommitId)
+		require.Nil(t, err)
+		require.Equal(t, execFiles, goFiles)
+
+		execFiles, err = execGit.DiffTree(commit.CommitId)
+		require.Nil(t, err)
+		goFiles, err = goGit.DiffTree(commit.CommitId)
+		require.Nil(t, err)
+		require.Equal(t, execFiles, goFiles)
+	}
+
+	// merges are compared to their first parent
+	execFiles, err := execGit.DiffTreeCommits(commits[0].CommitId+"^1", commits[0].CommitId)
+	require.Nil(t, err)
+	goFiles, err := goGit.DiffTreeCommits(commits[0].CommitId+"^1", commits[0].CommitId)
+	require.Nil(t, err)
+	require.Equal(t, []string{"file1", "file2"}, execFiles)
+	require.Equal(t, execFiles, goFiles)
+}
+
+func TestGoGitBackendCoAuthors(t *testing.T) {
+	repoDir, err := ResolveTestCoAuthorsRepo()
+	require.Nil(t, err)
+
+	execGit, err := NewGitBackend(GitBackendExec, repoDir, GitOptions{})
+	require.Nil(t, err)
+	defer execGit.Close()
+	goGit, err := NewGitBackend(GitBackendGoGit, repoDir, GitOptions{})
+	require.Nil(t, err)
+
+	commits, err := ExecGetCommitsInDateRange(repoDir, "main", "", "now")
+	require.Nil(t, err)
+	require.Equal(t, 2, len(commits))
+
+	execInfo, err := execGit.CommitInfo(commits[0].CommitId)
+	require.Nil(t, err)
+	require.Equal(t, []CoAuthor{{Name: "author1", Mail: "<author1@mail.com>"}, {Name: "author2", Mail: "<author2@mail.com>"}}, execInfo.CoAuthors)
+	goInfo, err := goGit.CommitInfo(commits[0].CommitId)
+	require.Nil(t, err)
+	require.Equal(t, execInfo.CoAuthors, goInfo.CoAuthors)
+
+	execInfo, err = execGit.CommitInfo(commits[1].CommitId)
+	require.Nil(t, err)
+	require.Equal(t, []CoAuthor{{Name: "author2", Mail: "<author2@mail.com>"}}, execInfo.CoAuthors)
+}
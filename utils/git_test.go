@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"os"
 	"strings"
 	"testing"
 
@@ -120,7 +121,7 @@ func TestExecDiffFileRevisions(t *testing.T) {
 		return
 	}
 
-	de, err := ExecDiffFileRevisions(repoDir, "file1", commitIds[0].CommitId, commitIds[len(commitIds)-1].CommitId)
+	de, err := ExecDiffFileRevisions(repoDir, "file1", commitIds[0].CommitId, commitIds[len(commitIds)-1].CommitId, GitOptions{})
 	require.Nil(t, err)
 	require.Equal(t, OperationChange, de[0].Operation)
 	require.Equal(t, 1, de[0].DstLines[0].Number)
@@ -275,10 +276,307 @@ func TestExecGitBlame(t *testing.T) {
 	require.Nil(t, err)
 	require.NotEmpty(t, cid)
 
-	lines, err := ExecGitBlame(repoDir, "file1", cid.CommitId)
+	lines, err := ExecGitBlame(repoDir, "file1", cid.CommitId, GitOptions{})
 	require.Nil(t, err)
 	require.NotEmpty(t, lines)
 	require.Equal(t, 2, len(lines))
 	require.Equal(t, "author2", lines[0].AuthorName)
 	require.Equal(t, "author1", lines[1].AuthorName)
 }
+
+func TestExecGetCommitAuthorsInDateRange(t *testing.T) {
+	repoDir, err := ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+	if err != nil {
+		return
+	}
+
+	commits, err := ExecGetCommitAuthorsInDateRange(repoDir, "main", "1 week ago", "")
+	require.Nil(t, err)
+	require.Equal(t, 5, len(commits))
+	require.Equal(t, "author3", commits[0].AuthorName)
+	require.Equal(t, "<author3@mail.com>", commits[0].AuthorMail)
+	require.False(t, commits[0].Date.IsZero())
+
+	commits, err = ExecGetCommitAuthorsInDateRange(repoDir, "main", "", "1 week ago")
+	require.Nil(t, err)
+	require.Equal(t, 0, len(commits))
+}
+
+func TestExecDiffTreeCommits(t *testing.T) {
+	repoDir, err := ResolveTestOwnershipRepo()
+	require.Nil(t, err)
+	if err != nil {
+		return
+	}
+
+	commits, err := ExecGetCommitsInDateRange(repoDir, "main", "", "now")
+	require.Nil(t, err)
+	slices.Reverse(commits)
+
+	// only file1 was changed between the first and the fourth commit
+	files, err := ExecDiffTreeCommits(repoDir, commits[0].CommitId, commits[3].CommitId)
+	require.Nil(t, err)
+	require.Equal(t, []string{"file1"}, files)
+
+	// dir1/dir1.1/file2 was created by the last commit
+	files, err = ExecDiffTreeCommits(repoDir, commits[3].CommitId, commits[4].CommitId)
+	require.Nil(t, err)
+	require.Equal(t, []string{"dir1/dir1.1/file2"}, files)
+
+	// order of commits doesn't matter
+	files, err = ExecDiffTreeCommits(repoDir, commits[4].CommitId, commits[0].CommitId)
+	require.Nil(t, err)
+	require.ElementsMatch(t, []string{"file1", "dir1/dir1.1/file2"}, files)
+
+	files, err = ExecDiffTreeCommits(repoDir, commits[4].CommitId, commits[4].CommitId)
+	require.Nil(t, err)
+	require.Empty(t, files)
+}
+
+func TestExecFilesChangedInRange(t *testing.T) {
+	repoDir, err := ResolveTestRevertedChangesRepo()
+	require.Nil(t, err)
+
+	commits, err := ExecGetCommitsInDateRange(repoDir, "main", "", "now")
+	require.Nil(t, err)
+	require.Equal(t, 3, len(commits))
+
+	// file1 is the same in both commits, but it was changed in between
+	files, err := ExecDiffTreeCommits(repoDir, commits[2].CommitId, commits[0].CommitId)
+	require.Nil(t, err)
+	require.Empty(t, files)
+	files, err = ExecFilesChangedInRange(repoDir, commits[2].CommitId, commits[0].CommitId)
+	require.Nil(t, err)
+	require.Equal(t, []string{"file1"}, files)
+
+	files, err = ExecFilesChangedInRange(repoDir, commits[0].CommitId, commits[2].CommitId)
+	require.Nil(t, err)
+	require.Empty(t, files)
+}
+
+func TestExecFileRenames(t *testing.T) {
+	repoDir, err := ResolveTestRenamedFilesRepo()
+	require.Nil(t, err)
+
+	commits, err := ExecGetCommitsInDateRange(repoDir, "main", "", "now")
+	require.Nil(t, err)
+	require.Equal(t, 2, len(commits))
+
+	renames, err := ExecFileRenames(repoDir, commits[0].CommitId)
+	require.Nil(t, err)
+	require.Equal(t, []FileRename{{SrcPath: "file1", DstPath: "dir1/file3"}}, renames)
+
+	// first commit only adds files
+	renames, err = ExecFileRenames(repoDir, commits[1].CommitId)
+	require.Nil(t, err)
+	require.Empty(t, renames)
+
+	de, err := ExecDiffMovedFileRevisions(repoDir, "file1", commits[1].CommitId, "dir1/file3", commits[0].CommitId, GitOptions{})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(de))
+	require.Equal(t, OperationChange, de[0].Operation)
+	require.Equal(t, 3, de[0].DstLines[0].Number)
+	require.Equal(t, "XXXX", de[0].DstLines[0].Text)
+}
+
+func TestExecGitBlameIgnoreWhitespace(t *testing.T) {
+	repoDir, err := ResolveTestWhitespaceRepo()
+	require.Nil(t, err)
+
+	commits, err := ExecGetCommitsInDateRange(repoDir, "main", "", "now")
+	require.Nil(t, err)
+	require.Equal(t, 3, len(commits))
+
+	lines, err := ExecGitBlame(repoDir, "file1", commits[0].CommitId, GitOptions{})
+	require.Nil(t, err)
+	require.Equal(t, 4, len(lines))
+	require.Equal(t, "author3", lines[0].AuthorName)
+	require.Equal(t, "author2", lines[1].AuthorName)
+	require.Equal(t, "author2", lines[3].AuthorName)
+
+	lines, err = ExecGitBlame(repoDir, "file1", commits[0].CommitId, GitOptions{IgnoreWhitespace: true})
+	require.Nil(t, err)
+	require.Equal(t, 4, len(lines))
+	require.Equal(t, "author3", lines[0].AuthorName)
+	require.Equal(t, "author1", lines[1].AuthorName)
+	require.Equal(t, "author1", lines[3].AuthorName)
+}
+
+func TestExecGitBlameIgnoreRevs(t *testing.T) {
+	repoDir, err := ResolveTestWhitespaceRepo()
+	require.Nil(t, err)
+
+	commits, err := ExecGetCommitsInDateRange(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	lines, err := ExecGitBlame(repoDir, "file1", commits[0].CommitId, GitOptions{IgnoreRevs: []string{commits[1].CommitId}})
+	require.Nil(t, err)
+	require.Equal(t, 4, len(lines))
+	require.Equal(t, "author3", lines[0].AuthorName)
+	require.Equal(t, "author1", lines[1].AuthorName)
+	require.Equal(t, "author1", lines[3].AuthorName)
+}
+
+func TestExecDiffFileRevisionsIgnoreWhitespace(t *testing.T) {
+	repoDir, err := ResolveTestWhitespaceRepo()
+	require.Nil(t, err)
+
+	commits, err := ExecGetCommitsInDateRange(repoDir, "main", "", "now")
+	require.Nil(t, err)
+
+	de, err := ExecDiffFileRevisions(repoDir, "file1", commits[2].CommitId, commits[1].CommitId, GitOptions{})
+	require.Nil(t, err)
+	require.NotEmpty(t, de)
+
+	de, err = ExecDiffFileRevisions(repoDir, "file1", commits[2].CommitId, commits[1].CommitId, GitOptions{IgnoreWhitespace: true})
+	require.Nil(t, err)
+	require.Empty(t, de)
+}
+
+func TestReadIgnoreRevsFile(t *testing.T) {
+	revs, err := ReadIgnoreRevsFile("")
+	require.Nil(t, err)
+	require.Empty(t, revs)
+
+	file := t.TempDir() + "/.git-blame-ignore-revs"
+	err = os.WriteFile(file, []byte(`# formatting
+0123456789abcdef0123456789abcdef01234567
+
+abcdef1 # abbreviated
+`), 0644)
+	require.Nil(t, err)
+	revs, err = ReadIgnoreRevsFile(file)
+	require.Nil(t, err)
+	require.Equal(t, []string{"0123456789abcdef0123456789abcdef01234567", "abcdef1"}, revs)
+
+	err = os.WriteFile(file, []byte("HEAD~1\n"), 0644)
+	require.Nil(t, err)
+	_, err = ReadIgnoreRevsFile(file)
+	require.NotNil(t, err)
+}
+
+func TestIgnoreRevsKey(t *testing.T) {
+	require.Equal(t, "", BaseOptions{}.IgnoreRevsKey())
+
+	file := t.TempDir() + "/.git-blame-ignore-revs"
+	err := os.WriteFile(file, []byte("abcdef1\n"), 0644)
+	require.Nil(t, err)
+	opts := BaseOptions{IgnoreRevsFile: file}
+	key1 := opts.IgnoreRevsKey()
+
+	// comments and blank lines don't change the key
+	err = os.WriteFile(file, []byte("# formatting\n\nabcdef1 # abbreviated\n"), 0644)
+	require.Nil(t, err)
+	require.Equal(t, key1, opts.IgnoreRevsKey())
+
+	// other commits change the key
+	err = os.WriteFile(file, []byte("abcdef1\nabcdef2\n"), 0644)
+	require.Nil(t, err)
+	require.NotEqual(t, key1, opts.IgnoreRevsKey())
+}
+
+func TestExecGitBlameDetectMoves(t *testing.T) {
+	repoDir, err := ResolveTestMovedLinesRepo()
+	require.Nil(t, err)
+
+	commits, err := ExecGetCommitsInDateRange(repoDir, "main", "", "now")
+	require.Nil(t, err)
+	require.Equal(t, 2, len(commits))
+
+	lines, err := ExecGitBlame(repoDir, "file2", commits[0].CommitId, GitOptions{})
+	require.Nil(t, err)
+	require.Equal(t, 4, len(lines))
+	for _, line := range lines {
+		require.Equal(t, "author2", line.AuthorName)
+		require.Equal(t, "file2", line.FilePath)
+	}
+
+	// moved lines keep the author and the file where they were written
+	lines, err = ExecGitBlame(repoDir, "file2", commits[0].CommitId, GitOptions{DetectMoves: true})
+	require.Nil(t, err)
+	require.Equal(t, 4, len(lines))
+	require.Equal(t, "author2", lines[0].AuthorName)
+	require.Equal(t, "file2", lines[0].FilePath)
+	require.Equal(t, "", lines[0].MovedCommitId)
+	for _, line := range lines[1:] {
+		require.Equal(t, "author1", line.AuthorName)
+		require.Equal(t, "file1", line.FilePath)
+		require.Equal(t, commits[0].CommitId, line.MovedCommitId)
+		require.Equal(t, "author2", line.MovedByName)
+		require.Equal(t, "<author2@mail.com>", line.MovedByMail)
+	}
+}
+
+func TestExecGitBlameDetectMovesRenamed(t *testing.T) {
+	repoDir, err := ResolveTestRenamedMovedLinesRepo()
+	require.Nil(t, err)
+
+	commits, err := ExecGetCommitsInDateRange(repoDir, "main", "", "now")
+	require.Nil(t, err)
+	require.Equal(t, 3, len(commits))
+
+	// lines of a renamed file were not moved
+	lines, err := ExecGitBlame(repoDir, "file2", commits[1].CommitId, GitOptions{DetectMoves: true})
+	require.Nil(t, err)
+	require.Equal(t, 4, len(lines))
+	for _, line := range lines {
+		require.Equal(t, "author1", line.AuthorName)
+		require.Equal(t, "file1", line.FilePath)
+		require.Equal(t, "", line.MovedCommitId)
+	}
+
+	// lines moved after the rename
+	lines, err = ExecGitBlame(repoDir, "file3", commits[0].CommitId, GitOptions{DetectMoves: true})
+	require.Nil(t, err)
+	require.Equal(t, 4, len(lines))
+	require.Equal(t, "", lines[0].MovedCommitId)
+	for _, line := range lines[1:] {
+		require.Equal(t, "author1", line.AuthorName)
+		require.Equal(t, commits[0].CommitId, line.MovedCommitId)
+		require.Equal(t, "author1", line.MovedByName)
+	}
+}
+
+func TestExecMergeCommits(t *testing.T) {
+	repoDir, err := ResolveTestMergesRepo()
+	require.Nil(t, err)
+
+	commits, err := ExecGetCommitsInCommitRange(repoDir, "main", "", "")
+	require.Nil(t, err)
+	require.Equal(t, 4, len(commits))
+	mergeId := commits[0].CommitId
+	firstId := commits[len(commits)-1].CommitId
+
+	mergeIds, err := ExecMergeCommitIds(repoDir, "", mergeId)
+	require.Nil(t, err)
+	require.Equal(t, []string{mergeId}, mergeIds)
+
+	firstParentIds, err := ExecFirstParentCommitIds(repoDir, "", mergeId)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(firstParentIds))
+	require.Equal(t, mergeId, firstParentIds[0])
+	require.Equal(t, firstId, firstParentIds[2])
+
+	// the since commit is not returned
+	firstParentIds, err = ExecFirstParentCommitIds(repoDir, firstId, mergeId)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(firstParentIds))
+
+	// merges show no changes in diff-tree
+	files, err := ExecDiffTree(repoDir, mergeId)
+	require.Nil(t, err)
+	require.Empty(t, files)
+
+	files, err = ExecDiffTreeMerge(repoDir, mergeId)
+	require.Nil(t, err)
+	require.Equal(t, []string{"file1"}, files)
+}
+
+func TestParseCoAuthors(t *testing.T) {
+	message := "fix something\n\nlonger description\n\nCo-authored-by: Mary Doe <mary@mail.com>\nco-authored-by:John <john@mail.com>\nCo-authored-by: Mary Doe <mary@mail.com>\nSigned-off-by: Mary Doe <mary@mail.com>\n"
+	coAuthors := ParseCoAuthors(message)
+	require.Equal(t, []CoAuthor{{Name: "Mary Doe", Mail: "<mary@mail.com>"}, {Name: "John", Mail: "<john@mail.com>"}}, coAuthors)
+
+	require.Nil(t, ParseCoAuthors("no trailers"))
+}
@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Identity is a person that might have used different names and emails in commits
+type Identity struct {
+	// Name is the canonical name of the person
+	Name string `yaml:"name" json:"name"`
+	// Mail is the canonical email of the person
+	Mail string `yaml:"mail" json:"mail"`
+	// Names are other names used by the same person in commits
+	Names []string `yaml:"names" json:"names"`
+	// Mails are other emails used by the same person in commits
+	Mails []string `yaml:"mails" json:"mails"`
+}
+
+// IdentityResolver maps the different names/emails found in commits to a canonical identity
+// using the repo .mailmap and an optional identities file
+type IdentityResolver struct {
+	// .mailmap entries indexed by "commit name###commit mail"
+	mailmapNameMail map[string]Identity
+	// .mailmap entries indexed by "commit mail"
+	mailmapMail map[string]Identity
+	// identities indexed by any of its mails
+	identitiesMail map[string]Identity
+	// identities indexed by any of its names
+	identitiesName map[string]Identity
+}
+
+var mailmapLineRe = regexp.MustCompile(`^([^<]*)<([^>]*)>\s*([^<]*)(<([^>]*)>)?\s*$`)
+
+// NewIdentityResolver loads the .mailmap file found in repoDir (if it exists)
+// and the identities yaml file (if defined). As in git, .mailmap is read from the
+// working tree, so the same mappings are used for all analysed commits
+func NewIdentityResolver(repoDir string, identitiesFile string) (*IdentityResolver, error) {
+	resolver := &IdentityResolver{
+		mailmapNameMail: make(map[string]Identity, 0),
+		mailmapMail:     make(map[string]Identity, 0),
+		identitiesMail:  make(map[string]Identity, 0),
+		identitiesName:  make(map[string]Identity, 0),
+	}
+
+	mailmapContents, err := os.ReadFile(filepath.Join(repoDir, ".mailmap"))
+	if err == nil {
+		err = resolver.addMailmap(string(mailmapContents))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if identitiesFile != "" {
+		identitiesContents, err := os.ReadFile(identitiesFile)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't read identities file. err=%s", err)
+		}
+		identities := make([]Identity, 0)
+		err = yaml.Unmarshal(identitiesContents, &identities)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't parse identities file. err=%s", err)
+		}
+		resolver.AddIdentities(identities)
+	}
+
+	return resolver, nil
+}
+
+// AddIdentities registers identities in the resolver. They have precedence over .mailmap entries
+func (r *IdentityResolver) AddIdentities(identities []Identity) {
+	for _, identity := range identities {
+		identity.Mail = trimMail(identity.Mail)
+		for _, name := range append([]string{identity.Name}, identity.Names...) {
+			if name != "" {
+				r.identitiesName[strings.ToLower(name)] = identity
+			}
+		}
+		for _, mail := range append([]string{identity.Mail}, identity.Mails...) {
+			if mail != "" {
+				r.identitiesMail[normalizeMail(mail)] = identity
+			}
+		}
+	}
+}
+
+func (r *IdentityResolver) addMailmap(contents string) error {
+	lines, err := linesToArray(contents)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		// mailmap formats (see https://git-scm.com/docs/gitmailmap)
+		// Proper Name <commit@email>
+		// <proper@email> <commit@email>
+		// Proper Name <proper@email> <commit@email>
+		// Proper Name <proper@email> Commit Name <commit@email>
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		matches := mailmapLineRe.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		properName := strings.TrimSpace(matches[1])
+		properMail := trimMail(matches[2])
+		commitName := strings.TrimSpace(matches[3])
+		commitMail := normalizeMail(matches[5])
+
+		// only one email means it's both the proper and the commit email
+		if matches[4] == "" {
+			commitMail = normalizeMail(properMail)
+			properMail = ""
+		}
+
+		identity := Identity{Name: properName, Mail: properMail}
+		if commitName != "" {
+			r.mailmapNameMail[strings.ToLower(commitName)+"###"+commitMail] = identity
+			continue
+		}
+		r.mailmapMail[commitMail] = identity
+	}
+	return nil
+}
+
+// Resolve returns the canonical name and mail for an author found in commits.
+// Mails surrounded by "<>" are returned in the same format
+func (r *IdentityResolver) Resolve(name string, mail string) (string, string) {
+	if r == nil {
+		return name, mail
+	}
+	brackets := strings.HasPrefix(mail, "<")
+	resolvedName := name
+	resolvedMail := trimMail(mail)
+
+	// .mailmap
+	mailmapIdentity, ok := r.mailmapNameMail[strings.ToLower(name)+"###"+normalizeMail(resolvedMail)]
+	if !ok {
+		mailmapIdentity, ok = r.mailmapMail[normalizeMail(resolvedMail)]
+	}
+	if ok {
+		if mailmapIdentity.Name != "" {
+			resolvedName = mailmapIdentity.Name
+		}
+		if mailmapIdentity.Mail != "" {
+			resolvedMail = mailmapIdentity.Mail
+		}
+	}
+
+	// identities file
+	identity, ok := r.identitiesMail[normalizeMail(resolvedMail)]
+	if !ok {
+		identity, ok = r.identitiesName[strings.ToLower(resolvedName)]
+	}
+	if ok {
+		if identity.Name != "" {
+			resolvedName = identity.Name
+		}
+		if identity.Mail != "" {
+			resolvedMail = identity.Mail
+		}
+	}
+
+	if brackets {
+		return resolvedName, fmt.Sprintf("<%s>", resolvedMail)
+	}
+	return resolvedName, resolvedMail
+}
+
+// ResolveBlameLines replaces the authors of blame lines by their canonical identities
+func (r *IdentityResolver) ResolveBlameLines(lines []BlameLine) []BlameLine {
+	if r == nil {
+		return lines
+	}
+	for i := range lines {
+		lines[i].AuthorName, lines[i].AuthorMail = r.Resolve(lines[i].AuthorName, lines[i].AuthorMail)
+		if lines[i].MovedCommitId != "" {
+			lines[i].MovedByName, lines[i].MovedByMail = r.Resolve(lines[i].MovedByName, lines[i].MovedByMail)
+		}
+	}
+	return lines
+}
+
+// ResolveCommitInfo replaces the author and co-authors of a commit by their canonical identities
+func (r *IdentityResolver) ResolveCommitInfo(commitInfo CommitInfo) CommitInfo {
+	if r == nil {
+		return commitInfo
+	}
+	commitInfo.AuthorName, commitInfo.AuthorMail = r.Resolve(commitInfo.AuthorName, commitInfo.AuthorMail)
+	if len(commitInfo.CoAuthors) > 0 {
+		coAuthors := make([]CoAuthor, len(commitInfo.CoAuthors))
+		for i, coAuthor := range commitInfo.CoAuthors {
+			coAuthors[i].Name, coAuthors[i].Mail = r.Resolve(coAuthor.Name, coAuthor.Mail)
+		}
+		commitInfo.CoAuthors = coAuthors
+	}
+	return commitInfo
+}
+
+func trimMail(mail string) string {
+	return strings.Trim(strings.TrimSpace(mail), "<>")
+}
+
+func normalizeMail(mail string) string {
+	return strings.ToLower(trimMail(mail))
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentityResolverMailmap(t *testing.T) {
+	repoDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(repoDir, ".mailmap"), []byte(`# comment
+Flávio Stutz <flaviostutz@gmail.com>
+<flaviostutz@gmail.com> <flavio@old.com>
+Flávio Stutz <flaviostutz@gmail.com> Flavio Stutz <flaviostutz@test.nl>
+Other Person <other@mail.com> <OTHER@work.com> # trailing comment
+`), 0644)
+	require.Nil(t, err)
+
+	resolver, err := NewIdentityResolver(repoDir, "")
+	require.Nil(t, err)
+
+	// name replaced by mail
+	name, mail := resolver.Resolve("flavio", "<flaviostutz@gmail.com>")
+	require.Equal(t, "Flávio Stutz", name)
+	require.Equal(t, "<flaviostutz@gmail.com>", mail)
+
+	// mail replaced by mail
+	name, mail = resolver.Resolve("flavio", "flavio@old.com")
+	require.Equal(t, "flavio", name)
+	require.Equal(t, "flaviostutz@gmail.com", mail)
+
+	// name and mail replaced by name and mail
+	name, mail = resolver.Resolve("Flavio Stutz", "<flaviostutz@test.nl>")
+	require.Equal(t, "Flávio Stutz", name)
+	require.Equal(t, "<flaviostutz@gmail.com>", mail)
+
+	// same mail, but different name
+	name, mail = resolver.Resolve("Someone else", "<flaviostutz@test.nl>")
+	require.Equal(t, "Someone else", name)
+	require.Equal(t, "<flaviostutz@test.nl>", mail)
+
+	// mails are case insensitive
+	name, mail = resolver.Resolve("other", "other@work.com")
+	require.Equal(t, "Other Person", name)
+	require.Equal(t, "other@mail.com", mail)
+
+	// unknown
+	name, mail = resolver.Resolve("unknown", "<unknown@mail.com>")
+	require.Equal(t, "unknown", name)
+	require.Equal(t, "<unknown@mail.com>", mail)
+}
+
+func TestIdentityResolverIdentitiesFile(t *testing.T) {
+	identitiesFile := filepath.Join(t.TempDir(), "identities.yml")
+	err := os.WriteFile(identitiesFile, []byte(`
+- name: Flávio Stutz
+  mail: flaviostutz@gmail.com
+  names:
+    - Flavio Stutz
+    - flavio
+  mails:
+    - flaviostutz@test.nl
+`), 0644)
+	require.Nil(t, err)
+
+	resolver, err := NewIdentityResolver(t.TempDir(), identitiesFile)
+	require.Nil(t, err)
+
+	name, mail := resolver.Resolve("Flavio Stutz", "<flaviostutz@something.com>")
+	require.Equal(t, "Flávio Stutz", name)
+	require.Equal(t, "<flaviostutz@gmail.com>", mail)
+
+	name, mail = resolver.Resolve("Whatever", "flaviostutz@test.nl")
+	require.Equal(t, "Flávio Stutz", name)
+	require.Equal(t, "flaviostutz@gmail.com", mail)
+
+	lines := resolver.ResolveBlameLines([]BlameLine{{AuthorName: "flavio", AuthorMail: "<a@b.com>"}, {AuthorName: "another", AuthorMail: "<c@d.com>"}})
+	require.Equal(t, "Flávio Stutz", lines[0].AuthorName)
+	require.Equal(t, "another", lines[1].AuthorName)
+
+	commitInfo := resolver.ResolveCommitInfo(CommitInfo{AuthorName: "flavio", AuthorMail: "<a@b.com>"})
+	require.Equal(t, "Flávio Stutz", commitInfo.AuthorName)
+	require.Equal(t, "<flaviostutz@gmail.com>", commitInfo.AuthorMail)
+
+	_, err = NewIdentityResolver(t.TempDir(), "/invalid/identities/file.yml")
+	require.NotNil(t, err)
+}
+
+func TestIdentitiesKeyFileContents(t *testing.T) {
+	repoDir := t.TempDir()
+	identitiesFile := filepath.Join(t.TempDir(), "identities.yml")
+	opts := BaseOptions{RepoDir: repoDir, IdentitiesFile: identitiesFile}
+	key1 := opts.IdentitiesKey()
+
+	// changing the identities file contents changes the key
+	err := os.WriteFile(identitiesFile, []byte("- name: Someone\n  mail: someone@mail.com\n"), 0644)
+	require.Nil(t, err)
+	key2 := opts.IdentitiesKey()
+	require.NotEqual(t, key1, key2)
+
+	// changing the repo .mailmap changes the key
+	err = os.WriteFile(filepath.Join(repoDir, ".mailmap"), []byte("Someone <someone@mail.com> <other@mail.com>\n"), 0644)
+	require.Nil(t, err)
+	key3 := opts.IdentitiesKey()
+	require.NotEqual(t, key2, key3)
+	require.Equal(t, key3, opts.IdentitiesKey())
+
+	// inline identities are part of the key
+	opts.Identities = []Identity{{Name: "Someone", Mail: "someone@mail.com"}}
+	require.NotEqual(t, key3, opts.IdentitiesKey())
+}
@@ -1,12 +1,78 @@
 package utils
 
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/segmentio/fasthash/fnv1a"
+)
+
 type BaseOptions struct {
 	Branch          string `json:"branch"`
 	FilesRegex      string `json:"files_regex"`
 	FilesNotRegex   string `json:"files_not_regex"`
 	AuthorsRegex    string `json:"authors_regex"`
 	AuthorsNotRegex string `json:"authors_not_regex"`
+	IdentitiesFile  string `json:"identities_file"`
+	TeamsFile       string `json:"teams_file"`
+	// GitBackend how git is accessed. See NewGitBackend
+	GitBackend      string `json:"git_backend"`
 	RepoDir         string `json:"repo_dir"`
 	CacheFile       string `json:"cache_file"`
 	CacheTTLSeconds int    `json:"cache_ttl_seconds"`
+	// IgnoreWhitespace whitespace and blank line only changes are ignored by blame and diffs
+	IgnoreWhitespace bool `json:"ignore_whitespace"`
+	// IgnoreRevsFile file with commits to be ignored, as in .git-blame-ignore-revs
+	IgnoreRevsFile string `json:"ignore_revs_file"`
+	// ExcludePresets comma separated presets of authors and files excluded from analysis. See ExcludePresetBots etc
+	ExcludePresets string `json:"exclude_presets"`
+	// Identities are used in addition to the ones in IdentitiesFile. Usually defined in the config file
+	Identities []Identity `json:"identities,omitempty"`
+}
+
+// IdentitiesKey short hash of the inline identities and of the contents of the identities file
+// and of the repo .mailmap, used to compose cache keys
+func (o BaseOptions) IdentitiesKey() string {
+	identitiesJSON, _ := json.Marshal(o.Identities)
+	h := fnv1a.HashBytes64(identitiesJSON)
+	h = addFileContentsHash(h, o.IdentitiesFile)
+	h = addFileContentsHash(h, filepath.Join(o.RepoDir, ".mailmap"))
+	return fmt.Sprintf("%x", h)
+}
+
+// TeamsKey short hash of the contents of the teams file, used to compose cache keys
+func (o BaseOptions) TeamsKey() string {
+	if o.TeamsFile == "" {
+		return ""
+	}
+	return fmt.Sprintf("%x", addFileContentsHash(fnv1a.Init64, o.TeamsFile))
+}
+
+// IgnoreRevsKey short hash of the commits listed in the ignore revs file, used to compose cache keys.
+// Comments and blank lines don't change the key
+func (o BaseOptions) IgnoreRevsKey() string {
+	if o.IgnoreRevsFile == "" {
+		return ""
+	}
+	revs, err := ReadIgnoreRevsFile(o.IgnoreRevsFile)
+	if err != nil {
+		return fmt.Sprintf("%x", addFileContentsHash(fnv1a.Init64, o.IgnoreRevsFile))
+	}
+	return fmt.Sprintf("%x", fnv1a.HashString64(strings.Join(revs, ",")))
+}
+
+// addFileContentsHash adds the contents of a file to a hash so that cached results are
+// not reused after the file changes. Missing files don't change the hash
+func addFileContentsHash(h uint64, file string) uint64 {
+	if file == "" {
+		return h
+	}
+	contents, err := os.ReadFile(file)
+	if err != nil {
+		return h
+	}
+	return fnv1a.AddBytes64(h, contents)
 }
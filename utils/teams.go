@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NoTeamName is the team used for authors that don't match any team
+const NoTeamName = "(no team)"
+
+// Team is a group of authors whose results are aggregated together
+type Team struct {
+	Name string `yaml:"name" json:"name"`
+	// Authors are regexes matched against the author name or email
+	Authors []string `yaml:"authors" json:"authors"`
+}
+
+// TeamResolver finds the team of an author. The first team
+// (in the order they were defined) that matches the author is used
+type TeamResolver struct {
+	teams []teamMatcher
+}
+
+type teamMatcher struct {
+	name      string
+	authorsRe []*regexp.Regexp
+}
+
+// NewTeamResolver loads teams from a yaml file in the format "team: [author regexes]".
+// Returns nil if teamsFile is not defined
+func NewTeamResolver(teamsFile string) (*TeamResolver, error) {
+	if teamsFile == "" {
+		return nil, nil
+	}
+	contents, err := os.ReadFile(teamsFile)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read teams file. err=%s", err)
+	}
+	teams, err := parseTeams(contents)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't parse teams file. err=%s", err)
+	}
+	resolver := &TeamResolver{teams: make([]teamMatcher, 0)}
+	err = resolver.AddTeams(teams)
+	if err != nil {
+		return nil, err
+	}
+	return resolver, nil
+}
+
+// AddTeams registers teams in the resolver
+func (r *TeamResolver) AddTeams(teams []Team) error {
+	for _, team := range teams {
+		matcher := teamMatcher{name: team.Name, authorsRe: make([]*regexp.Regexp, 0)}
+		for _, author := range team.Authors {
+			re, err := regexp.Compile(author)
+			if err != nil {
+				return fmt.Errorf("Invalid author regex for team %s. err=%s", team.Name, err)
+			}
+			matcher.authorsRe = append(matcher.authorsRe, re)
+		}
+		r.teams = append(r.teams, matcher)
+	}
+	return nil
+}
+
+// TeamNames returns the names of all teams in the order they were defined
+func (r *TeamResolver) TeamNames() []string {
+	names := make([]string, 0)
+	if r == nil {
+		return names
+	}
+	for _, team := range r.teams {
+		names = append(names, team.name)
+	}
+	return names
+}
+
+// TeamOf returns the name of the team the author belongs to or NoTeamName
+func (r *TeamResolver) TeamOf(authorName string, authorMail string) string {
+	if r == nil {
+		return NoTeamName
+	}
+	for _, team := range r.teams {
+		for _, re := range team.authorsRe {
+			if re.MatchString(authorName) || re.MatchString(authorMail) {
+				return team.name
+			}
+		}
+	}
+	return NoTeamName
+}
+
+// parseTeams reads a yaml map keeping the order in which teams were defined
+func parseTeams(contents []byte) ([]Team, error) {
+	teams := make([]Team, 0)
+	var doc yaml.Node
+	err := yaml.Unmarshal(contents, &doc)
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return teams, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("teams file must be a map of team name to list of authors")
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		team := Team{Name: root.Content[i].Value, Authors: make([]string, 0)}
+		err := root.Content[i+1].Decode(&team.Authors)
+		if err != nil {
+			return nil, fmt.Errorf("team %s must have a list of authors. err=%s", team.Name, err)
+		}
+		teams = append(teams, team)
+	}
+	return teams, nil
+}
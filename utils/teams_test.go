@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamResolver(t *testing.T) {
+	teamsFile := filepath.Join(t.TempDir(), "teams.yml")
+	err := os.WriteFile(teamsFile, []byte(`
+squad-b:
+  - author2
+  - "@squad-b.com"
+squad-a:
+  - author[12]
+`), 0644)
+	require.Nil(t, err)
+
+	teams, err := NewTeamResolver(teamsFile)
+	require.Nil(t, err)
+	require.Equal(t, []string{"squad-b", "squad-a"}, teams.TeamNames())
+
+	// first team defined in file has precedence
+	require.Equal(t, "squad-b", teams.TeamOf("author2", "<author2@mail.com>"))
+	require.Equal(t, "squad-a", teams.TeamOf("author1", "<author1@mail.com>"))
+	require.Equal(t, "squad-b", teams.TeamOf("someone", "<someone@squad-b.com>"))
+	require.Equal(t, NoTeamName, teams.TeamOf("author3", "<author3@mail.com>"))
+
+	// no teams file
+	teams, err = NewTeamResolver("")
+	require.Nil(t, err)
+	require.Nil(t, teams)
+	require.Equal(t, NoTeamName, teams.TeamOf("author1", "<author1@mail.com>"))
+
+	// invalid regex
+	err = os.WriteFile(teamsFile, []byte("squad-a:\n  - \"author(\"\n"), 0644)
+	require.Nil(t, err)
+	_, err = NewTeamResolver(teamsFile)
+	require.NotNil(t, err)
+}
+
+func TestTeamsKeyFileContents(t *testing.T) {
+	require.Equal(t, "", BaseOptions{}.TeamsKey())
+
+	teamsFile := filepath.Join(t.TempDir(), "teams.yml")
+	err := os.WriteFile(teamsFile, []byte("squad-a:\n  - author1\n"), 0644)
+	require.Nil(t, err)
+	opts := BaseOptions{TeamsFile: teamsFile}
+	key1 := opts.TeamsKey()
+	require.Equal(t, key1, opts.TeamsKey())
+
+	// changing the teams file contents changes the key
+	err = os.WriteFile(teamsFile, []byte("squad-a:\n  - author2\n"), 0644)
+	require.Nil(t, err)
+	require.NotEqual(t, key1, opts.TeamsKey())
+}
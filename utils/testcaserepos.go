@@ -11,6 +11,17 @@ import (
 var (
 	ownershipRepoDir                 *string
 	ownershipDuplicatesRepoDir       *string
+	deletedFilesRepoDir              *string
+	renamedFilesRepoDir              *string
+	copiedFilesRepoDir               *string
+	revertedChangesRepoDir           *string
+	whitespaceRepoDir                *string
+	movedLinesRepoDir                *string
+	renamedMovedLinesRepoDir         *string
+	mergesRepoDir                    *string
+	coAuthorsRepoDir                 *string
+	excludePresetsRepoDir            *string
+	attributesRepoDir                *string
 	ownershipTestRepoFirstCommitHash string
 	ownershipTestRepoLastCommitHash  string
 )
@@ -20,36 +31,7 @@ func ResolveTestOwnershipRepo() (string, error) {
 		return *ownershipRepoDir, nil
 	}
 
-	curDir, err := os.Getwd()
-	if err != nil {
-		return "", err
-	}
-
-	testCasesDir := curDir + "/.testcaserepos"
-	repoDir := testCasesDir + "/ownership"
-	// fmt.Printf("repoDir=%s\n", repoDir)
-
-	// remove repo if exists
-	_, err = ExecShellf("", "rm -rf %s", repoDir)
-	if err != nil {
-		return "", err
-	}
-
-	// create base dir for testcases
-	ExecShellf("", "mkdir -p %s", testCasesDir)
-
-	fmt.Println("Creating test repo")
-	_, err = ExecShellf(testCasesDir, "git init ownership --initial-branch main")
-	if err != nil {
-		return "", err
-	}
-
-	_, err = ExecShellf(repoDir, "git config user.email \"you@example.com\"")
-	if err != nil {
-		return "", err
-	}
-
-	_, err = ExecShellf(repoDir, "git config user.name \"Your Name\"")
+	repoDir, err := initTestRepo("ownership")
 	if err != nil {
 		return "", err
 	}
@@ -102,36 +84,7 @@ func ResolveTestOwnershipDuplicatesRepo() (string, error) {
 		return *ownershipDuplicatesRepoDir, nil
 	}
 
-	curDir, err := os.Getwd()
-	if err != nil {
-		return "", err
-	}
-
-	testCasesDir := curDir + "/.testcaserepos"
-	repoDir := testCasesDir + "/ownership-dup"
-	// fmt.Printf("repoDir=%s\n", repoDir)
-
-	// remove repo if exists
-	_, err = ExecShellf("", "rm -rf %s", repoDir)
-	if err != nil {
-		return "", err
-	}
-
-	// create base dir for testcases
-	ExecShellf("", "mkdir -p %s", testCasesDir)
-
-	fmt.Println("Creating test repo")
-	_, err = ExecShellf(testCasesDir, "git init ownership-dup --initial-branch main")
-	if err != nil {
-		return "", err
-	}
-
-	_, err = ExecShellf(repoDir, "git config user.email \"you@example.com\"")
-	if err != nil {
-		return "", err
-	}
-
-	_, err = ExecShellf(repoDir, "git config user.name \"Your Name\"")
+	repoDir, err := initTestRepo("ownership-dup")
 	if err != nil {
 		return "", err
 	}
@@ -174,6 +127,593 @@ bbbbbbbbbbbbbbbbbbbb`)
 	return repoDir, nil
 }
 
+// ResolveTestDeletedFilesRepo repo in which author2 deletes a line of file1
+// and the whole file2, both created by author1
+func ResolveTestDeletedFilesRepo() (string, error) {
+	if deletedFilesRepoDir != nil {
+		return *deletedFilesRepoDir, nil
+	}
+
+	repoDir, err := initTestRepo("deleted-files")
+	if err != nil {
+		return "", err
+	}
+
+	// DON'T CHANGE THE REPO CONTENTS
+	// there are unit tests that depends exactly on how it is
+
+	// commit 1
+	err = writeAddFile(repoDir, "file1", "a\nb\n")
+	if err != nil {
+		return "", err
+	}
+	err = writeAddFile(repoDir, "file2", "a\nb\nc\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 1", "author1")
+	if err != nil {
+		return "", err
+	}
+
+	// commit 2
+	err = writeAddFile(repoDir, "file1", "a\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = ExecShellf(repoDir, "git rm file2")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 2", "author2")
+	if err != nil {
+		return "", err
+	}
+
+	deletedFilesRepoDir = &repoDir
+	return repoDir, nil
+}
+
+// ResolveTestRenamedFilesRepo repo in which author2 renames file1, created
+// by author1, to dir1/file3 and changes one of its lines
+func ResolveTestRenamedFilesRepo() (string, error) {
+	if renamedFilesRepoDir != nil {
+		return *renamedFilesRepoDir, nil
+	}
+
+	repoDir, err := initTestRepo("renamed-files")
+	if err != nil {
+		return "", err
+	}
+
+	// DON'T CHANGE THE REPO CONTENTS
+	// there are unit tests that depends exactly on how it is
+
+	// commit 1
+	err = writeAddFile(repoDir, "file1", "aaaa\nbbbb\ncccc\ndddd\neeee\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 1", "author1")
+	if err != nil {
+		return "", err
+	}
+
+	// commit 2
+	_, err = ExecShellf(repoDir, "mkdir dir1 && git mv file1 dir1/file3")
+	if err != nil {
+		return "", err
+	}
+	err = writeAddFile(repoDir, "dir1/file3", "aaaa\nbbbb\nXXXX\ndddd\neeee\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 2", "author2")
+	if err != nil {
+		return "", err
+	}
+
+	renamedFilesRepoDir = &repoDir
+	return repoDir, nil
+}
+
+// ResolveTestCopiedFilesRepo creates a repo in which the second commit copies a file while changing it
+func ResolveTestCopiedFilesRepo() (string, error) {
+	if copiedFilesRepoDir != nil {
+		return *copiedFilesRepoDir, nil
+	}
+
+	repoDir, err := initTestRepo("copied-files")
+	if err != nil {
+		return "", err
+	}
+
+	// DON'T CHANGE THE REPO CONTENTS
+	// there are unit tests that depends exactly on how it is
+
+	// commit 1
+	err = writeAddFile(repoDir, "file1", "aaaa\nbbbb\ncccc\ndddd\neeee\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 1", "author1")
+	if err != nil {
+		return "", err
+	}
+
+	// commit 2
+	err = writeAddFile(repoDir, "dir1/file2", "aaaa\nbbbb\ncccc\ndddd\neeee\n")
+	if err != nil {
+		return "", err
+	}
+	err = writeAddFile(repoDir, "file1", "aaaa\nbbbb\nXXXX\ndddd\neeee\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 2", "author2")
+	if err != nil {
+		return "", err
+	}
+
+	copiedFilesRepoDir = &repoDir
+	return repoDir, nil
+}
+
+// ResolveTestRevertedChangesRepo creates a repo in which a line is changed by
+// the second commit and reverted to its previous contents by the third commit
+func ResolveTestRevertedChangesRepo() (string, error) {
+	if revertedChangesRepoDir != nil {
+		return *revertedChangesRepoDir, nil
+	}
+
+	repoDir, err := initTestRepo("reverted-changes")
+	if err != nil {
+		return "", err
+	}
+
+	// DON'T CHANGE THE REPO CONTENTS
+	// there are unit tests that depends exactly on how it is
+
+	// commit 1
+	err = writeAddFile(repoDir, "file1", "aaaa\nbbbb\ncccc\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 1", "author1")
+	if err != nil {
+		return "", err
+	}
+
+	// commit 2
+	err = writeAddFile(repoDir, "file1", "aaaa\nXXXX\ncccc\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 2", "author2")
+	if err != nil {
+		return "", err
+	}
+
+	// commit 3
+	err = writeAddFile(repoDir, "file1", "aaaa\nbbbb\ncccc\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 3", "author2")
+	if err != nil {
+		return "", err
+	}
+
+	revertedChangesRepoDir = &repoDir
+	return repoDir, nil
+}
+
+// ResolveTestWhitespaceRepo creates a repo in which the second commit only reformats a file
+func ResolveTestWhitespaceRepo() (string, error) {
+	if whitespaceRepoDir != nil {
+		return *whitespaceRepoDir, nil
+	}
+
+	repoDir, err := initTestRepo("whitespace")
+	if err != nil {
+		return "", err
+	}
+
+	// DON'T CHANGE THE REPO CONTENTS
+	// there are unit tests that depends exactly on how it is
+
+	// commit 1
+	err = writeAddFile(repoDir, "file1", "aaaa\nbbbb\ncccc\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 1", "author1")
+	if err != nil {
+		return "", err
+	}
+
+	// commit 2 (reformat only)
+	err = writeAddFile(repoDir, "file1", "aaaa\n  bbbb\n\n  cccc\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 2", "author2")
+	if err != nil {
+		return "", err
+	}
+
+	// commit 3
+	err = writeAddFile(repoDir, "file1", "XXXX\n  bbbb\n\n  cccc\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 3", "author3")
+	if err != nil {
+		return "", err
+	}
+
+	whitespaceRepoDir = &repoDir
+	return repoDir, nil
+}
+
+// ResolveTestMovedLinesRepo creates a repo in which the second commit moves part of a file to another file
+func ResolveTestMovedLinesRepo() (string, error) {
+	if movedLinesRepoDir != nil {
+		return *movedLinesRepoDir, nil
+	}
+
+	repoDir, err := initTestRepo("moved-lines")
+	if err != nil {
+		return "", err
+	}
+
+	// DON'T CHANGE THE REPO CONTENTS
+	// there are unit tests that depends exactly on how it is
+
+	// lines must be long enough to be detected as moved by git blame
+	movedLines := "func first() { return \"first line that will be moved\" }\n" +
+		"func second() { return \"second line that will be moved\" }\n" +
+		"func third() { return \"third line that will be moved\" }\n"
+
+	// commit 1
+	err = writeAddFile(repoDir, "file1", "package one\n"+movedLines)
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 1", "author1")
+	if err != nil {
+		return "", err
+	}
+
+	// commit 2 (moves lines from file1 to file2)
+	err = writeAddFile(repoDir, "file1", "package one\n")
+	if err != nil {
+		return "", err
+	}
+	err = writeAddFile(repoDir, "file2", "package two\n"+movedLines)
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 2", "author2")
+	if err != nil {
+		return "", err
+	}
+
+	movedLinesRepoDir = &repoDir
+	return repoDir, nil
+}
+
+// ResolveTestRenamedMovedLinesRepo creates a repo in which the second commit only renames
+// a file (git mv) and the third commit moves lines of that file to another file by their own author
+func ResolveTestRenamedMovedLinesRepo() (string, error) {
+	if renamedMovedLinesRepoDir != nil {
+		return *renamedMovedLinesRepoDir, nil
+	}
+
+	repoDir, err := initTestRepo("renamed-moved-lines")
+	if err != nil {
+		return "", err
+	}
+
+	// DON'T CHANGE THE REPO CONTENTS
+	// there are unit tests that depends exactly on how it is
+
+	// lines must be long enough to be detected as moved by git blame
+	movedLines := "func first() { return \"first line that will be moved\" }\n" +
+		"func second() { return \"second line that will be moved\" }\n" +
+		"func third() { return \"third line that will be moved\" }\n"
+
+	// commit 1
+	err = writeAddFile(repoDir, "file1", "package one\n"+movedLines)
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 1", "author1")
+	if err != nil {
+		return "", err
+	}
+
+	// commit 2 (only renames file1 to file2)
+	_, err = ExecShellf(repoDir, "git mv file1 file2")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 2", "author2")
+	if err != nil {
+		return "", err
+	}
+
+	// commit 3 (author1 moves its own lines from file2 to file3)
+	err = writeAddFile(repoDir, "file2", "package one\n")
+	if err != nil {
+		return "", err
+	}
+	err = writeAddFile(repoDir, "file3", "package three\n"+movedLines)
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 3", "author1")
+	if err != nil {
+		return "", err
+	}
+
+	renamedMovedLinesRepoDir = &repoDir
+	return repoDir, nil
+}
+
+// ResolveTestMergesRepo creates a repo with a branch that is merged back to main.
+// The merge commit also changes a line while resolving the merge
+func ResolveTestMergesRepo() (string, error) {
+	if mergesRepoDir != nil {
+		return *mergesRepoDir, nil
+	}
+
+	repoDir, err := initTestRepo("merges")
+	if err != nil {
+		return "", err
+	}
+
+	// DON'T CHANGE THE REPO CONTENTS
+	// there are unit tests that depends exactly on how it is
+
+	// commit 1 (main)
+	err = writeAddFile(repoDir, "file1", "a\nb\nc\nd\ne\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 1", "author1")
+	if err != nil {
+		return "", err
+	}
+
+	// commit 2 (feature)
+	_, err = ExecShellf(repoDir, "git checkout -b feature")
+	if err != nil {
+		return "", err
+	}
+	err = writeAddFile(repoDir, "file1", "a\nB\nc\nd\ne\n")
+	if err != nil {
+		return "", err
+	}
+	err = writeAddFile(repoDir, "file2", "x\ny\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 2", "author2")
+	if err != nil {
+		return "", err
+	}
+
+	// commit 3 (main)
+	_, err = ExecShellf(repoDir, "git checkout main")
+	if err != nil {
+		return "", err
+	}
+	err = writeAddFile(repoDir, "file1", "a\nb\nc\nd\nE\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 3", "author1")
+	if err != nil {
+		return "", err
+	}
+
+	// commit 4 (merge feature into main changing another line)
+	_, err = ExecShellf(repoDir, "git merge --no-ff --no-commit feature")
+	if err != nil {
+		return "", err
+	}
+	err = writeAddFile(repoDir, "file1", "A\nB\nc\nd\nE\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 4", "author3")
+	if err != nil {
+		return "", err
+	}
+
+	mergesRepoDir = &repoDir
+	return repoDir, nil
+}
+
+// ResolveTestCoAuthorsRepo creates a repo whose commits have "Co-authored-by" trailers
+func ResolveTestCoAuthorsRepo() (string, error) {
+	if coAuthorsRepoDir != nil {
+		return *coAuthorsRepoDir, nil
+	}
+
+	repoDir, err := initTestRepo("coauthors")
+	if err != nil {
+		return "", err
+	}
+
+	// DON'T CHANGE THE REPO CONTENTS
+	// there are unit tests that depends exactly on how it is
+
+	// commit 1 (author1 paired with author2)
+	err = writeAddFile(repoDir, "file1", "a\nb\nc\nd\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 1\n\nCo-authored-by: author2 <author2@mail.com>", "author1")
+	if err != nil {
+		return "", err
+	}
+
+	// commit 2 (author3 paired with author1 and author2)
+	err = writeAddFile(repoDir, "file1", "a\nb\nc\nd\ne\nf\ng\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 2\n\nCo-authored-by: author1 <author1@mail.com>\nco-authored-by: author2 <author2@mail.com>", "author3")
+	if err != nil {
+		return "", err
+	}
+
+	coAuthorsRepoDir = &repoDir
+	return repoDir, nil
+}
+
+// ResolveTestExcludePresetsRepo creates a repo with lock files, vendored and generated
+// files and a commit from a bot
+func ResolveTestExcludePresetsRepo() (string, error) {
+	if excludePresetsRepoDir != nil {
+		return *excludePresetsRepoDir, nil
+	}
+
+	repoDir, err := initTestRepo("excludepresets")
+	if err != nil {
+		return "", err
+	}
+
+	// DON'T CHANGE THE REPO CONTENTS
+	// there are unit tests that depends exactly on how it is
+
+	// commit 1
+	files := map[string]string{
+		"file1":             "a\nb\n",
+		"package-lock.json": "{}\n",
+		"vendor/lib.go":     "package lib\n",
+		"api.pb.go":         "package api\n",
+		"gen/schema.go":     "package gen\n",
+		"keep.pb.go":        "package keep\n",
+		".gitattributes":    "gen/* linguist-generated\nkeep.pb.go -linguist-generated\n",
+	}
+	for file, contents := range files {
+		err = writeAddFile(repoDir, file, contents)
+		if err != nil {
+			return "", err
+		}
+	}
+	_, err = createCommit(repoDir, "commit 1", "author1")
+	if err != nil {
+		return "", err
+	}
+
+	// commit 2 (bot)
+	err = writeAddFile(repoDir, "package-lock.json", "{\"a\": 1}\n")
+	if err != nil {
+		return "", err
+	}
+	err = writeAddFile(repoDir, "file1", "a\nb\nc\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 2", "dependabot[bot]")
+	if err != nil {
+		return "", err
+	}
+
+	excludePresetsRepoDir = &repoDir
+	return repoDir, nil
+}
+
+// ResolveTestAttributesRepo creates a repo with files marked as binary or text in .gitattributes
+// and a .mailmap that maps author1 to another name
+func ResolveTestAttributesRepo() (string, error) {
+	if attributesRepoDir != nil {
+		return *attributesRepoDir, nil
+	}
+
+	repoDir, err := initTestRepo("attributes")
+	if err != nil {
+		return "", err
+	}
+
+	// DON'T CHANGE THE REPO CONTENTS
+	// there are unit tests that depends exactly on how it is
+
+	// commit 1
+	err = writeAddFile(repoDir, ".gitattributes", "*.dat binary\nforced.txt diff\n")
+	if err != nil {
+		return "", err
+	}
+	err = writeAddFile(repoDir, ".mailmap", "Real Author <realauthor@mail.com> <author1@mail.com>\n")
+	if err != nil {
+		return "", err
+	}
+	err = writeAddFile(repoDir, "file1", "aaaa\nbbbb\n")
+	if err != nil {
+		return "", err
+	}
+	err = writeAddFile(repoDir, "file2.dat", "aaaa\nbbbb\n")
+	if err != nil {
+		return "", err
+	}
+	err = writeAddFile(repoDir, "file3.bin", "aaaa\x00bbbb\n")
+	if err != nil {
+		return "", err
+	}
+	err = writeAddFile(repoDir, "forced.txt", "aaaa\x00bbbb\n")
+	if err != nil {
+		return "", err
+	}
+	_, err = createCommit(repoDir, "commit 1", "author1")
+	if err != nil {
+		return "", err
+	}
+
+	attributesRepoDir = &repoDir
+	return repoDir, nil
+}
+
+// initTestRepo creates an empty git repo in .testcaserepos/name, removing it if it already exists
+func initTestRepo(name string) (string, error) {
+	curDir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	testCasesDir := curDir + "/.testcaserepos"
+	repoDir := testCasesDir + "/" + name
+
+	// remove repo if exists
+	_, err = ExecShellf("", "rm -rf %s", repoDir)
+	if err != nil {
+		return "", err
+	}
+
+	// create base dir for testcases
+	ExecShellf("", "mkdir -p %s", testCasesDir)
+
+	fmt.Println("Creating test repo")
+	_, err = ExecShellf(testCasesDir, "git init %s --initial-branch main", name)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = ExecShellf(repoDir, "git config user.email \"you@example.com\"")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = ExecShellf(repoDir, "git config user.name \"Your Name\"")
+	if err != nil {
+		return "", err
+	}
+	return repoDir, nil
+}
+
 func writeAddFile(repoDir string, filePath string, contents string) error {
 	fileDir := repoDir
 	i := strings.LastIndex(filePath, "/")